@@ -0,0 +1,56 @@
+package main
+
+import (
+	"ccstatus/internal/parser"
+	"context"
+	"errors"
+	"time"
+)
+
+// errSessionMissing marks segments that need the transcript when parsing missed the deadline
+var errSessionMissing = errors.New("transcript not parsed before the deadline")
+
+// withDeadline runs fn and gives up when ctx is done first
+// fn must stop on its own once ctx is done, its result is then discarded;
+// otherwise every abandoned call keeps a goroutine busy in a long-lived process
+func withDeadline[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// deadlineContext returns the context a status line run started at start is bound by,
+// 0 disables the deadline
+func deadlineContext(start time.Time, deadline time.Duration) (context.Context, context.CancelFunc) {
+	if deadline <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithDeadline(context.Background(), start.Add(deadline))
+}
+
+// parseSession parses a transcript allowed by policy, replaced in tests to simulate slow storage
+var parseSession = parser.PathPolicy.ParseSessionContext
+
+// parseSessionWithin parses the transcript unless ctx ends first
+func parseSessionWithin(ctx context.Context, policy parser.PathPolicy, transcriptPath string) (*parser.Session, error) {
+	return withDeadline(ctx, func() (*parser.Session, error) {
+		return parseSession(policy, ctx, transcriptPath)
+	})
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"ccstatus/internal/config"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/state"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	got, err := withDeadline(ctx, func() (string, error) { return "fast", nil })
+	if err != nil || got != "fast" {
+		t.Errorf("withDeadline() = %q, %v, want fast", got, err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	start := time.Now()
+	_, err = withDeadline(ctx, func() (string, error) {
+		<-release
+		return "slow", nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("withDeadline() error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("withDeadline() returned after %v, want at the deadline", elapsed)
+	}
+
+	// an expired context does not start fn at all
+	called := false
+	if _, err := withDeadline(ctx, func() (string, error) { called = true; return "", nil }); err == nil || called {
+		t.Errorf("withDeadline() on expired context = %v, called %v", err, called)
+	}
+}
+
+// blockParse makes transcript parsing hang until the test ends, like a stalled network mount
+func blockParse(t *testing.T) {
+	release := make(chan struct{})
+	parseSession = func(parser.PathPolicy, context.Context, string) (*parser.Session, error) {
+		<-release
+		return nil, errors.New("released")
+	}
+	t.Cleanup(func() {
+		close(release)
+		parseSession = parser.PathPolicy.ParseSessionContext
+	})
+}
+
+// statusLine runs the text status line with the given transcript and returns its output
+func statusLine(t *testing.T, transcript string) string {
+	t.Helper()
+	input := `{"session_id":"af99e13e","model":{"id":"claude-sonnet-4-5"},"transcript_path":"` + transcript + `"}`
+	var stdout strings.Builder
+	start := time.Now()
+	if err := runWith(strings.NewReader(input), &stdout, statusOptions{now: time.Now(), output: outputText, roots: testRoots}); err != nil {
+		t.Fatalf("runWith() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("runWith() took %v, want it bound by the deadline", elapsed)
+	}
+	return stdout.String()
+}
+
+func TestDeadlineContext(t *testing.T) {
+	// the deadline counts from the start of the run, not from when the context is made
+	ctx, cancel := deadlineContext(time.Now().Add(-time.Second), 500*time.Millisecond)
+	defer cancel()
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Errorf("deadlineContext() from a second ago error = %v, want DeadlineExceeded", ctx.Err())
+	}
+
+	ctx, cancel = deadlineContext(time.Now().Add(-time.Second), 0)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok || ctx.Err() != nil {
+		t.Errorf("deadlineContext() with 0 has a deadline, want none")
+	}
+
+	// totals for plugins are not read once the deadline passed
+	status := &statusContext{ctx: ctx, input: &StatusInput{TranscriptPath: "testdata/json/transcript.jsonl"}}
+	cancel()
+	if timeline := status.timeline(); timeline != nil {
+		t.Errorf("timeline() after the deadline = %+v, want nil", timeline)
+	}
+}
+
+func TestRunDeadline(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(state.EnvDir, filepath.Join(dir, "state"))
+	slow := filepath.Join(dir, "slow")
+	cfg := `{
+		"deadline": "200ms",
+		"segments": ["context", "ci"],
+		"plugins": {"ci": {"command": "if [ -e ` + slow + ` ]; then sleep 5; fi; echo ci ok", "timeout": "10s"}}
+	}`
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvPath, configPath)
+
+	fresh := "[ctx: 30000/200000 15.0%] claude-sonnet-4-5 ci ok"
+	if got := statusLine(t, "testdata/json/transcript.jsonl"); got != fresh {
+		t.Fatalf("status line = %q, want %q", got, fresh)
+	}
+
+	// a slow plugin falls back alone
+	if err := os.WriteFile(slow, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := statusLine(t, "testdata/json/transcript.jsonl"), "[ctx: 30000/200000 15.0%] claude-sonnet-4-5 ~ci ok"; got != want {
+		t.Errorf("status line with slow plugin = %q, want %q", got, want)
+	}
+
+	// a transcript that cannot be read in time
+	blockParse(t)
+	if got, want := statusLine(t, "testdata/json/transcript.jsonl"), "~[ctx: 30000/200000 15.0%] claude-sonnet-4-5 ~ci ok"; got != want {
+		t.Errorf("status line with slow transcript = %q, want %q", got, want)
+	}
+}
+
+func TestRunDeadlineWithoutHistory(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(state.EnvDir, filepath.Join(dir, "state"))
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"deadline":"50ms","segments":["context","cwd"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvPath, configPath)
+
+	blockParse(t)
+
+	// nothing to fall back to: the context segment is left out instead of an error
+	input := `{"session_id":"new","cwd":"/work/app","transcript_path":"testdata/json/transcript.jsonl"}`
+	var stdout strings.Builder
+	if err := runWith(strings.NewReader(input), &stdout, statusOptions{now: time.Now(), output: outputText, roots: testRoots}); err != nil {
+		t.Fatalf("runWith() error = %v", err)
+	}
+	if got := stdout.String(); got != "/work/app" {
+		t.Errorf("status line = %q, want only the cwd", got)
+	}
+}
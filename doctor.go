@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"ccstatus/internal/config"
+	"ccstatus/internal/doctor"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/projects"
+	"ccstatus/internal/settings"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// runDoctor implements `ccstatus doctor`: diagnose why the status line is empty or broken
+func runDoctor(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: ccstatus doctor [path|session-id]")
+		flags.PrintDefaults()
+	}
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		flags.Usage()
+		return fmt.Errorf("expected at most one transcript path or session id")
+	}
+
+	var results []doctor.Result
+
+	binary, err := executablePath()
+	if err != nil {
+		return err
+	}
+	var files []doctor.SettingsFile
+	for _, scope := range settings.Scopes {
+		if path, err := settings.Path(scope, projectRoot()); err == nil {
+			files = append(files, doctor.SettingsFile{Scope: scope, Path: path})
+		}
+	}
+	settingsResult, command := doctor.CheckSettings(files, binary)
+	results = append(results, settingsResult)
+	if command != "" {
+		results = append(results, doctor.CheckExecutable(command))
+	}
+
+	results = append(results, doctor.CheckConfig(config.Path()))
+
+	transcript, err := doctorTranscript(positional)
+	if err != nil {
+		results = append(results, doctor.Result{Name: "transcript", Status: doctor.StatusWarn, Message: err.Error()})
+	} else {
+		results = append(results, doctor.CheckTranscript(transcript)...)
+		results = append(results, timeStatusLine(transcript))
+	}
+
+	if err := doctor.Write(stdout, results); err != nil {
+		return err
+	}
+	if failed := doctor.Failed(results); failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+	return nil
+}
+
+// doctorTranscript picks the requested transcript or the most recently modified one
+func doctorTranscript(args []string) (string, error) {
+	if len(args) == 1 {
+		return resolveSessionArg(args[0])
+	}
+	dirs := projects.Dirs()
+	if len(dirs) == 0 {
+		return "", fmt.Errorf("no Claude Code projects directory found")
+	}
+	// archives and nested subagent transcripts are not sessions the status line runs for
+	return projects.Latest(dirs, "")
+}
+
+// timeStatusLine renders the status line for transcript the way Claude Code would
+// invoke it and measures how long that takes
+func timeStatusLine(transcript string) doctor.Result {
+	input := StatusInput{TranscriptPath: transcript}
+	if turns, err := parser.ParseTurns(transcript); err == nil {
+		for _, turn := range turns {
+			if turn.SessionID != "" {
+				input.SessionID = turn.SessionID
+			}
+			if turn.Cwd != "" {
+				input.Cwd, input.Workspace.CurrentDir = turn.Cwd, turn.Cwd
+			}
+			if turn.Model != "" && !turn.Sidechain {
+				input.Model.ID = turn.Model
+			}
+		}
+	}
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return doctor.CheckLatency(0, err)
+	}
+
+	var out bytes.Buffer
+	start := time.Now()
+	// a dry run, the check must not notify or record anything
+	err = runWith(bytes.NewReader(payload), &out, statusOptions{now: time.Now(), output: outputText, dryRun: true})
+	result := doctor.CheckLatency(time.Since(start), err)
+	if line := strings.TrimSpace(out.String()); line != "" {
+		result.Details = append(result.Details, "output: "+line)
+	}
+	return result
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"ccstatus/internal/calculator"
+	"ccstatus/internal/formatter"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/report"
+	"math"
+	"time"
+)
+
+// document assembles the --output=json document of a successful run
+func (s *statusContext) document(session *parser.Session) *formatter.Document {
+	doc := &formatter.Document{
+		GeneratedAt: s.now,
+		Session:     documentSession(s.input),
+		Model: &formatter.DocModel{
+			ID:           s.model,
+			DisplayName:  s.input.Model.DisplayName,
+			ContextLimit: s.info.MaxTokens,
+			KnownLimit:   calculator.IsKnownModel(s.model),
+		},
+		Context: &formatter.DocContext{
+			Tokens:        s.info.CurrentTokens,
+			MaxTokens:     s.info.MaxTokens,
+			Percentage:    s.info.Percentage,
+			Level:         calculator.GetUsageLevel(s.info.Percentage),
+			APIErrors:     s.info.APIErrors,
+			Interruptions: s.info.Interruptions,
+		},
+		Cost:   &formatter.DocCost{LastCall: roundCost(calculator.Cost(session.Usage, s.model))},
+		Timing: documentTiming(session, s.times),
+	}
+	if usage := session.Usage; usage != nil {
+		doc.Usage = &formatter.DocUsage{
+			InputTokens:              usage.InputTokens,
+			CacheReadInputTokens:     usage.CacheReadInputTokens,
+			CacheCreationInputTokens: usage.CacheCreationInputTokens,
+			OutputTokens:             usage.OutputTokens,
+		}
+	}
+
+	if timeline := s.timeline(); timeline != nil {
+		doc.Cost.Session = roundCost(timeline.Cost)
+		doc.Totals = &formatter.DocTotals{
+			Calls:                    timelineCalls(timeline),
+			InputTokens:              timeline.Tokens.InputTokens,
+			CacheReadInputTokens:     timeline.Tokens.CacheReadInputTokens,
+			CacheCreationInputTokens: timeline.Tokens.CacheCreationInputTokens,
+			OutputTokens:             timeline.Tokens.OutputTokens,
+			Compactions:              timeline.Compactions,
+		}
+	}
+	return doc
+}
+
+// timeline parses the whole transcript once for session totals, nil when it cannot be read
+// or the deadline passed; totals use the same deduplication and pricing as `ccstatus session`
+func (s *statusContext) timeline() *report.Timeline {
+	if !s.timelineLoaded {
+		s.timelineLoaded = true
+		if turns, err := s.policy.ParseTurnsContext(s.ctx, s.input.TranscriptPath); err == nil {
+			s.sessionTimeline, _ = report.BuildTimeline(turns, report.SidechainAll)
+		}
+	}
+	return s.sessionTimeline
+}
+
+// timelineCalls counts API calls, each response once
+func timelineCalls(timeline *report.Timeline) int {
+	calls := 0
+	for _, row := range timeline.Rows {
+		if row.Usage != nil && !row.Duplicate {
+			calls++
+		}
+	}
+	return calls
+}
+
+// errorDocument reports a failed run with whatever the payload did provide
+func errorDocument(input *StatusInput, now time.Time, code string, err error) *formatter.Document {
+	return &formatter.Document{
+		GeneratedAt: now,
+		Session:     documentSession(input),
+		Error:       &formatter.DocError{Code: code, Message: err.Error()},
+	}
+}
+
+func documentSession(input *StatusInput) formatter.DocSession {
+	cwd := input.Workspace.CurrentDir
+	if cwd == "" {
+		cwd = input.Cwd
+	}
+	return formatter.DocSession{
+		ID:             input.SessionID,
+		TranscriptPath: input.TranscriptPath,
+		Cwd:            cwd,
+		ProjectDir:     input.Workspace.ProjectDir,
+	}
+}
+
+func documentTiming(session *parser.Session, times calculator.SessionTimes) *formatter.DocTiming {
+	timing := &formatter.DocTiming{
+		WallSeconds:   times.Wall.Seconds(),
+		ActiveSeconds: times.Active.Seconds(),
+	}
+	if !session.FirstTimestamp.IsZero() {
+		timing.FirstEntry = &session.FirstTimestamp
+		timing.LastEntry = &session.LastTimestamp
+	}
+	if times.HasResponse {
+		since := times.SinceLastResponse.Seconds()
+		timing.LastResponse = &session.LastAssistantTimestamp
+		timing.SinceLastResponseSeconds = &since
+	}
+	return timing
+}
+
+// roundCost keeps costs to a millionth of a dollar so float noise does not leak into the document
+func roundCost(cost float64) float64 {
+	return math.Round(cost*1e6) / 1e6
+}
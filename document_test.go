@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"ccstatus/internal/config"
+	"ccstatus/internal/projects"
+	"ccstatus/internal/state"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// TestJSONOutputGolden pins the --output=json schema, run with -update after
+// an intentional change and bump formatter.SchemaVersion if a field changed meaning
+func TestJSONOutputGolden(t *testing.T) {
+	t.Setenv(config.EnvPath, filepath.Join(t.TempDir(), "missing-config.json"))
+	t.Setenv(state.EnvDir, t.TempDir())
+	// a payload without transcript_path must not find a transcript of this machine
+	t.Setenv(projects.EnvConfigDir, t.TempDir())
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 10, 1, 10, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{
+			name:  "ok",
+			input: `{"session_id":"af99e13e","cwd":"/work/app","workspace":{"current_dir":"/work/app","project_dir":"/work"},"model":{"id":"claude-sonnet-4-5","display_name":"Sonnet 4.5"},"transcript_path":"testdata/json/transcript.jsonl"}`,
+		},
+		{
+			name:    "parse_error",
+			input:   `{"session_id":"af99e13e","model":{"id":"claude-sonnet-4-5"},"transcript_path":"testdata/json/missing.jsonl"}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing_path",
+			input:   `{"session_id":"af99e13e"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid_input",
+			input:   `not json`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := runWith(strings.NewReader(tt.input), &out, statusOptions{now: now, output: outputJSON, roots: testRoots})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runWith() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !json.Valid(out.Bytes()) {
+				t.Fatalf("runWith() output is not valid JSON: %s", out.String())
+			}
+
+			// golden files are indented for review and free of machine-specific paths
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, out.Bytes(), "", "  "); err != nil {
+				t.Fatal(err)
+			}
+			got := strings.ReplaceAll(indented.String(), wd, "$WORKDIR") + "\n"
+
+			golden := filepath.Join("testdata", "json", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("runWith() output differs from %s\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
@@ -1,3 +1,10 @@
 module ccstatus
 
 go 1.25.3
+
+require (
+	github.com/klauspost/compress v1.18.0
+	go.etcd.io/bbolt v1.4.3
+)
+
+require golang.org/x/sys v0.29.0 // indirect
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"ccstatus/internal/config"
+	"ccstatus/internal/history"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/projects"
+	"ccstatus/internal/state"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// historyLockTimeout bounds how long the status line waits for another writer,
+// a skipped run is caught up by the next one because records are deduplicated
+const historyLockTimeout = 100 * time.Millisecond
+
+// historyPath returns the configured database location
+func historyPath(cfg config.HistoryConfig) string {
+	if cfg.Path != "" {
+		return cfg.Path
+	}
+	return history.DefaultPath()
+}
+
+// recordHistory stores the API calls of the current transcript
+// failures are reported but never affect the status line
+func (s *statusContext) recordHistory() error {
+	if !s.cfg.History.Enabled {
+		return nil
+	}
+	fi, err := os.Stat(s.input.TranscriptPath)
+	if err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	scanner := &projects.Scanner{Store: state.Default()}
+	entries := scanner.Entries([]projects.Transcript{{
+		Path:    s.input.TranscriptPath,
+		Size:    fi.Size(),
+		ModTime: fi.ModTime(),
+	}})
+
+	db, err := history.Open(historyPath(s.cfg.History), historyLockTimeout)
+	if errors.Is(err, history.ErrLocked) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	_, err = db.Add(historyRecords(entries))
+	return err
+}
+
+func historyRecords(entries []parser.Entry) []history.Record {
+	records := make([]history.Record, len(entries))
+	for i, entry := range entries {
+		records[i] = history.NewRecord(entry)
+	}
+	return records
+}
+
+// historyCommands are the subcommands of `ccstatus history`
+var historyCommands = map[string]func(db *history.DB, args []string, stdout, stderr io.Writer) error{
+	"import": runHistoryImport,
+	"prune":  runHistoryPrune,
+	"query":  runHistoryQuery,
+}
+
+// runHistory implements `ccstatus history`: query, prune and backfill the usage history
+func runHistory(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 || historyCommands[args[0]] == nil {
+		fmt.Fprintln(stderr, "usage: ccstatus history query|prune|import [flags]")
+		if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+			return flag.ErrHelp
+		}
+		return fmt.Errorf("unknown history command %q", args[0])
+	}
+
+	cfg, err := config.Load(config.Path())
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: %v\n", err)
+	}
+	// interactive commands can wait for a status line run to finish writing
+	db, err := history.Open(historyPath(cfg.History), 5*time.Second)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return historyCommands[args[0]](db, args[1:], stdout, stderr)
+}
+
+// runHistoryQuery lists stored API calls
+func runHistoryQuery(db *history.DB, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("history query", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	since := flags.String("since", "", "include calls on or after this date (YYYY-MM-DD)")
+	until := flags.String("until", "", "include calls on or before this date (YYYY-MM-DD)")
+	var filter history.Filter
+	flags.StringVar(&filter.SessionID, "session", "", "only this session id")
+	flags.StringVar(&filter.Project, "project", "", "only this project directory")
+	flags.StringVar(&filter.Model, "model", "", "only this model")
+	format := flags.String("format", history.FormatTable, "output format: table, json")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	if filter.Since, err = parseDate(*since); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if filter.Until, err = parseDate(*until); err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+	if !filter.Until.IsZero() {
+		// --until is inclusive, the filter is exclusive
+		filter.Until = filter.Until.AddDate(0, 0, 1)
+	}
+
+	records, err := db.Query(filter)
+	if err != nil {
+		return err
+	}
+	return history.Write(stdout, records, *format, time.Local)
+}
+
+// runHistoryPrune deletes old calls
+func runHistoryPrune(db *history.DB, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("history prune", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	before := flags.String("before", "", "delete calls before this date (YYYY-MM-DD)")
+	keepDays := flags.Int("keep-days", 0, "delete calls older than this many days")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	var cutoff time.Time
+	switch {
+	case *before != "" && *keepDays != 0:
+		return fmt.Errorf("--before and --keep-days are mutually exclusive")
+	case *before != "":
+		if cutoff, err = parseDate(*before); err != nil {
+			return fmt.Errorf("invalid --before: %w", err)
+		}
+	case *keepDays > 0:
+		cutoff = time.Now().AddDate(0, 0, -*keepDays)
+	default:
+		return fmt.Errorf("either --before or a positive --keep-days is required")
+	}
+
+	removed, err := db.Prune(cutoff)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "removed %d calls before %s\n", removed, cutoff.Format(time.DateTime))
+	return nil
+}
+
+// runHistoryImport backfills the history from every transcript on disk
+func runHistoryImport(db *history.DB, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("history import", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	dirs := projects.Dirs()
+	if len(dirs) == 0 {
+		return fmt.Errorf("no Claude Code projects directory found")
+	}
+	transcripts, err := projects.List(dirs, time.Time{})
+	if err != nil {
+		return fmt.Errorf("failed to list transcripts: %w", err)
+	}
+	scanner := &projects.Scanner{Store: state.Default()}
+	entries := scanner.Entries(transcripts)
+
+	added, err := db.Add(historyRecords(entries))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "imported %d new calls from %d transcripts (%d seen)\n", added, len(transcripts), len(entries))
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"ccstatus/internal/config"
+	"ccstatus/internal/history"
+	"ccstatus/internal/state"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(state.EnvDir, filepath.Join(dir, "state"))
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"history":{"enabled":true}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvPath, configPath)
+
+	// repeated status line runs store each API call once
+	input := `{"session_id":"af99e13e","model":{"id":"claude-sonnet-4-5"},"transcript_path":"testdata/json/transcript.jsonl"}`
+	for range 2 {
+		opts := statusOptions{now: time.Date(2025, 10, 1, 10, 5, 0, 0, time.UTC), output: outputText, roots: testRoots}
+		if err := runWith(strings.NewReader(input), io.Discard, opts); err != nil {
+			t.Fatalf("runWith() error = %v", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "state", "history.db")); err != nil {
+		t.Fatalf("history database not created: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	if err := runHistory([]string{"query", "--session", "af99e13e", "--format", "json"}, &stdout, io.Discard); err != nil {
+		t.Fatalf("history query error = %v", err)
+	}
+	var records []history.Record
+	if err := json.Unmarshal(stdout.Bytes(), &records); err != nil {
+		t.Fatalf("history query output is not JSON: %v\n%s", err, stdout.String())
+	}
+	if len(records) != 2 || records[0].Key != "m1:r1" || records[1].Project != "/work/app" {
+		t.Errorf("history query = %+v, want m1 and m2", records)
+	}
+
+	stdout.Reset()
+	if err := runReport([]string{"--history", "--by", "model", "--format", "csv"}, &stdout, io.Discard); err != nil {
+		t.Fatalf("report --history error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "claude-sonnet-4-5,2,") {
+		t.Errorf("report --history = %q, want 2 sonnet calls", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := runHistory([]string{"prune", "--before", "2025-10-02"}, &stdout, io.Discard); err != nil {
+		t.Fatalf("history prune error = %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), "removed 2 calls") {
+		t.Errorf("history prune = %q, want 2 removed", stdout.String())
+	}
+}
+
+func TestHistoryUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no command", nil},
+		{"unknown command", []string{"drop"}},
+		{"prune without cutoff", []string{"prune"}},
+		{"prune with both cutoffs", []string{"prune", "--before", "2025-10-01", "--keep-days", "7"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(state.EnvDir, t.TempDir())
+			t.Setenv(config.EnvPath, filepath.Join(t.TempDir(), "missing.json"))
+			if err := runHistory(tt.args, io.Discard, io.Discard); err == nil {
+				t.Errorf("runHistory(%v) error = nil, want error", tt.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"ccstatus/internal/git"
+	"ccstatus/internal/settings"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runInstall implements `ccstatus install`: point Claude Code's statusLine at this binary
+func runInstall(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("install", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	scope := flags.String("scope", settings.ScopeUser, "settings file to edit: "+strings.Join(settings.Scopes, ", "))
+	path := flags.String("settings", "", "edit this settings file instead of the scope default")
+	uninstall := flags.Bool("uninstall", false, "remove the statusLine entry instead")
+	dryRun := flags.Bool("dry-run", false, "print the diff without writing anything")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	binary, err := executablePath()
+	if err != nil {
+		return err
+	}
+	if *path == "" {
+		if *path, err = settings.Path(*scope, projectRoot()); err != nil {
+			return err
+		}
+	}
+
+	var change *settings.Change
+	if *uninstall {
+		change, err = settings.PlanUninstall(*path, binary)
+	} else {
+		change, err = settings.PlanInstall(*path, binary)
+	}
+	if errors.Is(err, settings.ErrNotInstalled) {
+		fmt.Fprintf(stdout, "%s has no statusLine entry, nothing to do\n", *path)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !change.Changed() {
+		fmt.Fprintf(stdout, "%s is up to date\n", *path)
+		return nil
+	}
+	if *dryRun {
+		_, err := io.WriteString(stdout, change.Diff())
+		return err
+	}
+
+	backup, err := change.Apply(time.Now())
+	if err != nil {
+		return err
+	}
+	if backup != "" {
+		fmt.Fprintf(stdout, "backed up %s to %s\n", *path, backup)
+	}
+	if *uninstall {
+		fmt.Fprintf(stdout, "removed statusLine from %s\n", *path)
+	} else {
+		fmt.Fprintf(stdout, "statusLine in %s now runs %s\n", *path, binary)
+	}
+	fmt.Fprintln(stdout, "restart Claude Code to apply the change")
+	return nil
+}
+
+// executablePath returns the absolute path of the running binary with symlinks resolved
+func executablePath() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to find ccstatus binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return path, nil
+}
+
+// projectRoot is the git work tree containing the current directory, or the directory itself
+func projectRoot() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+	if repo, err := git.FindRepo(cwd); err == nil {
+		return repo.Root
+	}
+	return cwd
+}
@@ -0,0 +1,75 @@
+package calculator
+
+import (
+	"ccstatus/internal/parser"
+	"slices"
+	"time"
+)
+
+// BlockDuration is the length of a usage limit window
+const BlockDuration = 5 * time.Hour
+
+// Block is a 5-hour billing window and the usage that fell into it
+type Block struct {
+	Start        time.Time
+	End          time.Time
+	LastActivity time.Time
+	Tokens       TokenTotals
+	Cost         float64
+	Calls        int
+}
+
+// IsActive reports whether the block is still open at now
+func (b *Block) IsActive(now time.Time) bool {
+	return now.Before(b.End) && now.Sub(b.LastActivity) < BlockDuration
+}
+
+// Remaining returns the time until the block resets, zero once it ended
+func (b *Block) Remaining(now time.Time) time.Duration {
+	if remaining := b.End.Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// GroupBlocks splits entries into 5-hour blocks
+// A block starts at the hour of its first entry; the next entry that falls
+// past the block end or after a 5-hour gap starts a new block
+func GroupBlocks(entries []parser.Entry) []Block {
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b parser.Entry) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	var blocks []Block
+	var current *Block
+	for i := range sorted {
+		entry := &sorted[i]
+		if current == nil ||
+			!entry.Timestamp.Before(current.End) ||
+			entry.Timestamp.Sub(current.LastActivity) >= BlockDuration {
+			start := entry.Timestamp.UTC().Truncate(time.Hour)
+			blocks = append(blocks, Block{Start: start, End: start.Add(BlockDuration)})
+			current = &blocks[len(blocks)-1]
+		}
+
+		current.LastActivity = entry.Timestamp
+		current.Tokens.Add(&entry.Usage)
+		current.Cost += Cost(&entry.Usage, entry.Model)
+		current.Calls++
+	}
+	return blocks
+}
+
+// CurrentBlock returns the block active at now, nil when usage is idle
+func CurrentBlock(entries []parser.Entry, now time.Time) *Block {
+	blocks := GroupBlocks(entries)
+	if len(blocks) == 0 {
+		return nil
+	}
+	last := &blocks[len(blocks)-1]
+	if !last.IsActive(now) {
+		return nil
+	}
+	return last
+}
@@ -0,0 +1,126 @@
+package calculator
+
+import (
+	"ccstatus/internal/parser"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGroupBlocks(t *testing.T) {
+	base := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	entry := func(offset time.Duration, input int64) parser.Entry {
+		return parser.Entry{
+			Timestamp: base.Add(offset),
+			Model:     "claude-sonnet-4-5",
+			Usage:     parser.Usage{InputTokens: input},
+		}
+	}
+
+	entries := []parser.Entry{
+		// out of order on purpose, grouping must sort
+		entry(2*time.Hour, 200),
+		entry(35*time.Minute, 100),
+		// past the end of the first block (09:00-14:00)
+		entry(5*time.Hour+10*time.Minute, 300),
+		// more than 5 hours after the previous entry
+		entry(11*time.Hour, 400),
+	}
+
+	blocks := GroupBlocks(entries)
+	if len(blocks) != 3 {
+		t.Fatalf("len(blocks) = %d, want 3", len(blocks))
+	}
+
+	tests := []struct {
+		start  time.Time
+		calls  int
+		tokens int64
+	}{
+		{start: base, calls: 2, tokens: 300},
+		{start: base.Add(5 * time.Hour), calls: 1, tokens: 300},
+		{start: base.Add(11 * time.Hour), calls: 1, tokens: 400},
+	}
+	for i, tt := range tests {
+		got := blocks[i]
+		if !got.Start.Equal(tt.start) || !got.End.Equal(tt.start.Add(BlockDuration)) {
+			t.Errorf("block %d = %v-%v, want start %v", i, got.Start, got.End, tt.start)
+		}
+		if got.Calls != tt.calls || got.Tokens.Total() != tt.tokens {
+			t.Errorf("block %d calls/tokens = %d/%d, want %d/%d", i, got.Calls, got.Tokens.Total(), tt.calls, tt.tokens)
+		}
+	}
+
+	// 300 sonnet input tokens at $3/MTok
+	if math.Abs(blocks[0].Cost-0.0009) > 1e-12 {
+		t.Errorf("block 0 cost = %v, want 0.0009", blocks[0].Cost)
+	}
+	if !blocks[0].LastActivity.Equal(base.Add(2 * time.Hour)) {
+		t.Errorf("block 0 LastActivity = %v", blocks[0].LastActivity)
+	}
+}
+
+func TestGroupBlocksGapWithinWindow(t *testing.T) {
+	// a block started at the hour, activity resumed inside the window after a long pause
+	base := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	entries := []parser.Entry{
+		{Timestamp: base.Add(10 * time.Minute), Usage: parser.Usage{InputTokens: 1}},
+		{Timestamp: base.Add(4*time.Hour + 50*time.Minute), Usage: parser.Usage{InputTokens: 1}},
+	}
+	if blocks := GroupBlocks(entries); len(blocks) != 1 {
+		t.Errorf("len(blocks) = %d, want 1", len(blocks))
+	}
+}
+
+func TestCurrentBlock(t *testing.T) {
+	base := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	entries := []parser.Entry{
+		{Timestamp: base.Add(20 * time.Minute), Model: "claude-sonnet-4-5", Usage: parser.Usage{InputTokens: 1000}},
+		{Timestamp: base.Add(90 * time.Minute), Model: "claude-sonnet-4-5", Usage: parser.Usage{OutputTokens: 500}},
+	}
+
+	tests := []struct {
+		name          string
+		entries       []parser.Entry
+		now           time.Time
+		wantNil       bool
+		wantRemaining time.Duration
+	}{
+		{
+			name:    "no entries",
+			entries: nil,
+			now:     base,
+			wantNil: true,
+		},
+		{
+			name:          "inside the block",
+			entries:       entries,
+			now:           base.Add(2*time.Hour + 47*time.Minute),
+			wantRemaining: 2*time.Hour + 13*time.Minute,
+		},
+		{
+			name:    "after the block ended",
+			entries: entries,
+			now:     base.Add(5 * time.Hour),
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CurrentBlock(tt.entries, tt.now)
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("CurrentBlock() = %+v, wantNil %v", got, tt.wantNil)
+			}
+			if got == nil {
+				return
+			}
+			if remaining := got.Remaining(tt.now); remaining != tt.wantRemaining {
+				t.Errorf("Remaining() = %v, want %v", remaining, tt.wantRemaining)
+			}
+			if got.Tokens.Total() != 1500 {
+				t.Errorf("Tokens.Total() = %d, want 1500", got.Tokens.Total())
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package calculator
+
+import (
+	"ccstatus/internal/parser"
+	"path/filepath"
+	"time"
+)
+
+// budget periods
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// minProjectionBase keeps the first minutes of a period from being extrapolated
+// into huge projections
+const minProjectionBase = time.Hour
+
+// Budget is a spending cap for one period
+type Budget struct {
+	Name string
+	// Project is a path glob matched against the working directory of each API
+	// call and its parents, empty matches every project
+	Project string
+	Period  string
+	Limit   float64
+}
+
+// BudgetStatus is the spend of a budget in the period containing now
+type BudgetStatus struct {
+	Budget Budget
+	Start  time.Time
+	End    time.Time
+	Spent  float64
+	// Projected is the spend at the end of the period if the average rate
+	// since the period started continues
+	Projected float64
+}
+
+// Exceeded reports whether the cap is already reached
+func (s BudgetStatus) Exceeded() bool {
+	return s.Spent >= s.Budget.Limit
+}
+
+// AtRisk reports whether the projected spend exceeds the cap
+func (s BudgetStatus) AtRisk() bool {
+	return s.Projected > s.Budget.Limit
+}
+
+// PeriodBounds returns the start and end of the period containing now in loc
+// weeks start on Monday, like report groups
+func PeriodBounds(period string, now time.Time, loc *time.Location) (time.Time, time.Time) {
+	now = now.In(loc)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	switch period {
+	case PeriodWeek:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7)
+	case PeriodMonth:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 1, 0)
+	default:
+		return day, day.AddDate(0, 0, 1)
+	}
+}
+
+// CalculateBudget sums the cost of matching entries in the current period
+func CalculateBudget(entries []parser.Entry, budget Budget, now time.Time, loc *time.Location) BudgetStatus {
+	start, end := PeriodBounds(budget.Period, now, loc)
+	status := BudgetStatus{Budget: budget, Start: start, End: end}
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Timestamp.Before(start) || entry.Timestamp.After(now) {
+			continue
+		}
+		if !MatchProject(budget.Project, entry.Cwd) {
+			continue
+		}
+		status.Spent += Cost(&entry.Usage, entry.Model)
+	}
+
+	elapsed := max(now.Sub(start), minProjectionBase)
+	status.Projected = status.Spent + status.Spent/elapsed.Hours()*end.Sub(now).Hours()
+	return status
+}
+
+// MatchProject reports whether dir or one of its parents matches the glob pattern
+func MatchProject(pattern, dir string) bool {
+	if pattern == "" {
+		return true
+	}
+	if dir == "" {
+		return false
+	}
+	for dir = filepath.Clean(dir); ; dir = filepath.Dir(dir) {
+		if ok, _ := filepath.Match(pattern, dir); ok {
+			return true
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return false
+		}
+	}
+}
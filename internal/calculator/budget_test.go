@@ -0,0 +1,149 @@
+package calculator
+
+import (
+	"ccstatus/internal/parser"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPeriodBounds(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	// Wednesday 23:30 UTC is already Thursday in UTC+2
+	now := time.Date(2025, 10, 1, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		period    string
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{PeriodDay, time.Date(2025, 10, 2, 0, 0, 0, 0, loc), time.Date(2025, 10, 3, 0, 0, 0, 0, loc)},
+		{PeriodWeek, time.Date(2025, 9, 29, 0, 0, 0, 0, loc), time.Date(2025, 10, 6, 0, 0, 0, 0, loc)},
+		{PeriodMonth, time.Date(2025, 10, 1, 0, 0, 0, 0, loc), time.Date(2025, 11, 1, 0, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			start, end := PeriodBounds(tt.period, now, loc)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("PeriodBounds() = %v, %v, want %v, %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestCalculateBudget(t *testing.T) {
+	// one million output tokens of sonnet cost $15
+	entry := func(ts time.Time, cwd string) parser.Entry {
+		return parser.Entry{
+			Timestamp: ts,
+			Cwd:       cwd,
+			Model:     "claude-sonnet-4-5",
+			Usage:     parser.Usage{OutputTokens: 1_000_000},
+		}
+	}
+	day := func(d, h int) time.Time { return time.Date(2025, 10, d, h, 0, 0, 0, time.UTC) }
+	entries := []parser.Entry{
+		entry(day(1, 9), "/work/acme-api"),
+		entry(day(1, 10), "/work/acme-web/src"),
+		entry(day(1, 11), "/work/personal"),
+		entry(day(2, 9), "/work/acme-api"),
+		// after now, e.g. clock skew between machines
+		entry(day(2, 20), "/work/acme-api"),
+	}
+	now := day(2, 12)
+
+	tests := []struct {
+		name          string
+		budget        Budget
+		wantSpent     float64
+		wantProjected float64
+		wantExceeded  bool
+		wantAtRisk    bool
+	}{
+		{
+			name:      "today projects the average rate",
+			budget:    Budget{Period: PeriodDay, Limit: 50},
+			wantSpent: 15,
+			// $15 in 12 hours, 12 hours left
+			wantProjected: 30,
+		},
+		{
+			name:          "today at risk",
+			budget:        Budget{Period: PeriodDay, Limit: 20},
+			wantSpent:     15,
+			wantProjected: 30,
+			wantAtRisk:    true,
+		},
+		{
+			name:          "month across projects",
+			budget:        Budget{Period: PeriodMonth, Limit: 2000},
+			wantSpent:     60,
+			wantProjected: 60 + 60.0/36*(29*24+12),
+		},
+		{
+			name:          "project glob matches subdirectories",
+			budget:        Budget{Project: "/work/acme-*", Period: PeriodMonth, Limit: 45},
+			wantSpent:     45,
+			wantProjected: 45 + 45.0/36*(29*24+12),
+			wantExceeded:  true,
+			wantAtRisk:    true,
+		},
+		{
+			name:          "project without usage",
+			budget:        Budget{Project: "/other", Period: PeriodWeek, Limit: 10},
+			wantSpent:     0,
+			wantProjected: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculateBudget(entries, tt.budget, now, time.UTC)
+			if math.Abs(got.Spent-tt.wantSpent) > 1e-9 {
+				t.Errorf("Spent = %v, want %v", got.Spent, tt.wantSpent)
+			}
+			if math.Abs(got.Projected-tt.wantProjected) > 1e-9 {
+				t.Errorf("Projected = %v, want %v", got.Projected, tt.wantProjected)
+			}
+			if got.Exceeded() != tt.wantExceeded || got.AtRisk() != tt.wantAtRisk {
+				t.Errorf("Exceeded(), AtRisk() = %v, %v, want %v, %v", got.Exceeded(), got.AtRisk(), tt.wantExceeded, tt.wantAtRisk)
+			}
+		})
+	}
+}
+
+func TestCalculateBudgetEarlyInPeriod(t *testing.T) {
+	now := time.Date(2025, 10, 1, 0, 6, 0, 0, time.UTC)
+	entries := []parser.Entry{{
+		Timestamp: now.Add(-time.Minute),
+		Model:     "claude-sonnet-4-5",
+		Usage:     parser.Usage{OutputTokens: 100_000},
+	}}
+	// $1.50 after six minutes is spread over at least an hour, not extrapolated 240x
+	got := CalculateBudget(entries, Budget{Period: PeriodDay, Limit: 50}, now, time.UTC)
+	want := 1.5 + 1.5*(23.9)
+	if math.Abs(got.Projected-want) > 1e-9 {
+		t.Errorf("Projected = %v, want %v", got.Projected, want)
+	}
+}
+
+func TestMatchProject(t *testing.T) {
+	tests := []struct {
+		pattern string
+		dir     string
+		want    bool
+	}{
+		{"", "/work/app", true},
+		{"/work/app", "", false},
+		{"/work/app", "/work/app", true},
+		{"/work/app", "/work/app/internal/parser", true},
+		{"/work/app", "/work/application", false},
+		{"/work/acme-*", "/work/acme-api/cmd", true},
+		{"/work/*/api", "/work/acme/api/", true},
+		{"/work/acme-*", "/home/acme-api", false},
+	}
+	for _, tt := range tests {
+		if got := MatchProject(tt.pattern, tt.dir); got != tt.want {
+			t.Errorf("MatchProject(%q, %q) = %v, want %v", tt.pattern, tt.dir, got, tt.want)
+		}
+	}
+}
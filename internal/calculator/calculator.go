@@ -31,6 +31,10 @@ type ContextInfo struct {
 	CurrentTokens int64
 	MaxTokens     int64
 	Percentage    float64
+	// APIErrors and Interruptions count failed and cancelled requests of the session,
+	// set by CalculateSession only
+	APIErrors     int
+	Interruptions int
 }
 
 // Calculate computes context usage from parsed usage data
@@ -64,23 +68,69 @@ func Calculate(usage *parser.Usage, model string) ContextInfo {
 	}
 }
 
+// CalculateSession computes context usage like Calculate and adds the session
+// API error and interruption counts
+func CalculateSession(session *parser.Session, model string) ContextInfo {
+	if session == nil {
+		return Calculate(nil, model)
+	}
+	info := Calculate(session.Usage, model)
+	info.APIErrors = session.APIErrors
+	info.Interruptions = session.Interruptions
+	return info
+}
+
 // getModelLimit returns context window limit for given model
 func getModelLimit(model string) int64 {
+	if limit, _, ok := lookupModelLimit(model); ok {
+		return limit
+	}
+
+	// fallback to default
+	return DefaultContextTokens
+}
+
+// IsKnownModel reports whether model has a listed context limit
+// unknown models are calculated against DefaultContextTokens
+func IsKnownModel(model string) bool {
+	_, _, ok := lookupModelLimit(model)
+	return ok
+}
+
+// ModelLimitSource describes where the context limit of model comes from:
+// "exact", "prefix:<listed name>" or "default"
+func ModelLimitSource(model string) string {
+	_, key, ok := lookupModelLimit(model)
+	switch {
+	case !ok:
+		return "default"
+	case key == model:
+		return "exact"
+	default:
+		return "prefix:" + key
+	}
+}
+
+// lookupModelLimit returns the listed limit of model and the name it was listed under
+func lookupModelLimit(model string) (int64, string, bool) {
 	// try exact match first
 	if limit, ok := modelLimits[model]; ok {
-		return limit
+		return limit, model, true
 	}
 
-	// try prefix match (e.g., "claude-3-opus-20240229" matches "claude-3-opus")
+	// try prefix match (e.g., "claude-3-opus-20240229" matches "claude-3-opus"),
+	// the longest listed prefix wins so that "claude-2.1-x" is not taken for "claude-2"
 	modelLower := strings.ToLower(model)
-	for prefix, limit := range modelLimits {
-		if strings.HasPrefix(modelLower, prefix) {
-			return limit
+	var match string
+	for prefix := range modelLimits {
+		if strings.HasPrefix(modelLower, prefix) && len(prefix) > len(match) {
+			match = prefix
 		}
 	}
-
-	// fallback to default
-	return DefaultContextTokens
+	if match == "" {
+		return 0, "", false
+	}
+	return modelLimits[match], match, true
 }
 
 // GetUsageLevel returns usage level based on percentage
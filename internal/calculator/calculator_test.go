@@ -222,4 +222,61 @@ func TestGetModelLimit(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestIsKnownModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  bool
+	}{
+		{model: "claude-sonnet-4-5-20250929", want: true},
+		{model: "CLAUDE-3-HAIKU", want: true},
+		{model: "claude-future-model", want: false},
+		{model: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := IsKnownModel(tt.model); got != tt.want {
+				t.Errorf("IsKnownModel(%q) = %v, want %v", tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelLimitSource(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{model: "claude-sonnet-4-5", want: "exact"},
+		{model: "claude-sonnet-4-5-20250929", want: "prefix:claude-sonnet-4-5"},
+		{model: "claude-2.1-preview", want: "prefix:claude-2.1"},
+		{model: "claude-future-model", want: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := ModelLimitSource(tt.model); got != tt.want {
+				t.Errorf("ModelLimitSource(%q) = %q, want %q", tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateSession(t *testing.T) {
+	session := &parser.Session{
+		Usage:         &parser.Usage{InputTokens: 1000, CacheReadInputTokens: 19000},
+		APIErrors:     2,
+		Interruptions: 1,
+	}
+	got := CalculateSession(session, "claude-sonnet-4-5")
+	want := ContextInfo{CurrentTokens: 20000, MaxTokens: 200000, Percentage: 10, APIErrors: 2, Interruptions: 1}
+	if got != want {
+		t.Errorf("CalculateSession() = %+v, want %+v", got, want)
+	}
+
+	if got := CalculateSession(nil, "claude-sonnet-4-5"); got != (ContextInfo{MaxTokens: 200000}) {
+		t.Errorf("CalculateSession(nil) = %+v, want zero usage", got)
+	}
+}
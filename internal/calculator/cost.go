@@ -0,0 +1,109 @@
+package calculator
+
+import (
+	"ccstatus/internal/parser"
+	"strings"
+)
+
+// Pricing holds model prices in USD per million tokens
+type Pricing struct {
+	Input  float64
+	Output float64
+}
+
+// cache pricing relative to the input price
+const (
+	cacheWrite5mMultiplier = 1.25
+	cacheWrite1hMultiplier = 2.0
+	cacheReadMultiplier    = 0.1
+)
+
+// model prices in USD per million tokens, matched by longest prefix
+var modelPricing = map[string]Pricing{
+	"claude-opus-4-5":   {Input: 5, Output: 25},
+	"claude-opus-4-1":   {Input: 15, Output: 75},
+	"claude-opus-4":     {Input: 15, Output: 75},
+	"claude-sonnet-4-5": {Input: 3, Output: 15},
+	"claude-sonnet-4":   {Input: 3, Output: 15},
+	"claude-haiku-4-5":  {Input: 1, Output: 5},
+	"claude-3-7-sonnet": {Input: 3, Output: 15},
+	"claude-3-5-sonnet": {Input: 3, Output: 15},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4},
+	"claude-3-opus":     {Input: 15, Output: 75},
+	"claude-3-sonnet":   {Input: 3, Output: 15},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+}
+
+// LookupPricing returns prices for model, false when the model is unknown
+func LookupPricing(model string) (Pricing, bool) {
+	modelLower := strings.ToLower(model)
+	if pricing, ok := modelPricing[modelLower]; ok {
+		return pricing, true
+	}
+
+	// longest prefix wins so claude-opus-4-5 is not priced as claude-opus-4
+	var best string
+	for prefix := range modelPricing {
+		if strings.HasPrefix(modelLower, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return Pricing{}, false
+	}
+	return modelPricing[best], true
+}
+
+// Cost estimates the price of a single API call in USD, unknown models cost 0
+func Cost(usage *parser.Usage, model string) float64 {
+	pricing, ok := LookupPricing(model)
+	if !ok || usage == nil {
+		return 0
+	}
+
+	// split cache writes by TTL when the breakdown is available
+	write1h := int64(usage.CacheCreation["ephemeral_1h_input_tokens"])
+	write5m := usage.CacheCreationInputTokens - write1h
+	if write5m < 0 {
+		write5m = 0
+	}
+
+	perToken := func(price float64) float64 { return price / 1_000_000 }
+	return float64(usage.InputTokens)*perToken(pricing.Input) +
+		float64(usage.OutputTokens)*perToken(pricing.Output) +
+		float64(write5m)*perToken(pricing.Input*cacheWrite5mMultiplier) +
+		float64(write1h)*perToken(pricing.Input*cacheWrite1hMultiplier) +
+		float64(usage.CacheReadInputTokens)*perToken(pricing.Input*cacheReadMultiplier)
+}
+
+// TokenTotals accumulates token counts by type
+type TokenTotals struct {
+	InputTokens              int64 `json:"input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+}
+
+// Add accumulates usage into the totals
+func (t *TokenTotals) Add(usage *parser.Usage) {
+	if usage == nil {
+		return
+	}
+	t.InputTokens += usage.InputTokens
+	t.OutputTokens += usage.OutputTokens
+	t.CacheCreationInputTokens += usage.CacheCreationInputTokens
+	t.CacheReadInputTokens += usage.CacheReadInputTokens
+}
+
+// Merge adds other into the totals
+func (t *TokenTotals) Merge(other TokenTotals) {
+	t.InputTokens += other.InputTokens
+	t.OutputTokens += other.OutputTokens
+	t.CacheCreationInputTokens += other.CacheCreationInputTokens
+	t.CacheReadInputTokens += other.CacheReadInputTokens
+}
+
+// Total returns the sum of all token types
+func (t TokenTotals) Total() int64 {
+	return t.InputTokens + t.OutputTokens + t.CacheCreationInputTokens + t.CacheReadInputTokens
+}
@@ -0,0 +1,97 @@
+package calculator
+
+import (
+	"ccstatus/internal/parser"
+	"math"
+	"testing"
+)
+
+func TestLookupPricing(t *testing.T) {
+	tests := []struct {
+		model  string
+		want   Pricing
+		wantOK bool
+	}{
+		{model: "claude-sonnet-4-5-20250929", want: Pricing{Input: 3, Output: 15}, wantOK: true},
+		{model: "claude-opus-4-5-20251101", want: Pricing{Input: 5, Output: 25}, wantOK: true},
+		{model: "claude-opus-4-20250514", want: Pricing{Input: 15, Output: 75}, wantOK: true},
+		{model: "CLAUDE-3-HAIKU-20240307", want: Pricing{Input: 0.25, Output: 1.25}, wantOK: true},
+		{model: "<synthetic>", wantOK: false},
+		{model: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := LookupPricing(tt.model)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("LookupPricing(%q) = %+v, %v, want %+v, %v", tt.model, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCost(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage *parser.Usage
+		model string
+		want  float64
+	}{
+		{
+			name:  "nil usage",
+			usage: nil,
+			model: "claude-sonnet-4-5",
+			want:  0,
+		},
+		{
+			name:  "unknown model",
+			usage: &parser.Usage{InputTokens: 1_000_000},
+			model: "gpt-4",
+			want:  0,
+		},
+		{
+			name: "sonnet all token types",
+			usage: &parser.Usage{
+				InputTokens:              1_000_000,
+				OutputTokens:             1_000_000,
+				CacheCreationInputTokens: 1_000_000,
+				CacheReadInputTokens:     1_000_000,
+			},
+			model: "claude-sonnet-4-5-20250929",
+			// 3 + 15 + 3.75 + 0.30
+			want: 22.05,
+		},
+		{
+			name: "one hour cache writes cost double input",
+			usage: &parser.Usage{
+				CacheCreationInputTokens: 1_000_000,
+				CacheCreation:            map[string]int{"ephemeral_1h_input_tokens": 400_000, "ephemeral_5m_input_tokens": 600_000},
+			},
+			model: "claude-opus-4-1",
+			// 0.6 * 18.75 + 0.4 * 30
+			want: 23.25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Cost(tt.usage, tt.model)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Cost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenTotals(t *testing.T) {
+	var totals TokenTotals
+	totals.Add(&parser.Usage{InputTokens: 1, OutputTokens: 2, CacheCreationInputTokens: 3, CacheReadInputTokens: 4})
+	totals.Add(nil)
+	totals.Merge(TokenTotals{InputTokens: 10})
+
+	want := TokenTotals{InputTokens: 11, OutputTokens: 2, CacheCreationInputTokens: 3, CacheReadInputTokens: 4}
+	if totals != want {
+		t.Errorf("totals = %+v, want %+v", totals, want)
+	}
+	if totals.Total() != 20 {
+		t.Errorf("Total() = %d, want 20", totals.Total())
+	}
+}
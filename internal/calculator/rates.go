@@ -0,0 +1,55 @@
+package calculator
+
+import (
+	"ccstatus/internal/parser"
+	"time"
+)
+
+// DefaultBurnWindow is the period burn rates are averaged over
+const DefaultBurnWindow = time.Hour
+
+// BurnRate is the recent speed of token usage and spending
+type BurnRate struct {
+	TokensPerMinute float64
+	CostPerHour     float64
+}
+
+// CacheHitRatio returns the share of prompt tokens served from cache, 0 to 1
+func CacheHitRatio(tokens TokenTotals) float64 {
+	prompt := tokens.InputTokens + tokens.CacheCreationInputTokens + tokens.CacheReadInputTokens
+	if prompt == 0 {
+		return 0
+	}
+	return float64(tokens.CacheReadInputTokens) / float64(prompt)
+}
+
+// CalculateBurnRate averages usage of entries inside the window ending at now
+// The rate is spread over the time since the first entry in the window, but at
+// least one minute so a single fresh call does not look like a runaway rate
+func CalculateBurnRate(entries []parser.Entry, now time.Time, window time.Duration) BurnRate {
+	windowStart := now.Add(-window)
+
+	var first time.Time
+	var tokens TokenTotals
+	var cost float64
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Timestamp.Before(windowStart) || entry.Timestamp.After(now) {
+			continue
+		}
+		if first.IsZero() || entry.Timestamp.Before(first) {
+			first = entry.Timestamp
+		}
+		tokens.Add(&entry.Usage)
+		cost += Cost(&entry.Usage, entry.Model)
+	}
+	if first.IsZero() {
+		return BurnRate{}
+	}
+
+	elapsed := max(now.Sub(first), time.Minute)
+	return BurnRate{
+		TokensPerMinute: float64(tokens.Total()) / elapsed.Minutes(),
+		CostPerHour:     cost / elapsed.Hours(),
+	}
+}
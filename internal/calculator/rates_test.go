@@ -0,0 +1,79 @@
+package calculator
+
+import (
+	"ccstatus/internal/parser"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCacheHitRatio(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens TokenTotals
+		want   float64
+	}{
+		{name: "no prompt tokens", tokens: TokenTotals{OutputTokens: 100}, want: 0},
+		{name: "all cached", tokens: TokenTotals{CacheReadInputTokens: 100}, want: 1},
+		{
+			name:   "mixed",
+			tokens: TokenTotals{InputTokens: 10, CacheCreationInputTokens: 90, CacheReadInputTokens: 900, OutputTokens: 5000},
+			want:   0.9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CacheHitRatio(tt.tokens); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("CacheHitRatio() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateBurnRate(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	entry := func(ago time.Duration, output int64) parser.Entry {
+		return parser.Entry{
+			Timestamp: now.Add(-ago),
+			Model:     "claude-sonnet-4-5",
+			Usage:     parser.Usage{OutputTokens: output},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		entries []parser.Entry
+		want    BurnRate
+	}{
+		{
+			name:    "no entries",
+			entries: nil,
+			want:    BurnRate{},
+		},
+		{
+			name: "entries outside the window are ignored",
+			entries: []parser.Entry{
+				entry(3*time.Hour, 1_000_000),
+				entry(30*time.Minute, 60_000),
+				entry(10*time.Minute, 60_000),
+			},
+			// 120k tokens over 30 minutes, $15/MTok output
+			want: BurnRate{TokensPerMinute: 4000, CostPerHour: 3.6},
+		},
+		{
+			name:    "single fresh call spread over a minute",
+			entries: []parser.Entry{entry(time.Second, 1000)},
+			want:    BurnRate{TokensPerMinute: 1000, CostPerHour: 0.9},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculateBurnRate(tt.entries, now, DefaultBurnWindow)
+			if math.Abs(got.TokensPerMinute-tt.want.TokensPerMinute) > 1e-9 || math.Abs(got.CostPerHour-tt.want.CostPerHour) > 1e-9 {
+				t.Errorf("CalculateBurnRate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package calculator
+
+import (
+	"ccstatus/internal/parser"
+	"time"
+)
+
+// DefaultIdleThreshold is the gap between entries after which the session counts as idle
+const DefaultIdleThreshold = 10 * time.Minute
+
+// SessionTimes contains timing information derived from transcript timestamps
+type SessionTimes struct {
+	// Wall is the time between the first and the last entry
+	Wall time.Duration
+	// Active is Wall minus every gap longer than the idle threshold
+	Active time.Duration
+	// SinceLastResponse is the time since the last assistant message, zero when there is none
+	SinceLastResponse time.Duration
+	// HasResponse reports whether an assistant message was seen
+	HasResponse bool
+}
+
+// CalculateTimes computes wall, active and idle times for a session at the given moment
+func CalculateTimes(session *parser.Session, now time.Time, idleThreshold time.Duration) SessionTimes {
+	if session == nil || session.FirstTimestamp.IsZero() {
+		return SessionTimes{}
+	}
+
+	times := SessionTimes{
+		Wall: session.LastTimestamp.Sub(session.FirstTimestamp),
+	}
+
+	// entries are written in order, but guard against clock skew between lines
+	var prev time.Time
+	for _, ts := range session.Timestamps {
+		if !prev.IsZero() {
+			gap := ts.Sub(prev)
+			if gap > 0 && (idleThreshold <= 0 || gap <= idleThreshold) {
+				times.Active += gap
+			}
+		}
+		if ts.After(prev) {
+			prev = ts
+		}
+	}
+
+	if !session.LastAssistantTimestamp.IsZero() {
+		times.HasResponse = true
+		if since := now.Sub(session.LastAssistantTimestamp); since > 0 {
+			times.SinceLastResponse = since
+		}
+	}
+
+	return times
+}
@@ -0,0 +1,100 @@
+package calculator
+
+import (
+	"ccstatus/internal/parser"
+	"testing"
+	"time"
+)
+
+func TestCalculateTimes(t *testing.T) {
+	base := time.Date(2025, 10, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return base.Add(time.Duration(minutes) * time.Minute)
+	}
+	session := func(assistant time.Time, stamps ...time.Time) *parser.Session {
+		s := &parser.Session{
+			FirstTimestamp:         stamps[0],
+			LastTimestamp:          stamps[len(stamps)-1],
+			LastAssistantTimestamp: assistant,
+			Timestamps:             stamps,
+		}
+		return s
+	}
+
+	tests := []struct {
+		name    string
+		session *parser.Session
+		now     time.Time
+		idle    time.Duration
+		want    SessionTimes
+	}{
+		{
+			name:    "nil session",
+			session: nil,
+			now:     base,
+			idle:    DefaultIdleThreshold,
+			want:    SessionTimes{},
+		},
+		{
+			name:    "session without timestamps",
+			session: &parser.Session{},
+			now:     base,
+			idle:    DefaultIdleThreshold,
+			want:    SessionTimes{},
+		},
+		{
+			name:    "continuous activity",
+			session: session(at(30), at(0), at(5), at(10), at(20), at(30)),
+			now:     at(32),
+			idle:    DefaultIdleThreshold,
+			want: SessionTimes{
+				Wall:              30 * time.Minute,
+				Active:            30 * time.Minute,
+				SinceLastResponse: 2 * time.Minute,
+				HasResponse:       true,
+			},
+		},
+		{
+			name:    "idle gap excluded from active time",
+			session: session(at(72), at(0), at(5), at(65), at(72)),
+			now:     at(72),
+			idle:    DefaultIdleThreshold,
+			want: SessionTimes{
+				Wall:        72 * time.Minute,
+				Active:      12 * time.Minute,
+				HasResponse: true,
+			},
+		},
+		{
+			name:    "zero threshold counts every gap",
+			session: session(time.Time{}, at(0), at(5), at(65)),
+			now:     at(70),
+			idle:    0,
+			want: SessionTimes{
+				Wall:   65 * time.Minute,
+				Active: 65 * time.Minute,
+			},
+		},
+		{
+			name:    "out of order entry does not count negative time",
+			session: session(at(10), at(0), at(10), at(8), at(12)),
+			now:     at(11),
+			idle:    DefaultIdleThreshold,
+			want: SessionTimes{
+				Wall:              12 * time.Minute,
+				Active:            12 * time.Minute,
+				SinceLastResponse: time.Minute,
+				HasResponse:       true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculateTimes(tt.session, tt.now, tt.idle)
+			if got != tt.want {
+				t.Errorf("CalculateTimes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package config
+
+import (
+	"ccstatus/internal/calculator"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EnvPath overrides the config file location
+const EnvPath = "CCSTATUS_CONFIG"
+
+// segment names accepted in Config.Segments
+const (
+	SegmentContext = "context"
+	SegmentCwd     = "cwd"
+	SegmentGit     = "git"
+	SegmentSession = "session"
+	SegmentBlock   = "block"
+	SegmentBudget  = "budget"
+	SegmentErrors  = "errors"
+)
+
+var knownSegments = map[string]bool{
+	SegmentContext: true,
+	SegmentCwd:     true,
+	SegmentGit:     true,
+	SegmentSession: true,
+	SegmentBlock:   true,
+	SegmentBudget:  true,
+	SegmentErrors:  true,
+}
+
+// budgetPeriods lists the accepted BudgetConfig.Period values
+var budgetPeriods = map[string]bool{"day": true, "week": true, "month": true}
+
+// Config holds user settings loaded from the config file
+type Config struct {
+	// Segments lists status line segments in display order
+	Segments []string       `json:"segments"`
+	Cwd      CwdConfig      `json:"cwd"`
+	Session  SessionConfig  `json:"session"`
+	Metrics  MetricsConfig  `json:"metrics"`
+	History  HistoryConfig  `json:"history"`
+	Notify   NotifyConfig   `json:"notify"`
+	Budgets  []BudgetConfig `json:"budgets"`
+	// Deadline bounds a status line run, segments that miss it show their
+	// previous value marked as stale; 0 disables the deadline
+	Deadline Duration `json:"deadline"`
+	// Plugins declares custom segments by name, enabled by listing the name in Segments
+	Plugins map[string]PluginConfig `json:"plugins"`
+	// Debug writes debug records to the log file in the state dir, like CCSTATUS_DEBUG
+	Debug bool `json:"debug"`
+	// TranscriptRoots lists directories besides the Claude Code projects dirs the
+	// status line accepts transcripts from, absolute or starting with ~/
+	TranscriptRoots []string `json:"transcript_roots"`
+}
+
+// CwdConfig controls how the working directory segment shortens paths
+type CwdConfig struct {
+	// Home replaces the home directory prefix with ~
+	Home bool `json:"home"`
+	// Fish abbreviates intermediate directories to their first letter, e.g. ~/w/p/ccstatus
+	Fish bool `json:"fish"`
+	// ProjectRelative shows the path relative to the project root, prefixed with the root name
+	ProjectRelative bool `json:"project_relative"`
+	// MaxWidth truncates longer paths with a middle ellipsis, 0 disables the limit
+	MaxWidth int `json:"max_width"`
+}
+
+// SessionConfig controls the session clock segment
+type SessionConfig struct {
+	// IdleThreshold is the gap between entries that is not counted as active time
+	IdleThreshold Duration `json:"idle_threshold"`
+}
+
+// MetricsConfig controls the Prometheus exporter
+type MetricsConfig struct {
+	// Enabled records a sample of every status line run for `ccstatus serve-metrics`
+	Enabled bool `json:"enabled"`
+	// Textfile is rewritten on every run for the node_exporter textfile collector,
+	// setting it also enables recording
+	Textfile string `json:"textfile"`
+	// MaxSessions caps the exported sessions, the most recently active are kept
+	MaxSessions int `json:"max_sessions"`
+	// Expiry drops sessions without a status line run for this long
+	Expiry Duration `json:"expiry"`
+	// Listen is the default address of `ccstatus serve-metrics`
+	Listen string `json:"listen"`
+}
+
+// Recording reports whether status line runs should record metric samples
+func (m MetricsConfig) Recording() bool {
+	return m.Enabled || m.Textfile != ""
+}
+
+// HistoryConfig controls the usage history database
+type HistoryConfig struct {
+	// Enabled stores every API call seen by the status line for `ccstatus history`
+	Enabled bool `json:"enabled"`
+	// Path overrides the database location, defaults to history.db in the state dir
+	Path string `json:"path"`
+}
+
+// NotifyConfig controls threshold crossing notifications
+type NotifyConfig struct {
+	// Command is run with sh -c and the event JSON on stdin, empty disables notifications
+	Command string `json:"command"`
+	// Context lists context usage thresholds in percent
+	Context []float64 `json:"context"`
+	// Cost lists session cost thresholds in USD
+	Cost []float64 `json:"cost"`
+}
+
+// BudgetConfig is a spending cap shown by the budget segment
+type BudgetConfig struct {
+	// Name labels the budget in the status line, optional
+	Name string `json:"name"`
+	// Project is a path glob for the working directories the budget covers,
+	// ~ is expanded; empty covers every project
+	Project string `json:"project"`
+	// Period is day, week or month
+	Period string `json:"period"`
+	// Limit is the cap in USD
+	Limit float64 `json:"limit"`
+}
+
+// PluginConfig is a segment rendered by an external command
+type PluginConfig struct {
+	// Command is run with sh -c in the session working directory, the status
+	// JSON document is passed on stdin and the first line of stdout is shown
+	Command string `json:"command"`
+	// Timeout kills a slow command, 0 uses the default of 200ms
+	Timeout Duration `json:"timeout"`
+	// TTL reuses the previous result for this long, 0 runs the command on every update
+	TTL Duration `json:"ttl"`
+}
+
+// Default returns the configuration used when no config file exists
+func Default() *Config {
+	return &Config{
+		Segments: []string{SegmentContext, SegmentGit},
+		Cwd: CwdConfig{
+			Home: true,
+		},
+		Session: SessionConfig{
+			IdleThreshold: Duration(calculator.DefaultIdleThreshold),
+		},
+		Metrics: MetricsConfig{
+			MaxSessions: 20,
+			Expiry:      Duration(time.Hour),
+			Listen:      "127.0.0.1:9464",
+		},
+	}
+}
+
+// Path returns the config file location
+// Honors CCSTATUS_CONFIG, otherwise <user config dir>/ccstatus/config.json
+func Path() string {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "ccstatus", "config.json")
+}
+
+// Load reads the config file at path on top of the defaults
+// A missing file is not an error and yields Default()
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return Default(), fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Default(), fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate checks values that cannot be caught by JSON decoding
+func (c *Config) Validate() error {
+	for _, name := range c.Segments {
+		if _, ok := c.Plugins[name]; !knownSegments[name] && !ok {
+			return fmt.Errorf("unknown segment %q", name)
+		}
+	}
+	for name, plugin := range c.Plugins {
+		if knownSegments[name] {
+			return fmt.Errorf("plugin %q shadows a built-in segment", name)
+		}
+		if plugin.Command == "" {
+			return fmt.Errorf("plugin %q has no command", name)
+		}
+		if plugin.Timeout < 0 || plugin.TTL < 0 {
+			return fmt.Errorf("plugin %q: timeout and ttl must not be negative", name)
+		}
+	}
+	if c.Cwd.MaxWidth < 0 {
+		return errors.New("cwd.max_width must not be negative")
+	}
+	if c.Deadline < 0 {
+		return errors.New("deadline must not be negative")
+	}
+	if c.Session.IdleThreshold < 0 {
+		return errors.New("session.idle_threshold must not be negative")
+	}
+	if c.Metrics.MaxSessions <= 0 {
+		return errors.New("metrics.max_sessions must be positive")
+	}
+	if c.Metrics.Expiry <= 0 {
+		return errors.New("metrics.expiry must be positive")
+	}
+	for i, budget := range c.Budgets {
+		if !budgetPeriods[budget.Period] {
+			return fmt.Errorf("budgets[%d]: unknown period %q, want day, week or month", i, budget.Period)
+		}
+		if budget.Limit <= 0 {
+			return fmt.Errorf("budgets[%d]: limit must be positive", i)
+		}
+		if _, err := filepath.Match(budget.Project, ""); err != nil {
+			return fmt.Errorf("budgets[%d]: invalid project pattern %q", i, budget.Project)
+		}
+	}
+	for i, root := range c.TranscriptRoots {
+		if !filepath.IsAbs(root) && root != "~" && !strings.HasPrefix(root, "~/") {
+			return fmt.Errorf("transcript_roots[%d]: %q must be absolute", i, root)
+		}
+	}
+	for _, threshold := range c.Notify.Context {
+		if threshold <= 0 || threshold > 100 {
+			return fmt.Errorf("notify.context threshold %g must be in (0, 100]", threshold)
+		}
+	}
+	for _, threshold := range c.Notify.Cost {
+		if threshold <= 0 {
+			return fmt.Errorf("notify.cost threshold %g must be positive", threshold)
+		}
+	}
+	return nil
+}
+
+// Has reports whether the named segment is enabled
+func (c *Config) Has(segment string) bool {
+	for _, name := range c.Segments {
+		if name == segment {
+			return true
+		}
+	}
+	return false
+}
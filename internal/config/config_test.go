@@ -0,0 +1,268 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			name:    "empty object keeps defaults",
+			content: `{}`,
+			want:    Default(),
+		},
+		{
+			name:    "partial cwd settings merge with defaults",
+			content: `{"segments":["cwd","context"],"cwd":{"fish":true,"max_width":30}}`,
+			want: &Config{
+				Segments: []string{"cwd", "context"},
+				Cwd:      CwdConfig{Home: true, Fish: true, MaxWidth: 30},
+				Session:  Default().Session,
+				Metrics:  Default().Metrics,
+			},
+		},
+		{
+			name:    "home substitution can be disabled",
+			content: `{"cwd":{"home":false}}`,
+			want: &Config{
+				Segments: Default().Segments,
+				Cwd:      CwdConfig{Home: false},
+				Session:  Default().Session,
+				Metrics:  Default().Metrics,
+			},
+		},
+		{
+			name:    "session idle threshold",
+			content: `{"segments":["context","session"],"session":{"idle_threshold":"5m"}}`,
+			want: &Config{
+				Segments: []string{"context", "session"},
+				Cwd:      Default().Cwd,
+				Session:  SessionConfig{IdleThreshold: Duration(5 * time.Minute)},
+				Metrics:  Default().Metrics,
+			},
+		},
+		{
+			name:    "invalid duration",
+			content: `{"session":{"idle_threshold":"later"}}`,
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name:    "malformed json",
+			content: `{"segments":`,
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name:    "unknown segment",
+			content: `{"segments":["context","weather"]}`,
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name:    "metrics textfile keeps other defaults",
+			content: `{"metrics":{"textfile":"/var/lib/node_exporter/ccstatus.prom"}}`,
+			want: &Config{
+				Segments: Default().Segments,
+				Cwd:      Default().Cwd,
+				Session:  Default().Session,
+				Metrics: MetricsConfig{
+					Textfile:    "/var/lib/node_exporter/ccstatus.prom",
+					MaxSessions: 20,
+					Expiry:      Duration(time.Hour),
+					Listen:      "127.0.0.1:9464",
+				},
+			},
+		},
+		{
+			name:    "history database path",
+			content: `{"history":{"enabled":true,"path":"/data/ccstatus.db"}}`,
+			want: &Config{
+				Segments: Default().Segments,
+				Cwd:      Default().Cwd,
+				Session:  Default().Session,
+				Metrics:  Default().Metrics,
+				History:  HistoryConfig{Enabled: true, Path: "/data/ccstatus.db"},
+			},
+		},
+		{
+			name:    "notify thresholds",
+			content: `{"notify":{"command":"notify-send ccstatus","context":[70,90],"cost":[5]}}`,
+			want: &Config{
+				Segments: Default().Segments,
+				Cwd:      Default().Cwd,
+				Session:  Default().Session,
+				Metrics:  Default().Metrics,
+				Notify:   NotifyConfig{Command: "notify-send ccstatus", Context: []float64{70, 90}, Cost: []float64{5}},
+			},
+		},
+		{
+			name:    "context threshold above 100",
+			content: `{"notify":{"context":[120]}}`,
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name:    "negative cost threshold",
+			content: `{"notify":{"cost":[-1]}}`,
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name:    "budgets",
+			content: `{"segments":["context","budget"],"budgets":[{"period":"day","limit":50},{"name":"acme","project":"~/work/acme-*","period":"month","limit":1000}]}`,
+			want: &Config{
+				Segments: []string{"context", "budget"},
+				Cwd:      Default().Cwd,
+				Session:  Default().Session,
+				Metrics:  Default().Metrics,
+				Budgets: []BudgetConfig{
+					{Period: "day", Limit: 50},
+					{Name: "acme", Project: "~/work/acme-*", Period: "month", Limit: 1000},
+				},
+			},
+		},
+		{
+			name:    "unknown budget period",
+			content: `{"budgets":[{"period":"year","limit":50}]}`,
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name:    "budget without limit",
+			content: `{"budgets":[{"period":"day"}]}`,
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name:    "malformed budget pattern",
+			content: `{"budgets":[{"project":"/work/[","period":"day","limit":5}]}`,
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name:    "plugin segment",
+			content: `{"segments":["context","ci"],"plugins":{"ci":{"command":"ci-status","timeout":"100ms","ttl":"30s"}}}`,
+			want: &Config{
+				Segments: []string{"context", "ci"},
+				Cwd:      Default().Cwd,
+				Session:  Default().Session,
+				Metrics:  Default().Metrics,
+				Plugins: map[string]PluginConfig{
+					"ci": {Command: "ci-status", Timeout: Duration(100 * time.Millisecond), TTL: Duration(30 * time.Second)},
+				},
+			},
+		},
+		{
+			name:    "plugin shadowing a built-in segment",
+			content: `{"plugins":{"git":{"command":"my-git"}}}`,
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name:    "plugin without command",
+			content: `{"segments":["ci"],"plugins":{"ci":{}}}`,
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name:    "deadline",
+			content: `{"deadline":"150ms"}`,
+			want: &Config{
+				Segments: Default().Segments,
+				Cwd:      Default().Cwd,
+				Session:  Default().Session,
+				Metrics:  Default().Metrics,
+				Deadline: Duration(150 * time.Millisecond),
+			},
+		},
+		{
+			name:    "negative deadline",
+			content: `{"deadline":"-1s"}`,
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name:    "transcript roots",
+			content: `{"transcript_roots":["/mnt/transcripts","~/archive"]}`,
+			want: &Config{
+				Segments:        Default().Segments,
+				Cwd:             Default().Cwd,
+				Session:         Default().Session,
+				Metrics:         Default().Metrics,
+				TranscriptRoots: []string{"/mnt/transcripts", "~/archive"},
+			},
+		},
+		{
+			name:    "relative transcript root",
+			content: `{"transcript_roots":["transcripts"]}`,
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name:    "zero metrics session cap",
+			content: `{"metrics":{"max_sessions":0}}`,
+			want:    Default(),
+			wantErr: true,
+		},
+		{
+			name:    "negative width",
+			content: `{"cwd":{"max_width":-1}}`,
+			want:    Default(),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	got, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, Default()) {
+		t.Errorf("Load() = %+v, want defaults", got)
+	}
+}
+
+func TestPathEnvOverride(t *testing.T) {
+	t.Setenv(EnvPath, "/custom/config.json")
+	if got := Path(); got != "/custom/config.json" {
+		t.Errorf("Path() = %q, want %q", got, "/custom/config.json")
+	}
+}
+
+func TestHas(t *testing.T) {
+	cfg := &Config{Segments: []string{SegmentContext, SegmentCwd}}
+	if !cfg.Has(SegmentCwd) {
+		t.Error("Has(cwd) = false, want true")
+	}
+	if cfg.Has(SegmentGit) {
+		t.Error("Has(git) = true, want false")
+	}
+}
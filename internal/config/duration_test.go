@@ -0,0 +1,41 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDurationJSON(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: `"10m"`, want: 10 * time.Minute},
+		{input: `"150ms"`, want: 150 * time.Millisecond},
+		{input: `"1h30m"`, want: 90 * time.Minute},
+		{input: `600`, wantErr: true},
+		{input: `"soon"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var got Duration
+		err := json.Unmarshal([]byte(tt.input), &got)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.Std() != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.input, got.Std(), tt.want)
+		}
+	}
+
+	data, err := json.Marshal(Duration(90 * time.Second))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `"1m30s"` {
+		t.Errorf("Marshal() = %s, want \"1m30s\"", data)
+	}
+}
@@ -0,0 +1,275 @@
+package doctor
+
+import (
+	"ccstatus/internal/calculator"
+	"ccstatus/internal/config"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/settings"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// check outcomes
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// latency limits for a status line run, Claude Code refreshes it at most every 300ms
+const (
+	LatencyWarn = 100 * time.Millisecond
+	LatencyFail = 500 * time.Millisecond
+)
+
+// Result is the outcome of a single check
+type Result struct {
+	Name    string
+	Status  string
+	Message string
+	// Details are extra lines printed under the result
+	Details []string
+}
+
+// SettingsFile is a Claude Code settings file of a scope
+type SettingsFile struct {
+	Scope string
+	Path  string
+}
+
+// CheckSettings finds the statusLine entry Claude Code will use and checks it runs binary
+// files are given in precedence order, later files override earlier ones
+// Returns the result and the effective command, empty when none is configured
+func CheckSettings(files []SettingsFile, binary string) (Result, string) {
+	result := Result{Name: "settings"}
+	var effective *settings.StatusLine
+	var source SettingsFile
+	for _, file := range files {
+		entry, err := settings.ReadStatusLine(file.Path)
+		if err != nil {
+			result.Status = StatusFail
+			result.Message = err.Error()
+			return result, ""
+		}
+		if entry != nil {
+			effective, source = entry, file
+		}
+	}
+
+	switch {
+	case effective == nil || effective.Command == "":
+		result.Status = StatusFail
+		result.Message = "no statusLine command configured, run `ccstatus install`"
+		return result, ""
+	case effective.Type != "" && effective.Type != "command":
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("statusLine type is %q in %s, want \"command\"", effective.Type, source.Path)
+	case settings.CommandProgram(effective.Command) == binary:
+		result.Status = StatusPass
+		result.Message = fmt.Sprintf("%s settings run this binary", source.Scope)
+	case settings.IsCcstatusCommand(effective.Command, binary):
+		result.Status = StatusWarn
+		result.Message = fmt.Sprintf("%s settings run a different ccstatus: %s", source.Scope, effective.Command)
+	default:
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("%s settings run another command: %s", source.Scope, effective.Command)
+	}
+	result.Details = append(result.Details, "file: "+source.Path)
+	return result, effective.Command
+}
+
+// CheckExecutable checks that the program of a statusLine command can be run
+func CheckExecutable(command string) Result {
+	result := Result{Name: "binary"}
+	program := settings.CommandProgram(command)
+	if program == "" {
+		result.Status = StatusFail
+		result.Message = "no command to check"
+		return result
+	}
+	if strings.HasPrefix(program, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			program = filepath.Join(home, program[2:])
+		}
+	}
+
+	fi, err := os.Stat(program)
+	switch {
+	case err != nil:
+		result.Status = StatusFail
+		result.Message = err.Error()
+	case !fi.Mode().IsRegular():
+		result.Status = StatusFail
+		result.Message = program + " is not a regular file"
+	case fi.Mode().Perm()&0o111 == 0:
+		result.Status = StatusFail
+		result.Message = program + " is not executable"
+	default:
+		result.Status = StatusPass
+		result.Message = program + " is executable"
+	}
+	return result
+}
+
+// CheckConfig loads the ccstatus config file
+func CheckConfig(path string) Result {
+	result := Result{Name: "config"}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		result.Status = StatusPass
+		result.Message = "no config file, using defaults"
+		result.Details = []string{"file: " + path}
+		return result
+	}
+	if _, err := config.Load(path); err != nil {
+		result.Status = StatusFail
+		result.Message = err.Error()
+		return result
+	}
+	result.Status = StatusPass
+	result.Message = "loaded " + path
+	return result
+}
+
+// CheckTranscript parses a transcript the way the status line does and reports
+// malformed lines and models that fall back to DefaultContextTokens
+func CheckTranscript(path string) []Result {
+	parse := Result{Name: "transcript", Details: []string{"file: " + path}}
+	file, err := os.Open(path)
+	if err != nil {
+		parse.Status = StatusFail
+		parse.Message = err.Error()
+		return []Result{parse}
+	}
+	// archived transcripts are counted by their JSONL lines, not their compressed bytes
+	reader, _, err := parser.Decompress(file)
+	if err != nil {
+		file.Close()
+		parse.Status = StatusFail
+		parse.Message = err.Error()
+		return []Result{parse}
+	}
+	stats, err := parser.ReadLineStats(reader)
+	reader.Close()
+	file.Close()
+	if err != nil {
+		parse.Status = StatusFail
+		parse.Message = err.Error()
+		return []Result{parse}
+	}
+	if _, err := parser.ParseSession(path); err != nil {
+		parse.Status = StatusFail
+		parse.Message = err.Error()
+		return []Result{parse}
+	}
+
+	parse.Status = StatusPass
+	parse.Message = fmt.Sprintf("%d lines, %d with usage", stats.Lines, stats.WithUsage)
+	if stats.Malformed > 0 {
+		// a partial last line is a write in progress, not a problem
+		malformed := stats.Malformed
+		if stats.Partial && len(stats.MalformedLines) > 0 && stats.MalformedLines[len(stats.MalformedLines)-1] == stats.Lines {
+			malformed--
+		}
+		if malformed > 0 {
+			parse.Status = StatusWarn
+			parse.Message += fmt.Sprintf(", %d malformed skipped", malformed)
+			parse.Details = append(parse.Details, "malformed lines: "+joinInts(stats.MalformedLines))
+		}
+	}
+	if stats.Oversized > 0 {
+		parse.Status = StatusWarn
+		parse.Message += fmt.Sprintf(", %d over the 1 MiB line limit", stats.Oversized)
+	}
+
+	return []Result{parse, checkModels(path)}
+}
+
+// checkModels lists transcript models without a known context limit
+func checkModels(path string) Result {
+	result := Result{Name: "models"}
+	turns, err := parser.ParseTurns(path)
+	if err != nil {
+		result.Status = StatusFail
+		result.Message = err.Error()
+		return result
+	}
+
+	seen := map[string]bool{}
+	var unknown []string
+	for _, turn := range turns {
+		if turn.Model == "" || seen[turn.Model] {
+			continue
+		}
+		seen[turn.Model] = true
+		if !calculator.IsKnownModel(turn.Model) {
+			unknown = append(unknown, turn.Model)
+		}
+	}
+	sort.Strings(unknown)
+
+	if len(unknown) == 0 {
+		result.Status = StatusPass
+		result.Message = fmt.Sprintf("%d models, all with known context limits", len(seen))
+		return result
+	}
+	result.Status = StatusWarn
+	result.Message = fmt.Sprintf("%d unknown models use the default %d token limit", len(unknown), calculator.DefaultContextTokens)
+	result.Details = unknown
+	return result
+}
+
+// CheckLatency grades an end-to-end status line run
+func CheckLatency(elapsed time.Duration, err error) Result {
+	result := Result{Name: "latency", Message: elapsed.Round(100 * time.Microsecond).String()}
+	switch {
+	case err != nil:
+		result.Status = StatusFail
+		result.Message = err.Error()
+	case elapsed >= LatencyFail:
+		result.Status = StatusFail
+	case elapsed >= LatencyWarn:
+		result.Status = StatusWarn
+	default:
+		result.Status = StatusPass
+	}
+	return result
+}
+
+// Failed returns the number of failed checks
+func Failed(results []Result) int {
+	failed := 0
+	for _, result := range results {
+		if result.Status == StatusFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Write prints one line per check with details indented below
+func Write(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, result := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", strings.ToUpper(result.Status), result.Name, result.Message)
+		for _, detail := range result.Details {
+			fmt.Fprintf(tw, "\t\t  %s\n", detail)
+		}
+	}
+	return tw.Flush()
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
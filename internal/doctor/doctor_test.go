@@ -0,0 +1,198 @@
+package doctor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string, mode os.FileMode) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCheckSettings(t *testing.T) {
+	dir := t.TempDir()
+	user := writeFile(t, filepath.Join(dir, "user.json"), `{"statusLine":{"type":"command","command":"/bin/ccstatus"}}`, 0o600)
+	other := writeFile(t, filepath.Join(dir, "other.json"), `{"statusLine":{"type":"command","command":"/opt/ccstatus --flag"}}`, 0o600)
+	foreign := writeFile(t, filepath.Join(dir, "foreign.json"), `{"statusLine":{"type":"command","command":"status.sh"}}`, 0o600)
+	empty := writeFile(t, filepath.Join(dir, "empty.json"), `{"model":"opus"}`, 0o600)
+	broken := writeFile(t, filepath.Join(dir, "broken.json"), `{`, 0o600)
+	missing := filepath.Join(dir, "missing.json")
+
+	tests := []struct {
+		name        string
+		files       []SettingsFile
+		wantStatus  string
+		wantCommand string
+	}{
+		{"this binary", []SettingsFile{{"user", user}}, StatusPass, "/bin/ccstatus"},
+		{"local overrides user", []SettingsFile{{"user", user}, {"local", other}}, StatusWarn, "/opt/ccstatus --flag"},
+		{"missing files skipped", []SettingsFile{{"user", user}, {"project", missing}, {"local", empty}}, StatusPass, "/bin/ccstatus"},
+		{"foreign command", []SettingsFile{{"user", foreign}}, StatusFail, "status.sh"},
+		{"not configured", []SettingsFile{{"user", empty}}, StatusFail, ""},
+		{"invalid JSON", []SettingsFile{{"user", broken}}, StatusFail, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, command := CheckSettings(tt.files, "/bin/ccstatus")
+			if result.Status != tt.wantStatus {
+				t.Errorf("CheckSettings() status = %s (%s), want %s", result.Status, result.Message, tt.wantStatus)
+			}
+			if command != tt.wantCommand {
+				t.Errorf("CheckSettings() command = %q, want %q", command, tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestCheckExecutable(t *testing.T) {
+	dir := t.TempDir()
+	exe := writeFile(t, filepath.Join(dir, "ccstatus"), "#!/bin/sh\n", 0o755)
+	plain := writeFile(t, filepath.Join(dir, "plain"), "", 0o644)
+
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{"executable with args", exe + " --flag", StatusPass},
+		{"not executable", plain, StatusFail},
+		{"missing", filepath.Join(dir, "missing"), StatusFail},
+		{"directory", dir, StatusFail},
+		{"empty", "", StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckExecutable(tt.command); got.Status != tt.want {
+				t.Errorf("CheckExecutable(%q) = %s (%s), want %s", tt.command, got.Status, got.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	dir := t.TempDir()
+	valid := writeFile(t, filepath.Join(dir, "valid.json"), `{"segments":["context"]}`, 0o600)
+	invalid := writeFile(t, filepath.Join(dir, "invalid.json"), `{"segments":["nope"]}`, 0o600)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"missing uses defaults", filepath.Join(dir, "missing.json"), StatusPass},
+		{"valid", valid, StatusPass},
+		{"invalid", invalid, StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckConfig(tt.path); got.Status != tt.want {
+				t.Errorf("CheckConfig() = %s (%s), want %s", got.Status, got.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTranscript(t *testing.T) {
+	dir := t.TempDir()
+	clean := writeFile(t, filepath.Join(dir, "clean.jsonl"),
+		`{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":5}}}`+"\n"+
+			`{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":7}}}`+"\n"+
+			`{"type":"assis`, 0o600)
+	messy := writeFile(t, filepath.Join(dir, "messy.jsonl"),
+		"garbage\n"+`{"type":"assistant","message":{"role":"assistant","model":"claude-mystery-9","usage":{"input_tokens":5}}}`+"\n", 0o600)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantParse  string
+		wantModels string
+	}{
+		{"clean with partial write", clean, StatusPass, StatusPass},
+		{"malformed and unknown model", messy, StatusWarn, StatusWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := CheckTranscript(tt.path)
+			if len(results) != 2 {
+				t.Fatalf("CheckTranscript() = %+v, want 2 results", results)
+			}
+			if results[0].Status != tt.wantParse {
+				t.Errorf("transcript status = %s (%s), want %s", results[0].Status, results[0].Message, tt.wantParse)
+			}
+			if results[1].Status != tt.wantModels {
+				t.Errorf("models status = %s (%s), want %s", results[1].Status, results[1].Message, tt.wantModels)
+			}
+		})
+	}
+
+	// an archive is checked by its decompressed lines
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(`{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":5}}}` + "\n" +
+		`{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":7}}}` + "\n"))
+	w.Close()
+	archived := writeFile(t, filepath.Join(dir, "archived.jsonl.gz"), gz.String(), 0o600)
+	if results := CheckTranscript(archived); results[0].Status != StatusPass || results[0].Message != "2 lines, 2 with usage" {
+		t.Errorf("CheckTranscript(archive) = %s (%s), want PASS with 2 lines, 2 with usage", results[0].Status, results[0].Message)
+	}
+
+	results := CheckTranscript(filepath.Join(dir, "missing.jsonl"))
+	if len(results) != 1 || results[0].Status != StatusFail {
+		t.Errorf("CheckTranscript(missing) = %+v, want one failure", results)
+	}
+}
+
+func TestCheckLatency(t *testing.T) {
+	tests := []struct {
+		elapsed time.Duration
+		err     error
+		want    string
+	}{
+		{20 * time.Millisecond, nil, StatusPass},
+		{LatencyWarn, nil, StatusWarn},
+		{LatencyFail, nil, StatusFail},
+		{time.Millisecond, errors.New("boom"), StatusFail},
+	}
+
+	for _, tt := range tests {
+		if got := CheckLatency(tt.elapsed, tt.err); got.Status != tt.want {
+			t.Errorf("CheckLatency(%v, %v) = %s, want %s", tt.elapsed, tt.err, got.Status, tt.want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	results := []Result{
+		{Name: "settings", Status: StatusPass, Message: "ok", Details: []string{"file: x"}},
+		{Name: "binary", Status: StatusFail, Message: "missing"},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, results); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, want := range []string{"PASS  settings  ok", "file: x", "FAIL  binary    missing"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Write() does not contain %q, got:\n%s", want, buf.String())
+		}
+	}
+	if got := Failed(results); got != 1 {
+		t.Errorf("Failed() = %d, want 1", got)
+	}
+}
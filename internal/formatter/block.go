@@ -0,0 +1,60 @@
+package formatter
+
+import (
+	"ccstatus/internal/calculator"
+	"fmt"
+	"time"
+)
+
+// FormatBlock renders the 5-hour block segment, returns empty string when no block is active
+// automatically detects TTY and falls back to plain output
+func FormatBlock(block *calculator.Block, now time.Time) string {
+	if !useColors() {
+		return FormatBlockPlain(block, now)
+	}
+	return formatBlockWithColors(block, now)
+}
+
+// format: [5h: 1.2M $3.42 2h13m left]
+func FormatBlockPlain(block *calculator.Block, now time.Time) string {
+	if block == nil {
+		return ""
+	}
+	return fmt.Sprintf("[5h: %s %s %s left]",
+		FormatTokens(block.Tokens.Total()),
+		FormatCost(block.Cost),
+		FormatDuration(block.Remaining(now)),
+	)
+}
+
+func formatBlockWithColors(block *calculator.Block, now time.Time) string {
+	if block == nil {
+		return ""
+	}
+	return fmt.Sprintf("[5h: %s %s%s%s %s%s left%s]",
+		FormatTokens(block.Tokens.Total()),
+		ColorYellow,
+		FormatCost(block.Cost),
+		ColorReset,
+		ColorDim,
+		FormatDuration(block.Remaining(now)),
+		ColorReset,
+	)
+}
+
+// FormatTokens renders a token count compactly: 950, 12.3k, 1.2M
+func FormatTokens(tokens int64) string {
+	switch {
+	case tokens < 1000:
+		return fmt.Sprintf("%d", tokens)
+	case tokens < 1_000_000:
+		return fmt.Sprintf("%.1fk", float64(tokens)/1000)
+	default:
+		return fmt.Sprintf("%.1fM", float64(tokens)/1_000_000)
+	}
+}
+
+// FormatCost renders a USD amount with cent precision
+func FormatCost(cost float64) string {
+	return fmt.Sprintf("$%.2f", cost)
+}
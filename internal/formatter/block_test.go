@@ -0,0 +1,69 @@
+package formatter
+
+import (
+	"ccstatus/internal/calculator"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatBlockPlain(t *testing.T) {
+	start := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	block := &calculator.Block{
+		Start:  start,
+		End:    start.Add(calculator.BlockDuration),
+		Tokens: calculator.TokenTotals{InputTokens: 200_000, CacheReadInputTokens: 1_000_000},
+		Cost:   3.421,
+	}
+
+	tests := []struct {
+		name  string
+		block *calculator.Block
+		now   time.Time
+		want  string
+	}{
+		{
+			name:  "no active block",
+			block: nil,
+			now:   start,
+			want:  "",
+		},
+		{
+			name:  "active block",
+			block: block,
+			now:   start.Add(2*time.Hour + 47*time.Minute),
+			want:  "[5h: 1.2M $3.42 2h13m left]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatBlockPlain(tt.block, tt.now); got != tt.want {
+				t.Errorf("FormatBlockPlain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	colored := formatBlockWithColors(block, start.Add(time.Hour))
+	if !strings.Contains(colored, ColorYellow+"$3.42"+ColorReset) || !strings.Contains(colored, "4h left") {
+		t.Errorf("formatBlockWithColors() = %q", colored)
+	}
+}
+
+func TestFormatTokens(t *testing.T) {
+	tests := []struct {
+		tokens int64
+		want   string
+	}{
+		{tokens: 0, want: "0"},
+		{tokens: 950, want: "950"},
+		{tokens: 12_345, want: "12.3k"},
+		{tokens: 1_234_567, want: "1.2M"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatTokens(tt.tokens); got != tt.want {
+			t.Errorf("FormatTokens(%d) = %q, want %q", tt.tokens, got, tt.want)
+		}
+	}
+}
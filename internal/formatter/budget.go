@@ -0,0 +1,78 @@
+package formatter
+
+import (
+	"ccstatus/internal/calculator"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// periodLabels name the current period of a budget
+var periodLabels = map[string]string{
+	calculator.PeriodDay:   "today",
+	calculator.PeriodWeek:  "this week",
+	calculator.PeriodMonth: "this month",
+}
+
+// FormatBudget renders spend against every budget, returns empty string without budgets
+// automatically detects TTY and falls back to plain output
+func FormatBudget(statuses []calculator.BudgetStatus) string {
+	if !useColors() {
+		return FormatBudgetPlain(statuses)
+	}
+	return formatBudgetWithColors(statuses)
+}
+
+// format: [$12/$50 today, acme: $310/$1000 this month (→$1240)]
+// the projection is shown when it exceeds the cap, a reached cap is marked with !
+func FormatBudgetPlain(statuses []calculator.BudgetStatus) string {
+	if len(statuses) == 0 {
+		return ""
+	}
+	parts := make([]string, len(statuses))
+	for i, status := range statuses {
+		parts[i] = budgetText(status)
+		switch {
+		case status.Exceeded():
+			parts[i] += " !"
+		case status.AtRisk():
+			parts[i] += fmt.Sprintf(" (→%s)", formatAmount(status.Projected))
+		}
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func formatBudgetWithColors(statuses []calculator.BudgetStatus) string {
+	if len(statuses) == 0 {
+		return ""
+	}
+	parts := make([]string, len(statuses))
+	for i, status := range statuses {
+		switch {
+		case status.Exceeded():
+			parts[i] = ColorRed + budgetText(status) + ColorReset
+		case status.AtRisk():
+			parts[i] = ColorYellow + budgetText(status) + ColorReset +
+				ColorDim + fmt.Sprintf(" (→%s)", formatAmount(status.Projected)) + ColorReset
+		default:
+			parts[i] = budgetText(status)
+		}
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func budgetText(status calculator.BudgetStatus) string {
+	text := fmt.Sprintf("%s/%s %s", formatAmount(status.Spent), formatAmount(status.Budget.Limit), periodLabels[status.Budget.Period])
+	if status.Budget.Name != "" {
+		text = status.Budget.Name + ": " + text
+	}
+	return text
+}
+
+// formatAmount renders whole dollars, cents only for small fractional amounts: $12, $3.42
+func formatAmount(amount float64) string {
+	if amount < 10 && amount != math.Trunc(amount) {
+		return fmt.Sprintf("$%.2f", amount)
+	}
+	return fmt.Sprintf("$%.0f", amount)
+}
@@ -0,0 +1,74 @@
+package formatter
+
+import (
+	"ccstatus/internal/calculator"
+	"strings"
+	"testing"
+)
+
+func TestFormatBudgetPlain(t *testing.T) {
+	daily := calculator.Budget{Period: calculator.PeriodDay, Limit: 50}
+	monthly := calculator.Budget{Name: "acme", Period: calculator.PeriodMonth, Limit: 1000}
+
+	tests := []struct {
+		name     string
+		statuses []calculator.BudgetStatus
+		want     string
+	}{
+		{
+			name:     "no budgets",
+			statuses: nil,
+			want:     "",
+		},
+		{
+			name:     "within budget",
+			statuses: []calculator.BudgetStatus{{Budget: daily, Spent: 12.37, Projected: 30}},
+			want:     "[$12/$50 today]",
+		},
+		{
+			name:     "small amounts keep cents",
+			statuses: []calculator.BudgetStatus{{Budget: calculator.Budget{Period: calculator.PeriodWeek, Limit: 5}, Spent: 3.421, Projected: 4}},
+			want:     "[$3.42/$5 this week]",
+		},
+		{
+			name: "projected overspend",
+			statuses: []calculator.BudgetStatus{
+				{Budget: daily, Spent: 12, Projected: 30},
+				{Budget: monthly, Spent: 310, Projected: 1240.4},
+			},
+			want: "[$12/$50 today, acme: $310/$1000 this month (→$1240)]",
+		},
+		{
+			name:     "cap reached",
+			statuses: []calculator.BudgetStatus{{Budget: daily, Spent: 51, Projected: 80}},
+			want:     "[$51/$50 today !]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatBudgetPlain(tt.statuses); got != tt.want {
+				t.Errorf("FormatBudgetPlain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBudgetWithColors(t *testing.T) {
+	daily := calculator.Budget{Period: calculator.PeriodDay, Limit: 50}
+	tests := []struct {
+		name   string
+		status calculator.BudgetStatus
+		want   string
+	}{
+		{"within budget", calculator.BudgetStatus{Budget: daily, Spent: 12, Projected: 30}, "[$12/$50 today]"},
+		{"at risk", calculator.BudgetStatus{Budget: daily, Spent: 30, Projected: 60}, ColorYellow + "$30/$50 today" + ColorReset},
+		{"exceeded", calculator.BudgetStatus{Budget: daily, Spent: 50, Projected: 60}, ColorRed + "$50/$50 today" + ColorReset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatBudgetWithColors([]calculator.BudgetStatus{tt.status}); !strings.Contains(got, tt.want) {
+				t.Errorf("formatBudgetWithColors() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package formatter
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ColorBlue is used for the working directory
+const ColorBlue = "\033[34m"
+
+// ellipsis marks the truncated middle of a long path
+const ellipsis = "…"
+
+// PathOptions controls how ShortenPath displays a directory
+type PathOptions struct {
+	// Home is replaced with ~ when it prefixes the path, empty disables substitution
+	Home string
+	// ProjectRoot makes paths inside it relative to the root, keeping the root name
+	ProjectRoot string
+	// Fish enables fish-style abbreviation, see ShortenPath
+	Fish bool
+	// MaxWidth truncates the result with a middle ellipsis, 0 disables the limit
+	MaxWidth int
+}
+
+// FormatCwd renders the working directory segment, returns empty string for an empty path
+// automatically detects TTY and falls back to plain output
+func FormatCwd(path string, opts PathOptions) string {
+	if !useColors() {
+		return FormatCwdPlain(path, opts)
+	}
+	return formatCwdWithColors(path, opts)
+}
+
+// format: ~/w/p/ccstatus
+func FormatCwdPlain(path string, opts PathOptions) string {
+	return ShortenPath(path, opts)
+}
+
+func formatCwdWithColors(path string, opts PathOptions) string {
+	short := ShortenPath(path, opts)
+	if short == "" {
+		return ""
+	}
+	return ColorBlue + short + ColorReset
+}
+
+// ShortenPath applies project-relative display, home substitution,
+// fish-style abbreviation and the width limit, in that order
+// e.g. /home/me/work/projects/ccstatus -> ~/w/p/ccstatus
+func ShortenPath(path string, opts PathOptions) string {
+	if path == "" {
+		return ""
+	}
+	path = filepath.Clean(path)
+
+	display, relative := projectRelative(path, opts.ProjectRoot)
+	if !relative {
+		display = substituteHome(path, opts.Home)
+	}
+	if opts.Fish {
+		display = abbreviate(display)
+	}
+	return truncateMiddle(display, opts.MaxWidth)
+}
+
+// projectRelative returns root name + path below root, e.g. ccstatus/internal/parser
+func projectRelative(path, root string) (string, bool) {
+	if root == "" {
+		return "", false
+	}
+	root = filepath.Clean(root)
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	name := filepath.Base(root)
+	if rel == "." {
+		return name, true
+	}
+	return filepath.Join(name, rel), true
+}
+
+func substituteHome(path, home string) string {
+	if home == "" {
+		return path
+	}
+	home = filepath.Clean(home)
+	if home == string(filepath.Separator) {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+		return "~" + string(filepath.Separator) + rest
+	}
+	return path
+}
+
+// abbreviate shortens intermediate components to their first letter,
+// keeping the leading dot of hidden directories (.config -> .c)
+func abbreviate(path string) string {
+	parts := strings.Split(path, string(filepath.Separator))
+	if len(parts) <= 2 {
+		return path
+	}
+
+	// the first component is the root name, ~ or empty for absolute paths
+	for i := 1; i < len(parts)-1; i++ {
+		parts[i] = abbreviateComponent(parts[i])
+	}
+	return strings.Join(parts, string(filepath.Separator))
+}
+
+func abbreviateComponent(name string) string {
+	runes := []rune(name)
+	if len(runes) == 0 {
+		return name
+	}
+	if runes[0] == '.' && len(runes) > 1 {
+		return string(runes[:2])
+	}
+	return string(runes[:1])
+}
+
+// truncateMiddle keeps the start and the end of s around an ellipsis
+// favoring the end, which usually holds the most specific directory
+func truncateMiddle(s string, maxWidth int) string {
+	runes := []rune(s)
+	if maxWidth <= 0 || len(runes) <= maxWidth {
+		return s
+	}
+	if maxWidth == 1 {
+		return ellipsis
+	}
+	head := (maxWidth - 1) / 2
+	tail := maxWidth - 1 - head
+	return string(runes[:head]) + ellipsis + string(runes[len(runes)-tail:])
+}
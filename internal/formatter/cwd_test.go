@@ -0,0 +1,143 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestShortenPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		opts PathOptions
+		want string
+	}{
+		{
+			name: "empty path",
+			path: "",
+			opts: PathOptions{Home: "/home/me"},
+			want: "",
+		},
+		{
+			name: "no options keeps full path",
+			path: "/home/me/work/projects/ccstatus",
+			want: "/home/me/work/projects/ccstatus",
+		},
+		{
+			name: "home substitution",
+			path: "/home/me/work/projects/ccstatus",
+			opts: PathOptions{Home: "/home/me"},
+			want: "~/work/projects/ccstatus",
+		},
+		{
+			name: "home itself",
+			path: "/home/me/",
+			opts: PathOptions{Home: "/home/me"},
+			want: "~",
+		},
+		{
+			name: "sibling of home is not substituted",
+			path: "/home/meow/src",
+			opts: PathOptions{Home: "/home/me"},
+			want: "/home/meow/src",
+		},
+		{
+			name: "fish style with home",
+			path: "/home/me/work/projects/ccstatus",
+			opts: PathOptions{Home: "/home/me", Fish: true},
+			want: "~/w/p/ccstatus",
+		},
+		{
+			name: "fish style absolute path",
+			path: "/usr/local/share/doc",
+			opts: PathOptions{Fish: true},
+			want: "/u/l/s/doc",
+		},
+		{
+			name: "fish style keeps hidden dir dot",
+			path: "/home/me/.config/ccstatus",
+			opts: PathOptions{Home: "/home/me", Fish: true},
+			want: "~/.c/ccstatus",
+		},
+		{
+			name: "project relative",
+			path: "/home/me/work/ccstatus/internal/parser",
+			opts: PathOptions{Home: "/home/me", ProjectRoot: "/home/me/work/ccstatus"},
+			want: "ccstatus/internal/parser",
+		},
+		{
+			name: "project root itself",
+			path: "/home/me/work/ccstatus",
+			opts: PathOptions{ProjectRoot: "/home/me/work/ccstatus"},
+			want: "ccstatus",
+		},
+		{
+			name: "project relative with fish keeps root name",
+			path: "/home/me/work/ccstatus/internal/parser",
+			opts: PathOptions{ProjectRoot: "/home/me/work/ccstatus", Fish: true},
+			want: "ccstatus/i/parser",
+		},
+		{
+			name: "outside project falls back to home",
+			path: "/home/me/other",
+			opts: PathOptions{Home: "/home/me", ProjectRoot: "/home/me/work/ccstatus"},
+			want: "~/other",
+		},
+		{
+			name: "max width middle ellipsis",
+			path: "/home/me/work/projects/ccstatus",
+			opts: PathOptions{Home: "/home/me", MaxWidth: 12},
+			want: "~/wor…status",
+		},
+		{
+			name: "max width not reached",
+			path: "/tmp/x",
+			opts: PathOptions{MaxWidth: 12},
+			want: "/tmp/x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ShortenPath(tt.path, tt.opts)
+			if got != tt.want {
+				t.Errorf("ShortenPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+			if tt.opts.MaxWidth > 0 && utf8.RuneCountInString(got) > tt.opts.MaxWidth {
+				t.Errorf("ShortenPath(%q) = %q exceeds max width %d", tt.path, got, tt.opts.MaxWidth)
+			}
+		})
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		s        string
+		maxWidth int
+		want     string
+	}{
+		{s: "abcdef", maxWidth: 0, want: "abcdef"},
+		{s: "abcdef", maxWidth: 6, want: "abcdef"},
+		{s: "abcdef", maxWidth: 5, want: "ab…ef"},
+		{s: "abcdef", maxWidth: 4, want: "a…ef"},
+		{s: "abcdef", maxWidth: 1, want: "…"},
+		{s: "проекты/код", maxWidth: 5, want: "пр…од"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateMiddle(tt.s, tt.maxWidth); got != tt.want {
+			t.Errorf("truncateMiddle(%q, %d) = %q, want %q", tt.s, tt.maxWidth, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCwdWithColors(t *testing.T) {
+	got := formatCwdWithColors("/home/me/src", PathOptions{Home: "/home/me"})
+	if !strings.HasPrefix(got, ColorBlue) || !strings.HasSuffix(got, ColorReset) || !strings.Contains(got, "~/src") {
+		t.Errorf("formatCwdWithColors() = %q", got)
+	}
+	if got := formatCwdWithColors("", PathOptions{}); got != "" {
+		t.Errorf("formatCwdWithColors(\"\") = %q, want empty", got)
+	}
+}
@@ -0,0 +1,102 @@
+package formatter
+
+import (
+	"strings"
+)
+
+// dialects render the colored status line for hosts other than a terminal
+const (
+	DialectTmux     = "tmux"
+	DialectZsh      = "zsh"
+	DialectBash     = "bash"
+	DialectStarship = "starship"
+)
+
+// IsDialect reports whether name is a known dialect
+func IsDialect(name string) bool {
+	switch name {
+	case DialectTmux, DialectZsh, DialectBash, DialectStarship:
+		return true
+	}
+	return false
+}
+
+// sgrNames maps the SGR parameters of the color codes above to color names
+// that tmux and zsh understand
+var sgrNames = map[string]string{
+	"31": "red",
+	"32": "green",
+	"33": "yellow",
+	"34": "blue",
+	"35": "magenta",
+	"36": "cyan",
+}
+
+// Translate converts a status line rendered with ANSI colors into dialect:
+// tmux #[fg=...] styles, zsh %F{...} and %{ %} escapes, bash \[ \] wrapped escapes,
+// or plain text for a starship custom module, which styles the output itself
+// text that the host would interpret is escaped; an unknown dialect returns line unchanged
+func Translate(line, dialect string) string {
+	if !IsDialect(dialect) {
+		return line
+	}
+	var b strings.Builder
+	for {
+		start := strings.Index(line, "\033[")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(line[start:], 'm')
+		if end < 0 {
+			break
+		}
+		b.WriteString(escapeText(line[:start], dialect))
+		b.WriteString(translateSGR(line[start+2:start+end], dialect))
+		line = line[start+end+1:]
+	}
+	b.WriteString(escapeText(line, dialect))
+	return b.String()
+}
+
+// translateSGR renders one SGR sequence, given by its parameters, in dialect
+func translateSGR(params, dialect string) string {
+	switch dialect {
+	case DialectTmux:
+		switch params {
+		case "0":
+			return "#[default]"
+		case "2":
+			return "#[dim]"
+		}
+		if name, ok := sgrNames[params]; ok {
+			return "#[fg=" + name + "]"
+		}
+		return ""
+	case DialectZsh:
+		if name, ok := sgrNames[params]; ok {
+			return "%F{" + name + "}"
+		}
+		if params == "0" {
+			return "%f%{\033[0m%}"
+		}
+		return "%{\033[" + params + "m%}"
+	case DialectBash:
+		return `\[\e[` + params + `m\]`
+	}
+	return ""
+}
+
+// escapeText escapes the characters dialect would interpret in plain text
+func escapeText(text, dialect string) string {
+	switch dialect {
+	case DialectTmux:
+		return strings.ReplaceAll(text, "#", "##")
+	case DialectZsh:
+		return strings.ReplaceAll(text, "%", "%%")
+	case DialectBash:
+		// PS1 is decoded and then expanded, a doubled backslash survives the decoding
+		// to quote the character after it in the expansion
+		return strings.NewReplacer(`\`, `\\\\`, "$", `\\$`, "`", "\\\\`").Replace(text)
+	}
+	return text
+}
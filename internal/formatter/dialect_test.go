@@ -0,0 +1,46 @@
+package formatter
+
+import (
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	line := ColorGreen + "[ctx: 1/2 50.0%]" + ColorReset + " " + ColorCyan + "m" + ColorReset + ColorDim + " #1 $2 `x` \\" + ColorReset
+	tests := []struct {
+		dialect string
+		want    string
+	}{
+		{DialectTmux, "#[fg=green][ctx: 1/2 50.0%]#[default] #[fg=cyan]m#[default]#[dim] ##1 $2 `x` \\#[default]"},
+		{DialectZsh, "%F{green}[ctx: 1/2 50.0%%]%f%{\033[0m%} %F{cyan}m%f%{\033[0m%}%{\033[2m%} #1 $2 `x` \\%f%{\033[0m%}"},
+		{DialectBash, `\[\e[32m\][ctx: 1/2 50.0%]\[\e[0m\] \[\e[36m\]m\[\e[0m\]\[\e[2m\] #1 \\$2 \\` + "`x\\\\` \\\\\\\\" + `\[\e[0m\]`},
+		{DialectStarship, "[ctx: 1/2 50.0%] m #1 $2 `x` \\"},
+		{"text", line},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			if got := Translate(line, tt.dialect); got != tt.want {
+				t.Errorf("Translate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDialect(t *testing.T) {
+	for _, name := range []string{DialectTmux, DialectZsh, DialectBash, DialectStarship} {
+		if !IsDialect(name) {
+			t.Errorf("IsDialect(%q) = false, want true", name)
+		}
+	}
+	if IsDialect("text") {
+		t.Error("IsDialect(text) = true, want false")
+	}
+}
+
+func TestForceColors(t *testing.T) {
+	ForceColors(true)
+	defer ForceColors(false)
+	if got := FormatErrorCode("read"); got != ColorRed+"!read"+ColorReset {
+		t.Errorf("FormatErrorCode() = %q, want colors when forced", got)
+	}
+}
@@ -0,0 +1,55 @@
+package formatter
+
+import (
+	"ccstatus/internal/calculator"
+	"fmt"
+)
+
+// FormatAPIErrors renders the API errors segment, returns empty string when no request failed or was interrupted
+// automatically detects TTY and falls back to plain output
+func FormatAPIErrors(info calculator.ContextInfo) string {
+	if !useColors() {
+		return FormatAPIErrorsPlain(info)
+	}
+	return formatAPIErrorsWithColors(info)
+}
+
+// format: 2 API errors, 1 interruption this session
+func FormatAPIErrorsPlain(info calculator.ContextInfo) string {
+	counts := apiErrorCounts(info)
+	if counts == "" {
+		return ""
+	}
+	return counts + " this session"
+}
+
+func formatAPIErrorsWithColors(info calculator.ContextInfo) string {
+	counts := apiErrorCounts(info)
+	if counts == "" {
+		return ""
+	}
+	return ColorRed + counts + ColorReset + ColorDim + " this session" + ColorReset
+}
+
+// apiErrorCounts lists the non-zero counts, empty when both are zero
+func apiErrorCounts(info calculator.ContextInfo) string {
+	var counts string
+	if info.APIErrors > 0 {
+		counts = plural(info.APIErrors, "API error")
+	}
+	if info.Interruptions > 0 {
+		if counts != "" {
+			counts += ", "
+		}
+		counts += plural(info.Interruptions, "interruption")
+	}
+	return counts
+}
+
+// plural renders a count with its noun: 1 API error, 2 API errors
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,56 @@
+package formatter
+
+import (
+	"ccstatus/internal/calculator"
+	"testing"
+)
+
+func TestFormatAPIErrorsPlain(t *testing.T) {
+	tests := []struct {
+		name string
+		info calculator.ContextInfo
+		want string
+	}{
+		{
+			name: "no errors",
+			info: calculator.ContextInfo{},
+			want: "",
+		},
+		{
+			name: "one error",
+			info: calculator.ContextInfo{APIErrors: 1},
+			want: "1 API error this session",
+		},
+		{
+			name: "errors",
+			info: calculator.ContextInfo{APIErrors: 2},
+			want: "2 API errors this session",
+		},
+		{
+			name: "only interruptions",
+			info: calculator.ContextInfo{Interruptions: 3},
+			want: "3 interruptions this session",
+		},
+		{
+			name: "errors and an interruption",
+			info: calculator.ContextInfo{APIErrors: 2, Interruptions: 1},
+			want: "2 API errors, 1 interruption this session",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatAPIErrorsPlain(tt.info); got != tt.want {
+				t.Errorf("FormatAPIErrorsPlain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	colored := formatAPIErrorsWithColors(calculator.ContextInfo{APIErrors: 2})
+	if want := ColorRed + "2 API errors" + ColorReset + ColorDim + " this session" + ColorReset; colored != want {
+		t.Errorf("formatAPIErrorsWithColors() = %q, want %q", colored, want)
+	}
+	if got := formatAPIErrorsWithColors(calculator.ContextInfo{}); got != "" {
+		t.Errorf("formatAPIErrorsWithColors() = %q, want empty", got)
+	}
+}
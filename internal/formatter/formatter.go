@@ -4,6 +4,7 @@ import (
 	"ccstatus/internal/calculator"
 	"fmt"
 	"os"
+	"sync/atomic"
 )
 
 // ANSI color codes
@@ -27,9 +28,22 @@ func isTerminal(f *os.File) bool {
 	return fi.Mode()&os.ModeCharDevice != 0
 }
 
+// forceColors makes the Format functions render colors when stdout is not a terminal
+var forceColors atomic.Bool
+
+// ForceColors renders colors regardless of stdout, for dialects that translate them
+func ForceColors(on bool) {
+	forceColors.Store(on)
+}
+
+// useColors reports whether the Format functions render colors
+func useColors() bool {
+	return forceColors.Load() || isTerminal(os.Stdout)
+}
+
 // automatically detects TTY and falls back to plain output
 func Format(info calculator.ContextInfo, model string) string {
-	if !isTerminal(os.Stdout) {
+	if !useColors() {
 		return FormatPlain(info, model)
 	}
 	return formatWithColors(info, model)
@@ -62,7 +76,7 @@ func FormatPlain(info calculator.ContextInfo, model string) string {
 
 // automatically detects TTY and falls back to plain output
 func FormatError(errorMsg string) string {
-	if !isTerminal(os.Stdout) {
+	if !useColors() {
 		return fmt.Sprintf("[ERROR: %s]", errorMsg)
 	}
 	return fmt.Sprintf("%s[ERROR: %s]%s",
@@ -84,3 +98,39 @@ func getColor(level string) string {
 		return ColorReset
 	}
 }
+
+// FormatStale marks a segment carried over from an earlier run
+// automatically detects TTY and falls back to plain output
+func FormatStale(segment string) string {
+	if !useColors() {
+		return FormatStalePlain(segment)
+	}
+	return formatStaleWithColors(segment)
+}
+
+// format: ~[ctx: 59261/200000 29.6%]
+func FormatStalePlain(segment string) string {
+	return "~" + segment
+}
+
+func formatStaleWithColors(segment string) string {
+	return ColorDim + "~" + ColorReset + segment
+}
+
+// FormatErrorCode renders the short code of a failure the status line recovered from
+// automatically detects TTY and falls back to plain output
+func FormatErrorCode(code string) string {
+	if !useColors() {
+		return FormatErrorCodePlain(code)
+	}
+	return formatErrorCodeWithColors(code)
+}
+
+// format: !nofile
+func FormatErrorCodePlain(code string) string {
+	return "!" + code
+}
+
+func formatErrorCodeWithColors(code string) string {
+	return ColorRed + "!" + code + ColorReset
+}
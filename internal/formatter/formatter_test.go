@@ -168,3 +168,21 @@ func TestIsTerminal(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatStale(t *testing.T) {
+	if got := FormatStalePlain("[ctx: 1/2 50.0%]"); got != "~[ctx: 1/2 50.0%]" {
+		t.Errorf("FormatStalePlain() = %q, want ~ prefix", got)
+	}
+	if got := formatStaleWithColors("main"); got != ColorDim+"~"+ColorReset+"main" {
+		t.Errorf("formatStaleWithColors() = %q, want dim ~ prefix", got)
+	}
+}
+
+func TestFormatErrorCode(t *testing.T) {
+	if got := FormatErrorCodePlain("nofile"); got != "!nofile" {
+		t.Errorf("FormatErrorCodePlain() = %q, want !nofile", got)
+	}
+	if got := formatErrorCodeWithColors("read"); got != ColorRed+"!read"+ColorReset {
+		t.Errorf("formatErrorCodeWithColors() = %q, want red !read", got)
+	}
+}
@@ -0,0 +1,76 @@
+package formatter
+
+import (
+	"ccstatus/internal/git"
+	"fmt"
+	"strings"
+)
+
+// ColorMagenta is used for the git branch
+const ColorMagenta = "\033[35m"
+
+// FormatGit renders the git segment, returns empty string when status is nil
+// automatically detects TTY and falls back to plain output
+func FormatGit(status *git.Status) string {
+	if !useColors() {
+		return FormatGitPlain(status)
+	}
+	return formatGitWithColors(status)
+}
+
+// format: main* ↑1↓2
+func FormatGitPlain(status *git.Status) string {
+	if status == nil {
+		return ""
+	}
+	return gitRef(status) + gitDirtyMarker(status) + gitAheadBehind(status)
+}
+
+func formatGitWithColors(status *git.Status) string {
+	if status == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(ColorMagenta + gitRef(status) + ColorReset)
+	if marker := gitDirtyMarker(status); marker != "" {
+		b.WriteString(ColorYellow + marker + ColorReset)
+	}
+	if counts := gitAheadBehind(status); counts != "" {
+		b.WriteString(ColorCyan + counts + ColorReset)
+	}
+	return b.String()
+}
+
+// gitRef returns the branch name or the short hash for a detached HEAD
+func gitRef(status *git.Status) string {
+	switch {
+	case status.Branch != "":
+		return status.Branch
+	case status.Head != "":
+		return "@" + status.ShortHead()
+	default:
+		return "@unknown"
+	}
+}
+
+func gitDirtyMarker(status *git.Status) string {
+	if status.Dirty {
+		return "*"
+	}
+	return ""
+}
+
+func gitAheadBehind(status *git.Status) string {
+	var s string
+	if status.Ahead > 0 {
+		s += fmt.Sprintf("↑%d", status.Ahead)
+	}
+	if status.Behind > 0 {
+		s += fmt.Sprintf("↓%d", status.Behind)
+	}
+	if s != "" {
+		s = " " + s
+	}
+	return s
+}
@@ -0,0 +1,63 @@
+package formatter
+
+import (
+	"ccstatus/internal/git"
+	"strings"
+	"testing"
+)
+
+func TestFormatGitPlain(t *testing.T) {
+	tests := []struct {
+		name   string
+		status *git.Status
+		want   string
+	}{
+		{
+			name:   "nil status",
+			status: nil,
+			want:   "",
+		},
+		{
+			name:   "clean branch",
+			status: &git.Status{Branch: "main"},
+			want:   "main",
+		},
+		{
+			name:   "dirty branch ahead and behind",
+			status: &git.Status{Branch: "feature/x", Dirty: true, Ahead: 2, Behind: 1},
+			want:   "feature/x* ↑2↓1",
+		},
+		{
+			name:   "detached head",
+			status: &git.Status{Head: "0123456789abcdef0123456789abcdef01234567", Detached: true},
+			want:   "@0123456",
+		},
+		{
+			name:   "only behind",
+			status: &git.Status{Branch: "main", Behind: 3},
+			want:   "main ↓3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatGitPlain(tt.status); got != tt.want {
+				t.Errorf("FormatGitPlain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatGitWithColors(t *testing.T) {
+	got := formatGitWithColors(&git.Status{Branch: "main", Dirty: true, Ahead: 1})
+
+	for _, want := range []string{ColorMagenta + "main", ColorYellow + "*", "↑1", ColorReset} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatGitWithColors() does not contain %q, got %q", want, got)
+		}
+	}
+
+	if got := formatGitWithColors(nil); got != "" {
+		t.Errorf("formatGitWithColors(nil) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,115 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SchemaVersion is bumped whenever a field of Document is renamed, removed or
+// changes meaning; adding fields keeps the version
+const SchemaVersion = 1
+
+// error codes reported in Document.Error
+const (
+	ErrorInvalidInput = "invalid_input"
+	ErrorMissingPath  = "missing_transcript_path"
+	ErrorParse        = "parse_error"
+	ErrorInternal     = "internal_error"
+)
+
+// Document is the --output=json representation of a status line run
+// sections that could not be computed are omitted, Error explains why
+type Document struct {
+	SchemaVersion int         `json:"schema_version"`
+	GeneratedAt   time.Time   `json:"generated_at"`
+	Session       DocSession  `json:"session"`
+	Model         *DocModel   `json:"model,omitempty"`
+	Usage         *DocUsage   `json:"usage,omitempty"`
+	Context       *DocContext `json:"context,omitempty"`
+	Cost          *DocCost    `json:"cost,omitempty"`
+	Totals        *DocTotals  `json:"totals,omitempty"`
+	Timing        *DocTiming  `json:"timing,omitempty"`
+	Error         *DocError   `json:"error,omitempty"`
+}
+
+// DocSession identifies the session the document describes
+type DocSession struct {
+	ID             string `json:"id,omitempty"`
+	TranscriptPath string `json:"transcript_path,omitempty"`
+	Cwd            string `json:"cwd,omitempty"`
+	ProjectDir     string `json:"project_dir,omitempty"`
+}
+
+// DocModel is the model from the payload and the context limit applied to it
+type DocModel struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name,omitempty"`
+	// ContextLimit is the context window in tokens
+	ContextLimit int64 `json:"context_limit"`
+	// KnownLimit is false when ContextLimit is the default for unknown models
+	KnownLimit bool `json:"known_limit"`
+}
+
+// DocUsage is the usage entry the context is computed from
+type DocUsage struct {
+	InputTokens              int64 `json:"input_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+}
+
+// DocContext is the computed context window usage
+type DocContext struct {
+	Tokens     int64   `json:"tokens"`
+	MaxTokens  int64   `json:"max_tokens"`
+	Percentage float64 `json:"percentage"`
+	// Level is green, yellow or red
+	Level string `json:"level"`
+	// APIErrors and Interruptions count failed and cancelled requests of the session
+	APIErrors     int `json:"api_errors"`
+	Interruptions int `json:"interruptions"`
+}
+
+// DocCost is the estimated cost in USD
+type DocCost struct {
+	LastCall float64 `json:"last_call"`
+	Session  float64 `json:"session"`
+}
+
+// DocTotals aggregates every deduplicated API call of the session
+type DocTotals struct {
+	Calls                    int   `json:"calls"`
+	InputTokens              int64 `json:"input_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+	Compactions              int   `json:"compactions"`
+}
+
+// DocTiming is the session clock, durations are in seconds
+type DocTiming struct {
+	FirstEntry               *time.Time `json:"first_entry,omitempty"`
+	LastEntry                *time.Time `json:"last_entry,omitempty"`
+	LastResponse             *time.Time `json:"last_response,omitempty"`
+	WallSeconds              float64    `json:"wall_seconds"`
+	ActiveSeconds            float64    `json:"active_seconds"`
+	SinceLastResponseSeconds *float64   `json:"since_last_response_seconds,omitempty"`
+}
+
+// DocError replaces the [ERROR: ...] text of the plain output
+type DocError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// FormatJSON renders the document as a single line of JSON
+func FormatJSON(doc *Document) string {
+	doc.SchemaVersion = SchemaVersion
+	data, err := json.Marshal(doc)
+	if err != nil {
+		// every field is a plain value, this cannot happen; keep the output valid anyway
+		return fmt.Sprintf(`{"schema_version":%d,"error":{"code":%q,"message":"failed to encode document"}}`, SchemaVersion, ErrorInternal)
+	}
+	return string(data)
+}
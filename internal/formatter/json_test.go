@@ -0,0 +1,57 @@
+package formatter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		doc         *Document
+		wantContain []string
+		wantAbsent  []string
+	}{
+		{
+			name: "error document omits computed sections",
+			doc: &Document{
+				GeneratedAt: time.Date(2025, 10, 1, 10, 0, 0, 0, time.UTC),
+				Error:       &DocError{Code: ErrorParse, Message: "boom"},
+			},
+			wantContain: []string{`"schema_version":1`, `"generated_at":"2025-10-01T10:00:00Z"`, `"error":{"code":"parse_error","message":"boom"}`},
+			wantAbsent:  []string{`"context"`, `"usage"`, `"model"`},
+		},
+		{
+			name: "zero values are kept in present sections",
+			doc: &Document{
+				Context: &DocContext{MaxTokens: 200000, Level: "green"},
+			},
+			wantContain: []string{`"context":{"tokens":0,"max_tokens":200000,"percentage":0,"level":"green","api_errors":0,"interruptions":0}`},
+			wantAbsent:  []string{`"error"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatJSON(tt.doc)
+			if !json.Valid([]byte(got)) {
+				t.Fatalf("FormatJSON() is not valid JSON: %s", got)
+			}
+			if strings.Contains(got, "\n") {
+				t.Errorf("FormatJSON() spans lines: %q", got)
+			}
+			for _, want := range tt.wantContain {
+				if !strings.Contains(got, want) {
+					t.Errorf("FormatJSON() does not contain %s, got %s", want, got)
+				}
+			}
+			for _, absent := range tt.wantAbsent {
+				if strings.Contains(got, absent) {
+					t.Errorf("FormatJSON() contains %s, got %s", absent, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package formatter
+
+import (
+	"ccstatus/internal/calculator"
+	"fmt"
+	"time"
+)
+
+// ColorDim is used for secondary details
+const ColorDim = "\033[2m"
+
+// FormatSession renders the session clock segment, returns empty string when no timestamps were seen
+// automatically detects TTY and falls back to plain output
+func FormatSession(times calculator.SessionTimes) string {
+	if !useColors() {
+		return FormatSessionPlain(times)
+	}
+	return formatSessionWithColors(times)
+}
+
+// format: ⏱ 1h12m (45m active) 3m ago
+func FormatSessionPlain(times calculator.SessionTimes) string {
+	if times == (calculator.SessionTimes{}) {
+		return ""
+	}
+	return "⏱ " + FormatDuration(times.Wall) + sessionDetails(times)
+}
+
+func formatSessionWithColors(times calculator.SessionTimes) string {
+	if times == (calculator.SessionTimes{}) {
+		return ""
+	}
+	details := sessionDetails(times)
+	if details == "" {
+		return "⏱ " + FormatDuration(times.Wall)
+	}
+	return "⏱ " + FormatDuration(times.Wall) + ColorDim + details + ColorReset
+}
+
+// sessionDetails lists active time when idle gaps were dropped and the age of the last response
+func sessionDetails(times calculator.SessionTimes) string {
+	var details string
+	if times.Wall-times.Active >= time.Minute {
+		details += fmt.Sprintf(" (%s active)", FormatDuration(times.Active))
+	}
+	if times.HasResponse && times.SinceLastResponse >= time.Minute {
+		details += fmt.Sprintf(" %s ago", FormatDuration(times.SinceLastResponse))
+	}
+	return details
+}
+
+// FormatDuration renders a duration compactly: 45s, 12m, 1h12m, 2d3h
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		hours := int(d / time.Hour)
+		minutes := int((d % time.Hour) / time.Minute)
+		if minutes == 0 {
+			return fmt.Sprintf("%dh", hours)
+		}
+		return fmt.Sprintf("%dh%02dm", hours, minutes)
+	default:
+		days := int(d / (24 * time.Hour))
+		hours := int((d % (24 * time.Hour)) / time.Hour)
+		if hours == 0 {
+			return fmt.Sprintf("%dd", days)
+		}
+		return fmt.Sprintf("%dd%dh", days, hours)
+	}
+}
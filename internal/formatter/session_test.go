@@ -0,0 +1,83 @@
+package formatter
+
+import (
+	"ccstatus/internal/calculator"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: -time.Second, want: "0s"},
+		{d: 0, want: "0s"},
+		{d: 45 * time.Second, want: "45s"},
+		{d: 12*time.Minute + 30*time.Second, want: "12m"},
+		{d: time.Hour, want: "1h"},
+		{d: time.Hour + 2*time.Minute, want: "1h02m"},
+		{d: time.Hour + 12*time.Minute, want: "1h12m"},
+		{d: 48 * time.Hour, want: "2d"},
+		{d: 51*time.Hour + 20*time.Minute, want: "2d3h"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSessionPlain(t *testing.T) {
+	tests := []struct {
+		name  string
+		times calculator.SessionTimes
+		want  string
+	}{
+		{
+			name:  "no timestamps",
+			times: calculator.SessionTimes{},
+			want:  "",
+		},
+		{
+			name: "fully active fresh response",
+			times: calculator.SessionTimes{
+				Wall:              72 * time.Minute,
+				Active:            72 * time.Minute,
+				SinceLastResponse: 10 * time.Second,
+				HasResponse:       true,
+			},
+			want: "⏱ 1h12m",
+		},
+		{
+			name: "idle time and stale response",
+			times: calculator.SessionTimes{
+				Wall:              72 * time.Minute,
+				Active:            45 * time.Minute,
+				SinceLastResponse: 3 * time.Minute,
+				HasResponse:       true,
+			},
+			want: "⏱ 1h12m (45m active) 3m ago",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatSessionPlain(tt.times); got != tt.want {
+				t.Errorf("FormatSessionPlain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSessionWithColors(t *testing.T) {
+	got := formatSessionWithColors(calculator.SessionTimes{
+		Wall:   72 * time.Minute,
+		Active: 45 * time.Minute,
+	})
+	if !strings.Contains(got, "⏱ 1h12m") || !strings.Contains(got, ColorDim+" (45m active)"+ColorReset) {
+		t.Errorf("formatSessionWithColors() = %q", got)
+	}
+}
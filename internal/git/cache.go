@@ -0,0 +1,61 @@
+package git
+
+import (
+	"ccstatus/internal/state"
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheTTL is how long a cached status is trusted when HEAD and the index are unchanged
+const DefaultCacheTTL = 5 * time.Second
+
+// cachedStatus is the on-disk cache record, keyed by repository root
+type cachedStatus struct {
+	Status     Status `json:"status"`
+	HeadMtime  int64  `json:"head_mtime"`
+	IndexMtime int64  `json:"index_mtime"`
+}
+
+// CachedLookup is like Lookup but reuses a recent result kept in store
+// a cached entry is discarded as soon as HEAD or the index is rewritten
+func CachedLookup(ctx context.Context, dir string, store *state.Store, ttl time.Duration) (*Status, error) {
+	repo, err := FindRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	key := "git-" + repo.Root
+	headMtime := mtimeNanos(filepath.Join(repo.GitDir, "HEAD"))
+	indexMtime := mtimeNanos(filepath.Join(repo.GitDir, "index"))
+
+	var cached cachedStatus
+	if store.LoadFresh(key, ttl, &cached) &&
+		cached.HeadMtime == headMtime &&
+		cached.IndexMtime == indexMtime &&
+		cached.Status.Root == repo.Root {
+		return &cached.Status, nil
+	}
+
+	status, err := repo.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// a failed cache write only costs speed on the next run
+	_ = store.Save(key, cachedStatus{
+		Status:     *status,
+		HeadMtime:  headMtime,
+		IndexMtime: indexMtime,
+	})
+	return status, nil
+}
+
+func mtimeNanos(path string) int64 {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return fi.ModTime().UnixNano()
+}
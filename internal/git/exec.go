@@ -0,0 +1,65 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommandTimeout bounds every git subprocess so a slow repository cannot stall the status line
+var CommandTimeout = 300 * time.Millisecond
+
+// runGit runs git in dir with a hard timeout and returns its stdout
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, CommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir, "--no-optional-locks"}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0", "LC_ALL=C")
+	cmd.WaitDelay = 50 * time.Millisecond
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("git %s: %w", args[0], ctx.Err())
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// aheadBehind counts commits HEAD has that upstream lacks and vice versa
+func aheadBehind(ctx context.Context, root, upstream string) (int, int, error) {
+	out, err := runGit(ctx, root, "rev-list", "--left-right", "--count", "HEAD..."+upstream)
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", out)
+	}
+	ahead, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", out)
+	}
+	behind, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", out)
+	}
+	return ahead, behind, nil
+}
+
+// statusDirty asks git whether tracked files have staged or unstaged changes
+func statusDirty(ctx context.Context, root string) (bool, error) {
+	out, err := runGit(ctx, root, "status", "--porcelain", "--untracked-files=no", "--ignore-submodules=dirty")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "", nil
+}
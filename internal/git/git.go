@@ -0,0 +1,290 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotRepository is returned when no repository contains the given directory
+var ErrNotRepository = errors.New("not a git repository")
+
+// Status describes the state of the repository containing a directory
+type Status struct {
+	Root     string `json:"root"`
+	Branch   string `json:"branch,omitempty"`
+	Head     string `json:"head,omitempty"`
+	Detached bool   `json:"detached,omitempty"`
+	Upstream string `json:"upstream,omitempty"`
+	Ahead    int    `json:"ahead,omitempty"`
+	Behind   int    `json:"behind,omitempty"`
+	Dirty    bool   `json:"dirty,omitempty"`
+}
+
+// ShortHead returns the abbreviated commit hash of HEAD
+func (s *Status) ShortHead() string {
+	if len(s.Head) > 7 {
+		return s.Head[:7]
+	}
+	return s.Head
+}
+
+// Repo points at the working tree and git directories of a repository
+type Repo struct {
+	Root      string // working tree root
+	GitDir    string // per-worktree git dir (HEAD, index)
+	CommonDir string // shared git dir (refs, objects, config)
+}
+
+// FindRepo walks up from dir until it finds a .git directory or gitdir file
+func FindRepo(dir string) (*Repo, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	for current := absDir; ; {
+		candidate := filepath.Join(current, ".git")
+		if fi, err := os.Stat(candidate); err == nil {
+			gitDir := candidate
+			if !fi.IsDir() {
+				// worktrees and submodules use a file pointing at the real git dir
+				gitDir, err = readGitDirFile(candidate)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return &Repo{
+				Root:      current,
+				GitDir:    gitDir,
+				CommonDir: readCommonDir(gitDir),
+			}, nil
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return nil, ErrNotRepository
+		}
+		current = parent
+	}
+}
+
+// Lookup reports the status of the repository containing dir
+// HEAD, branch, upstream and dirty state are read from .git files where possible;
+// the git binary is only used when the files are not conclusive
+func Lookup(ctx context.Context, dir string) (*Status, error) {
+	repo, err := FindRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Status(ctx)
+}
+
+// Status collects the repository status
+func (r *Repo) Status(ctx context.Context) (*Status, error) {
+	status := &Status{Root: r.Root}
+
+	ref, head, err := r.readHead()
+	if err != nil {
+		return nil, err
+	}
+	status.Head = head
+	if ref == "" {
+		status.Detached = true
+	} else {
+		status.Branch = strings.TrimPrefix(ref, "refs/heads/")
+	}
+
+	if status.Branch != "" && status.Head != "" {
+		if upstream := r.upstreamRef(status.Branch); upstream != "" {
+			status.Upstream = strings.TrimPrefix(upstream, "refs/remotes/")
+			upstreamHash, _ := r.resolveRef(upstream)
+			if upstreamHash != "" && upstreamHash != status.Head {
+				// counting commits requires walking history, leave that to git
+				status.Ahead, status.Behind, _ = aheadBehind(ctx, r.Root, upstream)
+			}
+		}
+	}
+
+	dirty, err := r.isDirty(ctx, status.Head)
+	if err != nil {
+		return nil, err
+	}
+	status.Dirty = dirty
+
+	return status, nil
+}
+
+// readHead returns the symbolic ref HEAD points to (empty when detached) and the commit hash
+// an unborn branch yields a ref with an empty hash
+func (r *Repo) readHead() (string, string, error) {
+	data, err := os.ReadFile(filepath.Join(r.GitDir, "HEAD"))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	content := strings.TrimSpace(string(data))
+
+	if ref, ok := strings.CutPrefix(content, "ref: "); ok {
+		hash, err := r.resolveRef(ref)
+		if err != nil {
+			return "", "", err
+		}
+		return ref, hash, nil
+	}
+
+	if !isHash(content) {
+		return "", "", fmt.Errorf("malformed HEAD: %q", content)
+	}
+	return "", content, nil
+}
+
+// resolveRef follows a ref through loose ref files and packed-refs
+// a missing ref is not an error and yields an empty hash
+func (r *Repo) resolveRef(ref string) (string, error) {
+	for range 10 {
+		data, err := r.readLooseRef(ref)
+		if err != nil {
+			return r.readPackedRef(ref)
+		}
+		content := strings.TrimSpace(string(data))
+		next, ok := strings.CutPrefix(content, "ref: ")
+		if !ok {
+			if !isHash(content) {
+				return "", fmt.Errorf("malformed ref %s: %q", ref, content)
+			}
+			return content, nil
+		}
+		ref = next
+	}
+	return "", fmt.Errorf("ref %s: too many levels of symbolic refs", ref)
+}
+
+func (r *Repo) readLooseRef(ref string) ([]byte, error) {
+	// per-worktree refs live in the git dir, everything else in the common dir
+	if data, err := os.ReadFile(filepath.Join(r.GitDir, filepath.FromSlash(ref))); err == nil {
+		return data, nil
+	}
+	return os.ReadFile(filepath.Join(r.CommonDir, filepath.FromSlash(ref)))
+}
+
+func (r *Repo) readPackedRef(ref string) (string, error) {
+	file, err := os.Open(filepath.Join(r.CommonDir, "packed-refs"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read packed-refs: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		hash, name, ok := strings.Cut(line, " ")
+		if ok && name == ref {
+			return hash, nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// upstreamRef returns the remote-tracking ref configured for branch
+func (r *Repo) upstreamRef(branch string) string {
+	section := fmt.Sprintf(`branch "%s"`, branch)
+	remote := r.configValue(section, "remote")
+	merge := r.configValue(section, "merge")
+	if remote == "" || merge == "" {
+		return ""
+	}
+	if remote == "." {
+		return merge
+	}
+	return "refs/remotes/" + remote + "/" + strings.TrimPrefix(merge, "refs/heads/")
+}
+
+// configValue reads a single value from the repository config
+// only the subset of the config syntax git writes itself is supported
+func (r *Repo) configValue(section, key string) string {
+	file, err := os.Open(filepath.Join(r.CommonDir, "config"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	var current, value string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = normalizeSection(line[1 : len(line)-1])
+			continue
+		}
+		if current != normalizeSection(section) {
+			continue
+		}
+		name, val, _ := strings.Cut(line, "=")
+		if strings.EqualFold(strings.TrimSpace(name), key) {
+			// last value wins, matching git's behavior for single-valued keys
+			value = strings.Trim(strings.TrimSpace(val), `"`)
+		}
+	}
+	return value
+}
+
+// normalizeSection lowercases the section name but keeps the case-sensitive subsection
+func normalizeSection(section string) string {
+	name, sub, ok := strings.Cut(strings.TrimSpace(section), " ")
+	if !ok {
+		return strings.ToLower(name)
+	}
+	return strings.ToLower(name) + " " + strings.TrimSpace(sub)
+}
+
+func readGitDirFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("malformed gitdir file %s", path)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	return filepath.Clean(gitDir), nil
+}
+
+func readCommonDir(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	commonDir := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+	return filepath.Clean(commonDir)
+}
+
+func isHash(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
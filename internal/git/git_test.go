@@ -0,0 +1,472 @@
+package git
+
+import (
+	"ccstatus/internal/state"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// past is used for file mtimes so the index never looks racily clean in tests
+var past = time.Now().Add(-time.Hour)
+
+func requireGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+}
+
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, past, past); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newRepo creates a repository with a single commit on main
+func newRepo(t *testing.T) string {
+	t.Helper()
+	requireGit(t)
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q", "-b", "main")
+	writeFile(t, filepath.Join(dir, "README.md"), "hello\n")
+	writeFile(t, filepath.Join(dir, "sub", "file.txt"), "content\n")
+	gitCmd(t, dir, "add", ".")
+	gitCmd(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func TestFindRepo(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := FindRepo(nested)
+	if err != nil {
+		t.Fatalf("FindRepo() error = %v", err)
+	}
+	if repo.Root != dir {
+		t.Errorf("FindRepo().Root = %q, want %q", repo.Root, dir)
+	}
+	if repo.GitDir != filepath.Join(dir, ".git") || repo.CommonDir != repo.GitDir {
+		t.Errorf("FindRepo() dirs = %q, %q", repo.GitDir, repo.CommonDir)
+	}
+}
+
+func TestFindRepoGitDirFile(t *testing.T) {
+	dir := t.TempDir()
+	realGitDir := filepath.Join(dir, "main", ".git", "worktrees", "wt")
+	if err := os.MkdirAll(realGitDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(realGitDir, "commondir"), "../..\n")
+	worktree := filepath.Join(dir, "wt")
+	writeFile(t, filepath.Join(worktree, ".git"), "gitdir: "+realGitDir+"\n")
+
+	repo, err := FindRepo(worktree)
+	if err != nil {
+		t.Fatalf("FindRepo() error = %v", err)
+	}
+	if repo.GitDir != realGitDir {
+		t.Errorf("FindRepo().GitDir = %q, want %q", repo.GitDir, realGitDir)
+	}
+	if want := filepath.Join(dir, "main", ".git"); repo.CommonDir != want {
+		t.Errorf("FindRepo().CommonDir = %q, want %q", repo.CommonDir, want)
+	}
+}
+
+func TestFindRepoNotRepository(t *testing.T) {
+	// a directory tree without .git anywhere up to the filesystem root is
+	// not guaranteed in every sandbox, so only check when the temp dir is clean
+	dir := t.TempDir()
+	if _, err := FindRepo(filepath.Dir(dir)); err == nil {
+		t.Skip("temp dir is inside a git repository")
+	}
+	if _, err := FindRepo(dir); err != ErrNotRepository {
+		t.Errorf("FindRepo() error = %v, want %v", err, ErrNotRepository)
+	}
+}
+
+func TestReadHeadFromFiles(t *testing.T) {
+	const hash = "0123456789abcdef0123456789abcdef01234567"
+	const packedHash = "89abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		wantRef  string
+		wantHash string
+		wantErr  bool
+	}{
+		{
+			name: "loose branch ref",
+			files: map[string]string{
+				"HEAD":               "ref: refs/heads/feature\n",
+				"refs/heads/feature": hash + "\n",
+			},
+			wantRef:  "refs/heads/feature",
+			wantHash: hash,
+		},
+		{
+			name: "packed branch ref",
+			files: map[string]string{
+				"HEAD":        "ref: refs/heads/main\n",
+				"packed-refs": "# pack-refs with: peeled fully-peeled sorted\n" + packedHash + " refs/heads/main\n",
+			},
+			wantRef:  "refs/heads/main",
+			wantHash: packedHash,
+		},
+		{
+			name: "unborn branch",
+			files: map[string]string{
+				"HEAD": "ref: refs/heads/main\n",
+			},
+			wantRef:  "refs/heads/main",
+			wantHash: "",
+		},
+		{
+			name: "detached head",
+			files: map[string]string{
+				"HEAD": hash + "\n",
+			},
+			wantRef:  "",
+			wantHash: hash,
+		},
+		{
+			name: "malformed head",
+			files: map[string]string{
+				"HEAD": "garbage\n",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitDir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, filepath.Join(gitDir, filepath.FromSlash(name)), content)
+			}
+			repo := &Repo{Root: filepath.Dir(gitDir), GitDir: gitDir, CommonDir: gitDir}
+
+			ref, hash, err := repo.readHead()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readHead() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ref != tt.wantRef || hash != tt.wantHash {
+				t.Errorf("readHead() = %q, %q, want %q, %q", ref, hash, tt.wantRef, tt.wantHash)
+			}
+		})
+	}
+}
+
+func TestUpstreamRef(t *testing.T) {
+	gitDir := t.TempDir()
+	writeFile(t, filepath.Join(gitDir, "config"), `[core]
+	bare = false
+[Branch "main"]
+	remote = origin
+	merge = refs/heads/main
+[branch "local"]
+	remote = .
+	merge = refs/heads/main
+`)
+	repo := &Repo{GitDir: gitDir, CommonDir: gitDir}
+
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{branch: "main", want: "refs/remotes/origin/main"},
+		{branch: "local", want: "refs/heads/main"},
+		{branch: "missing", want: ""},
+	}
+	for _, tt := range tests {
+		if got := repo.upstreamRef(tt.branch); got != tt.want {
+			t.Errorf("upstreamRef(%q) = %q, want %q", tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestLookupCleanRepository(t *testing.T) {
+	dir := newRepo(t)
+
+	repo, err := FindRepo(filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatalf("FindRepo() error = %v", err)
+	}
+	// a clean repository must be recognized from .git files alone
+	if got := repo.checkIndex(gitCmd(t, dir, "rev-parse", "HEAD")); got != stateClean {
+		t.Errorf("checkIndex() = %v, want stateClean", got)
+	}
+
+	status, err := Lookup(context.Background(), filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if status.Root != dir {
+		t.Errorf("Root = %q, want %q", status.Root, dir)
+	}
+	if status.Branch != "main" || status.Detached {
+		t.Errorf("Branch = %q, Detached = %v, want main, false", status.Branch, status.Detached)
+	}
+	if want := gitCmd(t, dir, "rev-parse", "HEAD"); status.Head != want {
+		t.Errorf("Head = %q, want %q", status.Head, want)
+	}
+	if status.Dirty {
+		t.Error("Dirty = true for clean repository")
+	}
+}
+
+func TestLookupPackedObjects(t *testing.T) {
+	dir := newRepo(t)
+	gitCmd(t, dir, "gc", "-q")
+
+	repo, err := FindRepo(dir)
+	if err != nil {
+		t.Fatalf("FindRepo() error = %v", err)
+	}
+	head := gitCmd(t, dir, "rev-parse", "HEAD")
+	tree, err := repo.commitTree(head)
+	if err != nil {
+		t.Fatalf("commitTree() error = %v", err)
+	}
+	if want := gitCmd(t, dir, "rev-parse", "HEAD^{tree}"); tree != want {
+		t.Errorf("commitTree() = %q, want %q", tree, want)
+	}
+}
+
+func TestLookupDirty(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, dir string)
+		want   bool
+	}{
+		{
+			name: "modified tracked file",
+			modify: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("changed\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: true,
+		},
+		{
+			name: "deleted tracked file",
+			modify: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, "sub", "file.txt")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: true,
+		},
+		{
+			name: "staged new file",
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "new.txt"), "new\n")
+				gitCmd(t, dir, "add", "new.txt")
+			},
+			want: true,
+		},
+		{
+			name: "touched but unchanged file",
+			modify: func(t *testing.T, dir string) {
+				now := time.Now()
+				if err := os.Chtimes(filepath.Join(dir, "README.md"), now, now); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: false,
+		},
+		{
+			name: "untracked file is ignored",
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "untracked.txt"), "x\n")
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t)
+			tt.modify(t, dir)
+
+			status, err := Lookup(context.Background(), dir)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			if status.Dirty != tt.want {
+				t.Errorf("Dirty = %v, want %v", status.Dirty, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupDetached(t *testing.T) {
+	dir := newRepo(t)
+	head := gitCmd(t, dir, "rev-parse", "HEAD")
+	gitCmd(t, dir, "checkout", "-q", "--detach")
+
+	status, err := Lookup(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if !status.Detached || status.Branch != "" {
+		t.Errorf("Detached = %v, Branch = %q, want true, empty", status.Detached, status.Branch)
+	}
+	if status.ShortHead() != head[:7] {
+		t.Errorf("ShortHead() = %q, want %q", status.ShortHead(), head[:7])
+	}
+}
+
+func TestLookupAheadBehind(t *testing.T) {
+	upstream := newRepo(t)
+	clone := filepath.Join(t.TempDir(), "clone")
+	gitCmd(t, upstream, "clone", "-q", upstream, clone)
+
+	// one commit upstream, two commits locally
+	writeFile(t, filepath.Join(upstream, "upstream.txt"), "u\n")
+	gitCmd(t, upstream, "add", ".")
+	gitCmd(t, upstream, "commit", "-q", "-m", "upstream")
+	gitCmd(t, clone, "fetch", "-q")
+	for _, name := range []string{"a.txt", "b.txt"} {
+		writeFile(t, filepath.Join(clone, name), name)
+		gitCmd(t, clone, "add", ".")
+		gitCmd(t, clone, "commit", "-q", "-m", name)
+	}
+
+	status, err := Lookup(context.Background(), clone)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if status.Upstream != "origin/main" {
+		t.Errorf("Upstream = %q, want origin/main", status.Upstream)
+	}
+	if status.Ahead != 2 || status.Behind != 1 {
+		t.Errorf("Ahead/Behind = %d/%d, want 2/1", status.Ahead, status.Behind)
+	}
+}
+
+func TestLookupWorktree(t *testing.T) {
+	dir := newRepo(t)
+	worktree := filepath.Join(t.TempDir(), "wt")
+	gitCmd(t, dir, "worktree", "add", "-q", "-b", "feature", worktree)
+
+	status, err := Lookup(context.Background(), worktree)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if status.Branch != "feature" {
+		t.Errorf("Branch = %q, want feature", status.Branch)
+	}
+	if status.Head != gitCmd(t, dir, "rev-parse", "HEAD") {
+		t.Errorf("Head = %q, want main HEAD", status.Head)
+	}
+}
+
+func TestCachedLookup(t *testing.T) {
+	dir := newRepo(t)
+	store := state.New(t.TempDir())
+	ctx := context.Background()
+
+	first, err := CachedLookup(ctx, dir, store, time.Minute)
+	if err != nil {
+		t.Fatalf("CachedLookup() error = %v", err)
+	}
+	if first.Dirty {
+		t.Fatal("Dirty = true for clean repository")
+	}
+
+	// worktree edits do not touch HEAD or the index, so the cached value is reused
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := CachedLookup(ctx, dir, store, time.Minute)
+	if err != nil {
+		t.Fatalf("CachedLookup() error = %v", err)
+	}
+	if cached.Dirty {
+		t.Error("CachedLookup() did not reuse cached status")
+	}
+
+	// switching branches rewrites HEAD and invalidates the cache
+	gitCmd(t, dir, "checkout", "-q", "-b", "other")
+	fresh, err := CachedLookup(ctx, dir, store, time.Minute)
+	if err != nil {
+		t.Fatalf("CachedLookup() error = %v", err)
+	}
+	if fresh.Branch != "other" || !fresh.Dirty {
+		t.Errorf("CachedLookup() = %+v, want fresh status on branch other", fresh)
+	}
+}
+
+func TestParseCacheTreeRoot(t *testing.T) {
+	hash := strings.Repeat("\xab", 20)
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "valid root",
+			data: "\x003 1\n" + hash,
+			want: strings.Repeat("ab", 20),
+		},
+		{
+			name: "invalidated root",
+			data: "\x00-1 1\n",
+			want: "",
+		},
+		{
+			name: "truncated hash",
+			data: "\x003 1\n\xab",
+			want: "",
+		},
+		{
+			name: "empty",
+			data: "",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseCacheTreeRoot([]byte(tt.data), 20); got != tt.want {
+				t.Errorf("parseCacheTreeRoot() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
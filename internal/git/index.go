@@ -0,0 +1,293 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// indexState is the verdict of the index-based dirty check
+type indexState int
+
+const (
+	stateUnknown indexState = iota
+	stateClean
+	stateDirty
+)
+
+const (
+	flagExtended     = 0x4000
+	flagSkipWorktree = 0x4000
+	flagIntentToAdd  = 0x2000
+	modeTypeMask     = 0o170000
+	modeGitlink      = 0o160000
+	modeSymlink      = 0o120000
+	maxIndexNameLen  = 0x0fff
+)
+
+var errUnsupportedIndex = errors.New("unsupported index format")
+
+// indexEntry holds the fields of an index entry used for stat comparison
+type indexEntry struct {
+	mtimeSec  uint32
+	mtimeNsec uint32
+	mode      uint32
+	size      uint32
+	stage     int
+	flags2    uint16
+	path      string
+}
+
+// index is the parsed subset of .git/index needed for the dirty check
+type index struct {
+	entries []indexEntry
+	// tree is the root cache-tree hash, empty when the cache-tree is missing or invalidated
+	tree string
+}
+
+// isDirty reports whether tracked files differ from HEAD
+// the index is compared against the worktree by stat data and against HEAD through
+// the cache-tree extension; git is consulted only when that is inconclusive
+func (r *Repo) isDirty(ctx context.Context, head string) (bool, error) {
+	verdict := r.checkIndex(head)
+	switch verdict {
+	case stateClean:
+		return false, nil
+	case stateDirty:
+		return true, nil
+	}
+
+	dirty, err := statusDirty(ctx, r.Root)
+	if err != nil {
+		// stat data already disagrees with the index, so dirty is the safer guess
+		return true, nil
+	}
+	return dirty, nil
+}
+
+// checkIndex compares the index with the worktree and HEAD without running git
+func (r *Repo) checkIndex(head string) indexState {
+	indexPath := filepath.Join(r.GitDir, "index")
+	fi, err := os.Stat(indexPath)
+	if err != nil {
+		if os.IsNotExist(err) && head == "" {
+			// fresh repository with nothing staged
+			return stateClean
+		}
+		return stateUnknown
+	}
+
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return stateUnknown
+	}
+	idx, err := parseIndex(data, r.hashSize())
+	if err != nil {
+		return stateUnknown
+	}
+
+	worktree := r.compareWorktree(idx, fi.ModTime().Unix(), int64(fi.ModTime().Nanosecond()))
+	if worktree == stateDirty {
+		return stateDirty
+	}
+
+	staged := r.compareHead(idx, head)
+	if staged == stateDirty {
+		return stateDirty
+	}
+	if worktree == stateClean && staged == stateClean {
+		return stateClean
+	}
+	return stateUnknown
+}
+
+// compareWorktree stats every tracked file and compares it to the index entry
+func (r *Repo) compareWorktree(idx *index, indexSec, indexNsec int64) indexState {
+	verdict := stateClean
+	for _, entry := range idx.entries {
+		if entry.stage != 0 || entry.flags2&flagIntentToAdd != 0 {
+			// unresolved conflict or file added with --intent-to-add
+			return stateDirty
+		}
+		if entry.flags2&flagSkipWorktree != 0 || entry.mode&modeTypeMask == modeGitlink {
+			continue
+		}
+
+		fi, err := os.Lstat(filepath.Join(r.Root, filepath.FromSlash(entry.path)))
+		if err != nil {
+			// tracked file was deleted
+			return stateDirty
+		}
+
+		if !statMatches(entry, fi) {
+			verdict = stateUnknown
+			continue
+		}
+
+		// racily clean: modified in the same timestamp tick the index was written
+		sec, nsec := int64(entry.mtimeSec), int64(entry.mtimeNsec)
+		if sec > indexSec || (sec == indexSec && nsec >= indexNsec) {
+			verdict = stateUnknown
+		}
+	}
+	return verdict
+}
+
+// compareHead compares the cache-tree root with the tree of the HEAD commit
+func (r *Repo) compareHead(idx *index, head string) indexState {
+	if head == "" {
+		if len(idx.entries) == 0 {
+			return stateClean
+		}
+		return stateDirty
+	}
+	if idx.tree == "" {
+		// cache-tree invalidated by git add, staged changes are likely but not certain
+		return stateUnknown
+	}
+
+	tree, err := r.commitTree(head)
+	if err != nil {
+		return stateUnknown
+	}
+	if tree == idx.tree {
+		return stateClean
+	}
+	return stateDirty
+}
+
+func statMatches(entry indexEntry, fi os.FileInfo) bool {
+	if uint32(fi.Size()) != entry.size {
+		return false
+	}
+
+	mtime := fi.ModTime()
+	if uint32(mtime.Unix()) != entry.mtimeSec {
+		return false
+	}
+	// git built without nanosecond support stores zero
+	if entry.mtimeNsec != 0 && uint32(mtime.Nanosecond()) != entry.mtimeNsec {
+		return false
+	}
+
+	switch entry.mode & modeTypeMask {
+	case modeSymlink:
+		return fi.Mode()&os.ModeSymlink != 0
+	default:
+		if !fi.Mode().IsRegular() {
+			return false
+		}
+		executable := fi.Mode().Perm()&0o111 != 0
+		return executable == (entry.mode&0o111 != 0)
+	}
+}
+
+// parseIndex decodes index versions 2 and 3
+// version 4 path compression is not supported and yields errUnsupportedIndex
+func parseIndex(data []byte, hashSize int) (*index, error) {
+	if len(data) < 12+hashSize || string(data[:4]) != "DIRC" {
+		return nil, errUnsupportedIndex
+	}
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version != 2 && version != 3 {
+		return nil, errUnsupportedIndex
+	}
+	count := binary.BigEndian.Uint32(data[8:12])
+	end := len(data) - hashSize
+
+	idx := &index{entries: make([]indexEntry, 0, count)}
+	offset := 12
+	for range count {
+		headerSize := 40 + hashSize + 2
+		if offset+headerSize > end {
+			return nil, errUnsupportedIndex
+		}
+		entryData := data[offset:]
+		flags := binary.BigEndian.Uint16(entryData[40+hashSize:])
+
+		entry := indexEntry{
+			mtimeSec:  binary.BigEndian.Uint32(entryData[8:12]),
+			mtimeNsec: binary.BigEndian.Uint32(entryData[12:16]),
+			mode:      binary.BigEndian.Uint32(entryData[24:28]),
+			size:      binary.BigEndian.Uint32(entryData[36:40]),
+			stage:     int(flags>>12) & 0x3,
+		}
+		if flags&flagExtended != 0 {
+			if version < 3 || offset+headerSize+2 > end {
+				return nil, errUnsupportedIndex
+			}
+			entry.flags2 = binary.BigEndian.Uint16(entryData[headerSize:])
+			headerSize += 2
+		}
+
+		nameLen := int(flags & maxIndexNameLen)
+		if nameLen == maxIndexNameLen {
+			nul := bytes.IndexByte(entryData[headerSize:], 0)
+			if nul < 0 {
+				return nil, errUnsupportedIndex
+			}
+			nameLen = nul
+		}
+		if offset+headerSize+nameLen > end {
+			return nil, errUnsupportedIndex
+		}
+		entry.path = string(entryData[headerSize : headerSize+nameLen])
+		idx.entries = append(idx.entries, entry)
+
+		// entries are NUL-padded to a multiple of eight bytes
+		offset += (headerSize + nameLen + 8) &^ 7
+	}
+
+	// extensions follow the entries: 4-byte signature, 4-byte size, payload
+	for offset+8 <= end {
+		signature := string(data[offset : offset+4])
+		size := int(binary.BigEndian.Uint32(data[offset+4 : offset+8]))
+		offset += 8
+		if offset+size > end {
+			return nil, errUnsupportedIndex
+		}
+		if signature == "TREE" {
+			idx.tree = parseCacheTreeRoot(data[offset:offset+size], hashSize)
+		}
+		offset += size
+	}
+
+	return idx, nil
+}
+
+// parseCacheTreeRoot returns the hash of the root cache-tree entry if it is valid
+func parseCacheTreeRoot(data []byte, hashSize int) string {
+	// root entry: empty path, NUL, "<entry_count> <subtrees>\n", hash
+	if len(data) == 0 || data[0] != 0 {
+		return ""
+	}
+	newline := bytes.IndexByte(data, '\n')
+	if newline < 0 {
+		return ""
+	}
+	countStr, _, ok := bytes.Cut(data[1:newline], []byte(" "))
+	if !ok {
+		return ""
+	}
+	count, err := strconv.Atoi(string(countStr))
+	if err != nil || count < 0 {
+		return ""
+	}
+	hashStart := newline + 1
+	if hashStart+hashSize > len(data) {
+		return ""
+	}
+	return fmt.Sprintf("%x", data[hashStart:hashStart+hashSize])
+}
+
+func (r *Repo) hashSize() int {
+	if r.configValue("extensions", "objectformat") == "sha256" {
+		return 32
+	}
+	return 20
+}
@@ -0,0 +1,200 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	packObjectCommit = 1
+	packIndexMagic   = "\377tOc"
+	maxCommitHeader  = 4096
+)
+
+var errObjectNotFound = errors.New("object not found")
+
+// commitTree returns the tree hash recorded in a commit object
+// loose objects and undeltified packed objects are supported, anything else is an error
+func (r *Repo) commitTree(hash string) (string, error) {
+	header, err := r.readLooseCommit(hash)
+	if errors.Is(err, errObjectNotFound) {
+		header, err = r.readPackedCommit(hash)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	tree, ok := bytes.CutPrefix(header, []byte("tree "))
+	if !ok {
+		return "", fmt.Errorf("commit %s: missing tree", hash)
+	}
+	if newline := bytes.IndexByte(tree, '\n'); newline >= 0 {
+		tree = tree[:newline]
+	}
+	if !isHash(string(tree)) {
+		return "", fmt.Errorf("commit %s: malformed tree", hash)
+	}
+	return string(tree), nil
+}
+
+// readLooseCommit returns the start of a loose commit object body
+func (r *Repo) readLooseCommit(hash string) ([]byte, error) {
+	path := filepath.Join(r.CommonDir, "objects", hash[:2], hash[2:])
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errObjectNotFound
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	zr, err := zlib.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("object %s: %w", hash, err)
+	}
+	defer zr.Close()
+
+	data, err := readUpTo(zr, maxCommitHeader)
+	if err != nil {
+		return nil, fmt.Errorf("object %s: %w", hash, err)
+	}
+
+	// loose objects start with "<type> <size>\x00"
+	objectHeader, body, ok := bytes.Cut(data, []byte{0})
+	if !ok {
+		return nil, fmt.Errorf("object %s: malformed header", hash)
+	}
+	if !bytes.HasPrefix(objectHeader, []byte("commit ")) {
+		return nil, fmt.Errorf("object %s: not a commit", hash)
+	}
+	return body, nil
+}
+
+// readPackedCommit locates hash in the pack indexes and inflates the object
+func (r *Repo) readPackedCommit(hash string) ([]byte, error) {
+	raw, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hash %s: %w", hash, err)
+	}
+
+	indexes, err := filepath.Glob(filepath.Join(r.CommonDir, "objects", "pack", "pack-*.idx"))
+	if err != nil {
+		return nil, err
+	}
+	for _, idxPath := range indexes {
+		offset, err := findInPackIndex(idxPath, raw)
+		if errors.Is(err, errObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return readPackCommit(strings.TrimSuffix(idxPath, ".idx")+".pack", offset)
+	}
+	return nil, errObjectNotFound
+}
+
+// findInPackIndex looks up an object offset in a version 2 pack index
+func findInPackIndex(path string, hash []byte) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	hashSize := len(hash)
+	if len(data) < 8+256*4 || string(data[:4]) != packIndexMagic || binary.BigEndian.Uint32(data[4:8]) != 2 {
+		return 0, fmt.Errorf("unsupported pack index %s", path)
+	}
+
+	fanout := data[8 : 8+256*4]
+	count := int(binary.BigEndian.Uint32(fanout[255*4:]))
+	lo := 0
+	if hash[0] > 0 {
+		lo = int(binary.BigEndian.Uint32(fanout[(int(hash[0])-1)*4:]))
+	}
+	hi := int(binary.BigEndian.Uint32(fanout[int(hash[0])*4:]))
+
+	hashesStart := 8 + 256*4
+	crcStart := hashesStart + count*hashSize
+	offsetsStart := crcStart + count*4
+	largeStart := offsetsStart + count*4
+	if len(data) < largeStart {
+		return 0, fmt.Errorf("truncated pack index %s", path)
+	}
+
+	hashAt := func(i int) []byte {
+		return data[hashesStart+i*hashSize : hashesStart+(i+1)*hashSize]
+	}
+	pos := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(hashAt(lo+i), hash) >= 0
+	})
+	if pos >= hi || !bytes.Equal(hashAt(pos), hash) {
+		return 0, errObjectNotFound
+	}
+
+	offset := binary.BigEndian.Uint32(data[offsetsStart+pos*4:])
+	if offset&0x80000000 == 0 {
+		return int64(offset), nil
+	}
+	// large offsets live in a separate 8-byte table
+	large := largeStart + int(offset&0x7fffffff)*8
+	if large+8 > len(data) {
+		return 0, fmt.Errorf("truncated pack index %s", path)
+	}
+	return int64(binary.BigEndian.Uint64(data[large:])), nil
+}
+
+// readPackCommit inflates an undeltified commit stored at offset in a pack file
+func readPackCommit(path string, offset int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(file)
+
+	// object header: type in bits 4-6 of the first byte, size as a varint
+	b, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	objectType := (b >> 4) & 0x7
+	for b&0x80 != 0 {
+		if b, err = br.ReadByte(); err != nil {
+			return nil, err
+		}
+	}
+	if objectType != packObjectCommit {
+		return nil, fmt.Errorf("packed object at %d is not a plain commit (type %d)", offset, objectType)
+	}
+
+	zr, err := zlib.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return readUpTo(zr, maxCommitHeader)
+}
+
+// readUpTo reads at most limit bytes, a shorter stream is not an error
+func readUpTo(r io.Reader, limit int) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(limit)))
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,229 @@
+package history
+
+import (
+	"bytes"
+	"ccstatus/internal/calculator"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/state"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// buckets of the history database
+var (
+	bucketMeta   = []byte("meta")
+	bucketTurns  = []byte("turns")
+	bucketByTime = []byte("turns_by_time")
+	keyVersion   = []byte("schema_version")
+)
+
+// ErrLocked is returned when another process holds the database longer than the open timeout
+var ErrLocked = errors.New("history database is locked by another process")
+
+// Record is one API call observed in a transcript
+type Record struct {
+	// Key deduplicates the call, see parser.Entry.DedupKey
+	Key       string       `json:"key"`
+	SessionID string       `json:"session_id"`
+	Project   string       `json:"project"`
+	Model     string       `json:"model"`
+	Timestamp time.Time    `json:"timestamp"`
+	Usage     parser.Usage `json:"usage"`
+	// Cost is priced when the record is stored
+	Cost float64 `json:"cost"`
+}
+
+// NewRecord converts a usage entry, cost is computed with the current prices
+func NewRecord(entry parser.Entry) Record {
+	key := entry.DedupKey()
+	if key == "" {
+		// entries without ids are unique by session and time
+		key = entry.SessionID + "@" + entry.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	return Record{
+		Key:       key,
+		SessionID: entry.SessionID,
+		Project:   entry.Cwd,
+		Model:     entry.Model,
+		Timestamp: entry.Timestamp,
+		Usage:     entry.Usage,
+		Cost:      calculator.Cost(&entry.Usage, entry.Model),
+	}
+}
+
+// Entry converts the record back for report aggregation, the dedup key
+// is kept as message id so entries stay distinct
+func (r Record) Entry() parser.Entry {
+	return parser.Entry{
+		Timestamp: r.Timestamp,
+		SessionID: r.SessionID,
+		Cwd:       r.Project,
+		Model:     r.Model,
+		MessageID: r.Key,
+		Usage:     r.Usage,
+	}
+}
+
+// DB is the history database, a bbolt file that allows one writer at a time
+type DB struct {
+	db *bolt.DB
+}
+
+// DefaultPath returns the database location under the state dir
+func DefaultPath() string {
+	return filepath.Join(state.Dir(), "history.db")
+}
+
+// Open opens or creates the database and applies pending migrations
+// waiting at most timeout for other processes to release the file lock
+func Open(path string, timeout time.Duration) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create history dir: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: timeout})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &DB{db: db}, nil
+}
+
+// Close releases the database file
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Add stores records not seen before and returns how many were new
+// the status line re-adds a whole transcript on every run, so known records
+// are filtered in a read transaction and the write lock is only taken when needed
+func (d *DB) Add(records []Record) (int, error) {
+	err := d.db.View(func(tx *bolt.Tx) error {
+		turns := tx.Bucket(bucketTurns)
+		records = slices.DeleteFunc(slices.Clone(records), func(r Record) bool {
+			return turns.Get([]byte(r.Key)) != nil
+		})
+		return nil
+	})
+	if err != nil || len(records) == 0 {
+		return 0, err
+	}
+
+	added := 0
+	err = d.db.Update(func(tx *bolt.Tx) error {
+		turns := tx.Bucket(bucketTurns)
+		byTime := tx.Bucket(bucketByTime)
+		for _, record := range records {
+			key := []byte(record.Key)
+			if turns.Get(key) != nil {
+				continue
+			}
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if err := turns.Put(key, data); err != nil {
+				return err
+			}
+			if err := byTime.Put(timeKey(record.Timestamp, record.Key), key); err != nil {
+				return err
+			}
+			added++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to write history: %w", err)
+	}
+	return added, nil
+}
+
+// Filter selects records, zero fields match everything
+type Filter struct {
+	Since     time.Time
+	Until     time.Time // exclusive
+	SessionID string
+	Project   string
+	Model     string
+}
+
+func (f Filter) match(r Record) bool {
+	return (f.SessionID == "" || r.SessionID == f.SessionID) &&
+		(f.Project == "" || r.Project == f.Project) &&
+		(f.Model == "" || r.Model == f.Model)
+}
+
+// Query returns matching records in time order
+func (d *DB) Query(filter Filter) ([]Record, error) {
+	var records []Record
+	err := d.db.View(func(tx *bolt.Tx) error {
+		turns := tx.Bucket(bucketTurns)
+		cursor := tx.Bucket(bucketByTime).Cursor()
+		start := timeKey(filter.Since, "")
+		var end []byte
+		if !filter.Until.IsZero() {
+			end = timeKey(filter.Until, "")
+		}
+
+		for k, key := cursor.Seek(start); k != nil; k, key = cursor.Next() {
+			if end != nil && bytes.Compare(k, end) >= 0 {
+				break
+			}
+			var record Record
+			if err := json.Unmarshal(turns.Get(key), &record); err != nil {
+				return fmt.Errorf("corrupt history record %q: %w", key, err)
+			}
+			if filter.match(record) {
+				records = append(records, record)
+			}
+		}
+		return nil
+	})
+	return records, err
+}
+
+// Prune deletes records older than before and returns how many were removed
+func (d *DB) Prune(before time.Time) (int, error) {
+	removed := 0
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		turns := tx.Bucket(bucketTurns)
+		cursor := tx.Bucket(bucketByTime).Cursor()
+		end := timeKey(before, "")
+		for k, key := cursor.First(); k != nil && bytes.Compare(k, end) < 0; k, key = cursor.First() {
+			if err := turns.Delete(key); err != nil {
+				return err
+			}
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune history: %w", err)
+	}
+	return removed, nil
+}
+
+// timeKey orders the time index; big-endian nanoseconds sort like the times they encode
+func timeKey(ts time.Time, key string) []byte {
+	var nanos uint64
+	if !ts.IsZero() {
+		nanos = uint64(ts.UnixNano())
+	}
+	return append(binary.BigEndian.AppendUint64(nil, nanos), key...)
+}
@@ -0,0 +1,206 @@
+package history
+
+import (
+	"ccstatus/internal/parser"
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var testNow = time.Date(2025, 10, 1, 10, 0, 0, 0, time.UTC)
+
+func testEntry(id, session, model string, age time.Duration) parser.Entry {
+	return parser.Entry{
+		Timestamp: testNow.Add(-age),
+		SessionID: session,
+		Cwd:       "/work/" + session,
+		Model:     model,
+		MessageID: id,
+		RequestID: "req_" + id,
+		Usage:     parser.Usage{InputTokens: 1000, OutputTokens: 100, CacheReadInputTokens: 5000},
+	}
+}
+
+func openTest(t *testing.T) (*DB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "history.db")
+	db, err := Open(path, time.Second)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, path
+}
+
+func TestNewRecord(t *testing.T) {
+	record := NewRecord(testEntry("msg_1", "s1", "claude-sonnet-4-5", 0))
+	if record.Key != "msg_1:req_msg_1" {
+		t.Errorf("Key = %q, want msg_1:req_msg_1", record.Key)
+	}
+	if record.Project != "/work/s1" || record.Cost <= 0 {
+		t.Errorf("NewRecord() = %+v, want project and cost", record)
+	}
+
+	// entries without ids fall back to session and timestamp
+	entry := testEntry("", "s1", "claude-sonnet-4-5", 0)
+	entry.RequestID = ""
+	if got, want := NewRecord(entry).Key, "s1@2025-10-01T10:00:00Z"; got != want {
+		t.Errorf("Key = %q, want %q", got, want)
+	}
+}
+
+func TestAddDeduplicates(t *testing.T) {
+	db, _ := openTest(t)
+	records := []Record{
+		NewRecord(testEntry("a", "s1", "claude-sonnet-4-5", time.Hour)),
+		NewRecord(testEntry("b", "s1", "claude-sonnet-4-5", 0)),
+	}
+	added, err := db.Add(records)
+	if err != nil || added != 2 {
+		t.Fatalf("Add() = %d, %v, want 2", added, err)
+	}
+
+	// a resumed session copies earlier calls, only the new one is stored
+	records = append(records, NewRecord(testEntry("c", "s2", "claude-opus-4-1", 0)))
+	added, err = db.Add(records)
+	if err != nil || added != 1 {
+		t.Fatalf("Add() again = %d, %v, want 1", added, err)
+	}
+
+	all, err := db.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].Key != "a:req_a" {
+		t.Errorf("Query() = %+v, want 3 records oldest first", all)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	db, _ := openTest(t)
+	if _, err := db.Add([]Record{
+		NewRecord(testEntry("a", "s1", "claude-sonnet-4-5", 48*time.Hour)),
+		NewRecord(testEntry("b", "s1", "claude-opus-4-1", 2*time.Hour)),
+		NewRecord(testEntry("c", "s2", "claude-sonnet-4-5", time.Hour)),
+		NewRecord(testEntry("d", "s2", "claude-sonnet-4-5", 0)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all", Filter{}, []string{"a", "b", "c", "d"}},
+		{"since", Filter{Since: testNow.Add(-3 * time.Hour)}, []string{"b", "c", "d"}},
+		{"until is exclusive", Filter{Until: testNow.Add(-time.Hour)}, []string{"a", "b"}},
+		{"session", Filter{SessionID: "s2"}, []string{"c", "d"}},
+		{"project", Filter{Project: "/work/s1"}, []string{"a", "b"}},
+		{"model", Filter{Model: "claude-opus-4-1"}, []string{"b"}},
+		{"no match", Filter{SessionID: "missing"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := db.Query(tt.filter)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			var got []string
+			for _, r := range records {
+				got = append(got, r.Entry().MessageID[:1])
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Query() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Query() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	db, _ := openTest(t)
+	if _, err := db.Add([]Record{
+		NewRecord(testEntry("a", "s1", "claude-sonnet-4-5", 48*time.Hour)),
+		NewRecord(testEntry("b", "s1", "claude-sonnet-4-5", 30*time.Hour)),
+		NewRecord(testEntry("c", "s1", "claude-sonnet-4-5", 0)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := db.Prune(testNow.Add(-24 * time.Hour))
+	if err != nil || removed != 2 {
+		t.Fatalf("Prune() = %d, %v, want 2", removed, err)
+	}
+	records, _ := db.Query(Filter{})
+	if len(records) != 1 || records[0].Key != "c:req_c" {
+		t.Errorf("Query() after prune = %+v, want only c", records)
+	}
+
+	// pruned records are forgotten, re-adding stores them again
+	added, err := db.Add([]Record{NewRecord(testEntry("a", "s1", "claude-sonnet-4-5", 48*time.Hour))})
+	if err != nil || added != 1 {
+		t.Errorf("Add() after prune = %d, %v, want 1", added, err)
+	}
+}
+
+func TestOpenLocked(t *testing.T) {
+	_, path := openTest(t)
+	// the first handle is still open and holds the file lock
+	if _, err := Open(path, 50*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Errorf("Open() error = %v, want ErrLocked", err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	db, path := openTest(t)
+	if _, err := db.Add([]Record{NewRecord(testEntry("a", "s1", "claude-sonnet-4-5", 0))}); err != nil {
+		t.Fatal(err)
+	}
+	lastTx := func() (id int) {
+		db.db.View(func(tx *bolt.Tx) error {
+			id = tx.ID()
+			return nil
+		})
+		return id
+	}
+	before := lastTx()
+	db.Close()
+
+	// reopening keeps data and version, and an up to date database is not written
+	db, err := Open(path, time.Second)
+	if err != nil {
+		t.Fatalf("Open() existing error = %v", err)
+	}
+	if after := lastTx(); after != before {
+		t.Errorf("transaction id after reopen = %d, want %d without a write", after, before)
+	}
+	if records, _ := db.Query(Filter{}); len(records) != 1 {
+		t.Errorf("Query() after reopen = %d records, want 1", len(records))
+	}
+	var version uint64
+	db.db.View(func(tx *bolt.Tx) error {
+		version = binary.BigEndian.Uint64(tx.Bucket(bucketMeta).Get(keyVersion))
+		return nil
+	})
+	if int(version) != SchemaVersion {
+		t.Errorf("schema_version = %d, want %d", version, SchemaVersion)
+	}
+
+	// a database from a newer release is refused
+	db.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketMeta).Put(keyVersion, binary.BigEndian.AppendUint64(nil, uint64(SchemaVersion+1)))
+	})
+	db.Close()
+	if _, err := Open(path, time.Second); err == nil {
+		t.Error("Open() newer schema error = nil, want error")
+	}
+}
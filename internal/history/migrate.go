@@ -0,0 +1,74 @@
+package history
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// migrations upgrade the schema one version at a time, migrations[i] moves
+// a database from version i to i+1; append new steps, never edit released ones
+var migrations = []func(tx *bolt.Tx) error{
+	// v1: records keyed by dedup key plus a time-ordered index
+	func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketTurns, bucketByTime} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// SchemaVersion is the schema version written by this build
+var SchemaVersion = len(migrations)
+
+// migrate brings the database to SchemaVersion inside a single transaction
+// databases written by a newer ccstatus are refused rather than downgraded;
+// an up to date database is only read, so opening it costs no write
+func migrate(db *bolt.DB) error {
+	var version int
+	if err := db.View(func(tx *bolt.Tx) error {
+		version = schemaVersion(tx)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to read history metadata: %w", err)
+	}
+	if version > SchemaVersion {
+		return fmt.Errorf("history schema version %d is newer than supported version %d", version, SchemaVersion)
+	}
+	if version == SchemaVersion {
+		return nil
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(bucketMeta)
+		if err != nil {
+			return fmt.Errorf("failed to create history metadata: %w", err)
+		}
+		// another process may have migrated in between
+		version := schemaVersion(tx)
+		if version > SchemaVersion {
+			return fmt.Errorf("history schema version %d is newer than supported version %d", version, SchemaVersion)
+		}
+		for ; version < SchemaVersion; version++ {
+			if err := migrations[version](tx); err != nil {
+				return fmt.Errorf("failed to migrate history to version %d: %w", version+1, err)
+			}
+		}
+		return meta.Put(keyVersion, binary.BigEndian.AppendUint64(nil, uint64(version)))
+	})
+}
+
+// schemaVersion returns the stored schema version, 0 for a new database
+func schemaVersion(tx *bolt.Tx) int {
+	meta := tx.Bucket(bucketMeta)
+	if meta == nil {
+		return 0
+	}
+	if v := meta.Get(keyVersion); len(v) == 8 {
+		return int(binary.BigEndian.Uint64(v))
+	}
+	return 0
+}
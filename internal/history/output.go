@@ -0,0 +1,66 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// output formats accepted by Write
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+)
+
+// Write renders records as a table or JSON, times are shown in loc
+func Write(w io.Writer, records []Record, format string, loc *time.Location) error {
+	switch format {
+	case FormatTable:
+		return writeTable(w, records, loc)
+	case FormatJSON:
+		if records == nil {
+			records = []Record{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func writeTable(w io.Writer, records []Record, loc *time.Location) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tSESSION\tMODEL\tINPUT\tOUTPUT\tCACHE WRITE\tCACHE READ\tCOST\tPROJECT")
+	var total float64
+	for _, r := range records {
+		cells := []string{
+			r.Timestamp.In(loc).Format(time.DateTime),
+			orDash(r.SessionID),
+			orDash(r.Model),
+			strconv.FormatInt(r.Usage.InputTokens, 10),
+			strconv.FormatInt(r.Usage.OutputTokens, 10),
+			strconv.FormatInt(r.Usage.CacheCreationInputTokens, 10),
+			strconv.FormatInt(r.Usage.CacheReadInputTokens, 10),
+			fmt.Sprintf("$%.2f", r.Cost),
+			orDash(r.Project),
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		total += r.Cost
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d calls, $%.2f\n", len(records), total)
+	return err
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,75 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	records := []Record{
+		{Key: "a", SessionID: "s1", Model: "claude-sonnet-4-5", Timestamp: testNow, Cost: 1.5},
+		{Key: "b", Timestamp: testNow.Add(time.Minute), Cost: 0.25},
+	}
+
+	tests := []struct {
+		name    string
+		records []Record
+		format  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "table",
+			records: records,
+			format:  FormatTable,
+			want:    []string{"TIME", "2025-10-01 10:00:00  s1", "2025-10-01 10:01:00  -", "2 calls, $1.75"},
+		},
+		{
+			name:   "empty table",
+			format: FormatTable,
+			want:   []string{"TIME", "0 calls, $0.00"},
+		},
+		{
+			name:   "empty json",
+			format: FormatJSON,
+			want:   []string{"[]"},
+		},
+		{
+			name:    "unknown format",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Write(&buf, tt.records, tt.format, time.UTC)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Write() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Write() = %q, want it to contain %q", buf.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteJSONRoundTrip(t *testing.T) {
+	record := NewRecord(testEntry("a", "s1", "claude-sonnet-4-5", 0))
+	var buf bytes.Buffer
+	if err := Write(&buf, []Record{record}, FormatJSON, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	var got []Record
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Key != record.Key || got[0].Usage.CacheReadInputTokens != 5000 || got[0].Cost != record.Cost {
+		t.Errorf("round trip = %+v, want %+v", got, record)
+	}
+}
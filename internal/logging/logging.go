@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"ccstatus/internal/state"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// EnvDebug enables debug records when set to a true value, like the debug config option
+const EnvDebug = "CCSTATUS_DEBUG"
+
+// MaxSize is the log size at which Open rotates the file to a single .1 backup
+var MaxSize int64 = 1 << 20
+
+// Path returns the log file location in the state dir
+func Path() string {
+	return filepath.Join(state.Dir(), "ccstatus.log")
+}
+
+// Level returns the level records are written at: warnings and errors are always
+// logged, debug records only when debug is set in the config or by EnvDebug
+func Level(debug bool) slog.Level {
+	if debug || envDebug() {
+		return slog.LevelDebug
+	}
+	return slog.LevelWarn
+}
+
+// envDebug reports whether EnvDebug is set, any value that is not a false boolean enables it
+func envDebug() bool {
+	value := os.Getenv(EnvDebug)
+	if value == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(value)
+	return err != nil || enabled
+}
+
+// Logger writes structured records to the log file, never to stdout
+type Logger struct {
+	*slog.Logger
+	file io.Closer
+}
+
+// Open returns a logger appending records at level or above to path
+// the file is created on the first record, so runs that log nothing leave no file;
+// a log that cannot be opened drops its records
+func Open(path string, level slog.Level) *Logger {
+	file := &lazyFile{path: path}
+	handler := slog.NewTextHandler(file, &slog.HandlerOptions{Level: level})
+	return &Logger{Logger: slog.New(handler), file: file}
+}
+
+// Discard returns a logger that drops every record
+func Discard() *Logger {
+	return &Logger{Logger: slog.New(slog.DiscardHandler)}
+}
+
+// Close closes the log file
+func (l *Logger) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// lazyFile opens the log on the first write, the handler serializes writes
+type lazyFile struct {
+	path string
+	file *os.File
+	err  error
+}
+
+func (f *lazyFile) Write(p []byte) (int, error) {
+	if f.file == nil && f.err == nil {
+		f.file, f.err = openFile(f.path)
+	}
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.file.Write(p)
+}
+
+func (f *lazyFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
+
+// openFile opens path for appending, a file that grew past MaxSize is moved
+// to path.1 first, replacing the previous backup
+func openFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create log dir: %w", err)
+	}
+	if fi, err := os.Stat(path); err == nil && fi.Size() >= MaxSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return nil, fmt.Errorf("failed to rotate log: %w", err)
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log: %w", err)
+	}
+	return file, nil
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "ccstatus.log")
+	for _, msg := range []string{"first", "second"} {
+		logger := Open(path, slog.LevelWarn)
+		logger.Warn(msg, "code", "nofile")
+		logger.Debug("below the level")
+		if err := logger.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "msg=first code=nofile") || !strings.Contains(lines[1], "msg=second") {
+		t.Errorf("log = %q, want both warnings appended", data)
+	}
+}
+
+func TestOpenWithoutRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "ccstatus.log")
+	logger := Open(path, slog.LevelWarn)
+	logger.Debug("below the level")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Stat() error = %v, want no log file without records", err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("CCSTATUS_STATE_DIR", "/tmp/ccstatus-state")
+	if got := Path(); got != "/tmp/ccstatus-state/ccstatus.log" {
+		t.Errorf("Path() = %q", got)
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	logger := Discard()
+	logger.Error("dropped")
+	if err := logger.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestOpenRotates(t *testing.T) {
+	old := MaxSize
+	MaxSize = 10
+	t.Cleanup(func() { MaxSize = old })
+
+	path := filepath.Join(t.TempDir(), "ccstatus.log")
+	for _, msg := range []string{"first", "second"} {
+		logger := Open(path, slog.LevelWarn)
+		logger.Warn(msg)
+		logger.Close()
+	}
+
+	current, _ := os.ReadFile(path)
+	backup, _ := os.ReadFile(path + ".1")
+	if !strings.Contains(string(current), "msg=second") || strings.Contains(string(current), "msg=first") {
+		t.Errorf("log = %q, want only the second record", current)
+	}
+	if !strings.Contains(string(backup), "msg=first") {
+		t.Errorf("backup = %q, want the first record", backup)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		env   string
+		debug bool
+		want  slog.Level
+	}{
+		{"", false, slog.LevelWarn},
+		{"", true, slog.LevelDebug},
+		{"1", false, slog.LevelDebug},
+		{"yes", false, slog.LevelDebug},
+		{"0", false, slog.LevelWarn},
+		{"false", true, slog.LevelDebug},
+	}
+	for _, tt := range tests {
+		t.Setenv(EnvDebug, tt.env)
+		if got := Level(tt.debug); got != tt.want {
+			t.Errorf("Level(%v) with %s=%q = %v, want %v", tt.debug, EnvDebug, tt.env, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package logging
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// Tail returns the last n lines of the log, reaching into the rotated backup
+// when the current file is shorter; a missing log has no lines
+func Tail(path string, n int) ([]string, error) {
+	var lines []string
+	for _, name := range []string{path + ".1", path} {
+		fileLines, err := readLines(name)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, fileLines...)
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+// readLines returns every line of the file at path, a missing file has none
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+	return lines, nil
+}
+
+// Size returns the current size of the log, Follow starts there to skip what was already shown
+func Size(path string) int64 {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
+
+// Follow copies records appended to the log after offset to w until ctx is done,
+// polling every interval; a rotated log is followed from its start
+func Follow(ctx context.Context, w io.Writer, path string, offset int64, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		fi, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			offset = 0
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to stat log: %w", err)
+		}
+		if fi.Size() < offset {
+			offset = 0
+		}
+		if fi.Size() == offset {
+			continue
+		}
+		n, err := copyFrom(w, path, offset)
+		offset += n
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// copyFrom copies the file at path from offset to w and returns the bytes copied
+func copyFrom(w io.Writer, path string, offset int64) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to seek log: %w", err)
+	}
+	return io.Copy(w, file)
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ccstatus.log")
+	if err := os.WriteFile(path+".1", []byte("a\nb\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("c\nd\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{1, []string{"d"}},
+		{3, []string{"b", "c", "d"}},
+		{10, []string{"a", "b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		got, err := Tail(path, tt.n)
+		if err != nil {
+			t.Fatalf("Tail() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tail(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+
+	got, err := Tail(filepath.Join(t.TempDir(), "missing.log"), 5)
+	if err != nil || got != nil {
+		t.Errorf("Tail() of a missing log = %q, %v, want nothing", got, err)
+	}
+}
+
+// syncBuilder is a strings.Builder safe to read while Follow writes to it
+type syncBuilder struct {
+	mu sync.Mutex
+	sb strings.Builder
+}
+
+func (b *syncBuilder) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sb.Write(p)
+}
+
+func (b *syncBuilder) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sb.String()
+}
+
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ccstatus.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out syncBuilder
+	done := make(chan error, 1)
+	offset := Size(path)
+	go func() { done <- Follow(ctx, &out, path, offset, 5*time.Millisecond) }()
+
+	appendLine := func(line string) {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.WriteString(line + "\n")
+		file.Close()
+	}
+	waitFor := func(want string) {
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			if out.String() == want {
+				return
+			}
+		}
+		t.Fatalf("Follow() wrote %q, want %q", out.String(), want)
+	}
+
+	appendLine("new")
+	waitFor("new\n")
+
+	// a rotated log is followed from its start
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("x\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("new\nx\n")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Follow() error = %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"bytes"
+	"ccstatus/internal/calculator"
+	"ccstatus/internal/state"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxLabelLength truncates label values such as long project paths
+const maxLabelLength = 128
+
+// Sample is the latest state of one session, written on every status line run
+type Sample struct {
+	SessionID string    `json:"session_id"`
+	Project   string    `json:"project"`
+	Model     string    `json:"model"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// ContextTokens, ContextLimit and ContextPercentage mirror calculator.ContextInfo
+	ContextTokens     int64   `json:"context_tokens"`
+	ContextLimit      int64   `json:"context_limit"`
+	ContextPercentage float64 `json:"context_percentage"`
+	// Cost and Tokens cover every deduplicated API call of the session
+	Cost   float64                `json:"cost"`
+	Tokens calculator.TokenTotals `json:"tokens"`
+}
+
+// Collector keeps one sample file per session in a directory shared by all ccstatus processes
+type Collector struct {
+	Dir string
+}
+
+// DefaultDir returns the collector directory under the state dir
+func DefaultDir() string {
+	return filepath.Join(state.Dir(), "metrics")
+}
+
+// Record stores the sample, replacing the previous one of the same session
+func (c *Collector) Record(sample Sample) error {
+	if sample.SessionID == "" {
+		return errors.New("sample has no session id")
+	}
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	return state.WriteFileAtomic(c.path(sample.SessionID), data, 0o600)
+}
+
+// Samples returns live samples, most recently updated first
+// samples older than expiry are deleted so finished sessions disappear from the export
+func (c *Collector) Samples(now time.Time, expiry time.Duration) ([]Sample, error) {
+	entries, err := os.ReadDir(c.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metrics dir: %w", err)
+	}
+
+	var samples []Sample
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(c.Dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var sample Sample
+		if err := json.Unmarshal(data, &sample); err != nil || now.Sub(sample.UpdatedAt) > expiry {
+			os.Remove(path)
+			continue
+		}
+		samples = append(samples, sample)
+	}
+
+	sort.Slice(samples, func(i, j int) bool {
+		if !samples[i].UpdatedAt.Equal(samples[j].UpdatedAt) {
+			return samples[i].UpdatedAt.After(samples[j].UpdatedAt)
+		}
+		return samples[i].SessionID < samples[j].SessionID
+	})
+	return samples, nil
+}
+
+func (c *Collector) path(sessionID string) string {
+	return state.New(c.Dir).Path(sessionID)
+}
+
+// metric describes one exported metric family
+type metric struct {
+	name  string
+	help  string
+	kind  string
+	value func(s Sample) float64
+}
+
+var gauges = []metric{
+	{"ccstatus_context_tokens", "Tokens in the context window after the last API call.", "gauge", func(s Sample) float64 { return float64(s.ContextTokens) }},
+	{"ccstatus_context_limit_tokens", "Context window size of the model.", "gauge", func(s Sample) float64 { return float64(s.ContextLimit) }},
+	{"ccstatus_context_used_percent", "Share of the context window in use, 0-100.", "gauge", func(s Sample) float64 { return s.ContextPercentage }},
+	{"ccstatus_session_cost_usd", "Estimated session cost in USD.", "gauge", func(s Sample) float64 { return s.Cost }},
+	{"ccstatus_cache_hit_ratio", "Cache reads over all input-side tokens of the session, 0-1.", "gauge", func(s Sample) float64 { return calculator.CacheHitRatio(s.Tokens) }},
+	{"ccstatus_last_update_timestamp_seconds", "Unix time of the last status line run of the session.", "gauge", func(s Sample) float64 { return float64(s.UpdatedAt.Unix()) }},
+}
+
+// tokenTypes are the values of the type label of ccstatus_session_tokens_total
+var tokenTypes = []struct {
+	name  string
+	value func(t calculator.TokenTotals) int64
+}{
+	{"input", func(t calculator.TokenTotals) int64 { return t.InputTokens }},
+	{"output", func(t calculator.TokenTotals) int64 { return t.OutputTokens }},
+	{"cache_creation", func(t calculator.TokenTotals) int64 { return t.CacheCreationInputTokens }},
+	{"cache_read", func(t calculator.TokenTotals) int64 { return t.CacheReadInputTokens }},
+}
+
+// Write renders samples in the Prometheus text exposition format
+// only the first maxSessions samples are exported to bound label cardinality,
+// the rest are counted in ccstatus_sessions_dropped
+func Write(w io.Writer, samples []Sample, maxSessions int) error {
+	dropped := 0
+	if maxSessions > 0 && len(samples) > maxSessions {
+		dropped = len(samples) - maxSessions
+		samples = samples[:maxSessions]
+	}
+
+	var b bytes.Buffer
+	for _, m := range gauges {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for _, s := range samples {
+			fmt.Fprintf(&b, "%s{%s} %s\n", m.name, labels(s), formatValue(m.value(s)))
+		}
+	}
+
+	fmt.Fprint(&b, "# HELP ccstatus_session_tokens_total Tokens of the session by type.\n# TYPE ccstatus_session_tokens_total counter\n")
+	for _, s := range samples {
+		for _, t := range tokenTypes {
+			fmt.Fprintf(&b, "ccstatus_session_tokens_total{%s,type=%q} %d\n", labels(s), t.name, t.value(s.Tokens))
+		}
+	}
+
+	fmt.Fprint(&b, "# HELP ccstatus_sessions Sessions with a recent status line run.\n# TYPE ccstatus_sessions gauge\n")
+	fmt.Fprintf(&b, "ccstatus_sessions %d\n", len(samples)+dropped)
+	fmt.Fprint(&b, "# HELP ccstatus_sessions_dropped Sessions left out of the export by the session cap.\n# TYPE ccstatus_sessions_dropped gauge\n")
+	fmt.Fprintf(&b, "ccstatus_sessions_dropped %d\n", dropped)
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// WriteTextfile atomically replaces a node_exporter textfile collector file
+func WriteTextfile(path string, samples []Sample, maxSessions int) error {
+	var b bytes.Buffer
+	if err := Write(&b, samples, maxSessions); err != nil {
+		return err
+	}
+	// the collector runs as another user, the file has to be world-readable
+	return state.WriteFileAtomic(path, b.Bytes(), 0o644)
+}
+
+func labels(s Sample) string {
+	return fmt.Sprintf(`session="%s",project="%s",model="%s"`,
+		escapeLabel(s.SessionID), escapeLabel(s.Project), escapeLabel(s.Model))
+}
+
+// escapeLabel truncates and escapes a label value per the exposition format
+func escapeLabel(value string) string {
+	if runes := []rune(value); len(runes) > maxLabelLength {
+		value = string(runes[:maxLabelLength])
+	}
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatValue(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.6f", v), "0"), ".")
+}
@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"bytes"
+	"ccstatus/internal/calculator"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2025, 10, 1, 10, 0, 0, 0, time.UTC)
+
+func testSample(id string, age time.Duration) Sample {
+	return Sample{
+		SessionID:         id,
+		Project:           "/work/app",
+		Model:             "claude-sonnet-4-5",
+		UpdatedAt:         testNow.Add(-age),
+		ContextTokens:     50000,
+		ContextLimit:      200000,
+		ContextPercentage: 25,
+		Cost:              1.25,
+		Tokens: calculator.TokenTotals{
+			InputTokens:          100,
+			OutputTokens:         20,
+			CacheReadInputTokens: 900,
+		},
+	}
+}
+
+func TestCollectorSamples(t *testing.T) {
+	c := &Collector{Dir: t.TempDir()}
+	for _, s := range []Sample{testSample("old", 2*time.Hour), testSample("a", time.Minute), testSample("b", 0)} {
+		if err := c.Record(s); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	// a newer sample of the same session replaces the old one
+	if err := c.Record(testSample("a", 30*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Record(Sample{}); err == nil {
+		t.Error("Record() without session id error = nil, want error")
+	}
+
+	samples, err := c.Samples(testNow, time.Hour)
+	if err != nil {
+		t.Fatalf("Samples() error = %v", err)
+	}
+	if len(samples) != 2 || samples[0].SessionID != "b" || samples[1].SessionID != "a" {
+		t.Fatalf("Samples() = %+v, want b then a", samples)
+	}
+	if !samples[1].UpdatedAt.Equal(testNow.Add(-30 * time.Second)) {
+		t.Errorf("Samples()[1].UpdatedAt = %v, want the replaced sample", samples[1].UpdatedAt)
+	}
+
+	// the expired session file is gone
+	files, _ := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if len(files) != 2 {
+		t.Errorf("sample files = %d, want 2 after expiry", len(files))
+	}
+}
+
+func TestCollectorMissingDir(t *testing.T) {
+	c := &Collector{Dir: filepath.Join(t.TempDir(), "missing")}
+	samples, err := c.Samples(testNow, time.Hour)
+	if err != nil || samples != nil {
+		t.Errorf("Samples() = %v, %v, want nothing", samples, err)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	quoted := testSample("b", 0)
+	quoted.Project = `/work/"quoted"\dir`
+	samples := []Sample{testSample("a", 0), quoted, testSample("c", time.Minute)}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, samples, 2); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"# TYPE ccstatus_context_tokens gauge\n",
+		`ccstatus_context_tokens{session="a",project="/work/app",model="claude-sonnet-4-5"} 50000` + "\n",
+		`ccstatus_context_used_percent{session="a",project="/work/app",model="claude-sonnet-4-5"} 25` + "\n",
+		`ccstatus_session_cost_usd{session="a",project="/work/app",model="claude-sonnet-4-5"} 1.25` + "\n",
+		`ccstatus_cache_hit_ratio{session="a",project="/work/app",model="claude-sonnet-4-5"} 0.9` + "\n",
+		`ccstatus_session_tokens_total{session="a",project="/work/app",model="claude-sonnet-4-5",type="cache_read"} 900` + "\n",
+		`project="/work/\"quoted\"\\dir"`,
+		"ccstatus_sessions 3\n",
+		"ccstatus_sessions_dropped 1\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Write() does not contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, `session="c"`) {
+		t.Errorf("Write() exported a session beyond the cap:\n%s", got)
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	long := strings.Repeat("é", maxLabelLength+10)
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain", "abc", "abc"},
+		{"quotes and backslash", `a"b\c`, `a\"b\\c`},
+		{"newline", "a\nb", `a\nb`},
+		{"truncated by runes", long, strings.Repeat("é", maxLabelLength)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeLabel(tt.value); got != tt.want {
+				t.Errorf("escapeLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTextfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ccstatus.prom")
+	if err := WriteTextfile(path, []Sample{testSample("a", 0)}, 10); err != nil {
+		t.Fatalf("WriteTextfile() error = %v", err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o644 {
+		t.Errorf("textfile mode = %v, want 0644", fi.Mode().Perm())
+	}
+}
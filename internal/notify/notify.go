@@ -0,0 +1,158 @@
+package notify
+
+import (
+	"ccstatus/internal/state"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// metrics that thresholds can be configured for
+const (
+	MetricContext = "context"
+	MetricCost    = "cost"
+)
+
+// Event describes a threshold crossing, it is passed to the command as JSON on stdin
+type Event struct {
+	Type           string    `json:"type"`
+	Metric         string    `json:"metric"`
+	Threshold      float64   `json:"threshold"`
+	Value          float64   `json:"value"`
+	SessionID      string    `json:"session_id"`
+	Project        string    `json:"project"`
+	Model          string    `json:"model"`
+	TranscriptPath string    `json:"transcript_path"`
+	Timestamp      time.Time `json:"timestamp"`
+	// Message is a ready to display summary
+	Message string `json:"message"`
+}
+
+// Thresholds lists the configured limits per metric
+type Thresholds struct {
+	// Context is in percent of the context window
+	Context []float64
+	// Cost is the session cost in USD
+	Cost []float64
+}
+
+// Values are the current session numbers checked against Thresholds
+type Values struct {
+	Context float64
+	Cost    float64
+}
+
+// Fired is the per-session record of thresholds that already fired
+type Fired struct {
+	Context []float64 `json:"context,omitempty"`
+	Cost    []float64 `json:"cost,omitempty"`
+}
+
+// Check returns the crossings not fired before and marks them in fired
+// when a jump crosses several thresholds of a metric at once only the
+// highest is reported, the lower ones are marked so they never fire later
+func Check(thresholds Thresholds, values Values, fired *Fired) []Event {
+	var events []Event
+	if e, ok := check(MetricContext, thresholds.Context, values.Context, &fired.Context); ok {
+		events = append(events, e)
+	}
+	if e, ok := check(MetricCost, thresholds.Cost, values.Cost, &fired.Cost); ok {
+		events = append(events, e)
+	}
+	return events
+}
+
+func check(metric string, thresholds []float64, value float64, fired *[]float64) (Event, bool) {
+	event := Event{Type: "threshold", Metric: metric, Value: value}
+	crossed := false
+	for _, threshold := range thresholds {
+		if value < threshold || slices.Contains(*fired, threshold) {
+			continue
+		}
+		*fired = append(*fired, threshold)
+		if !crossed || threshold > event.Threshold {
+			event.Threshold = threshold
+		}
+		crossed = true
+	}
+	return event, crossed
+}
+
+// Describe fills Message from the other fields
+func (e *Event) Describe() {
+	project := filepath.Base(e.Project)
+	if e.Project == "" {
+		project = "session"
+	}
+	switch e.Metric {
+	case MetricContext:
+		e.Message = fmt.Sprintf("%s: context %.0f%% used, crossed %g%%", project, e.Value, e.Threshold)
+	case MetricCost:
+		e.Message = fmt.Sprintf("%s: session cost $%.2f, crossed $%g", project, e.Value, e.Threshold)
+	}
+}
+
+// Tracker remembers fired thresholds per session in a state store
+type Tracker struct {
+	Store *state.Store
+}
+
+// Check is like the package function but loads and saves the fired record of session
+func (t *Tracker) Check(session string, thresholds Thresholds, values Values) ([]Event, error) {
+	key := "notify-" + session
+	// two runs started close together must not both see a threshold as unfired
+	unlock, err := t.Store.Lock(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock notification state: %w", err)
+	}
+	defer unlock()
+
+	var fired Fired
+	// a missing or unreadable record means nothing fired yet
+	_, _ = t.Store.Load(key, &fired)
+
+	events := Check(thresholds, values, &fired)
+	if len(events) == 0 {
+		return nil, nil
+	}
+	// saved before the command runs, a failing command must not fire again on every run
+	if err := t.Store.Save(key, fired); err != nil {
+		return nil, fmt.Errorf("failed to save notification state: %w", err)
+	}
+	return events, nil
+}
+
+// Start runs command with the shell and the event JSON on stdin without waiting for it,
+// notifications must never delay the status line; the process is returned for tests
+func Start(command string, event Event) (*os.Process, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	// stdin is an unlinked temp file rather than a pipe, so nothing
+	// has to stay alive in ccstatus to feed the command
+	stdin, err := os.CreateTemp("", "ccstatus-event-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event file: %w", err)
+	}
+	defer stdin.Close()
+	defer os.Remove(stdin.Name())
+	if _, err := stdin.Write(append(data, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write event file: %w", err)
+	}
+	if _, err := stdin.Seek(0, 0); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.Stdin = stdin
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run notify command: %w", err)
+	}
+	return cmd.Process, nil
+}
@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"ccstatus/internal/state"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	thresholds := Thresholds{Context: []float64{70, 90}, Cost: []float64{5}}
+
+	tests := []struct {
+		name      string
+		values    Values
+		fired     Fired
+		want      []Event
+		wantFired Fired
+	}{
+		{
+			name:   "below every threshold",
+			values: Values{Context: 50, Cost: 1},
+		},
+		{
+			name:      "first context threshold",
+			values:    Values{Context: 72, Cost: 1},
+			want:      []Event{{Type: "threshold", Metric: MetricContext, Threshold: 70, Value: 72}},
+			wantFired: Fired{Context: []float64{70}},
+		},
+		{
+			name:      "already fired",
+			values:    Values{Context: 80, Cost: 1},
+			fired:     Fired{Context: []float64{70}},
+			wantFired: Fired{Context: []float64{70}},
+		},
+		{
+			name:   "jump reports only the highest",
+			values: Values{Context: 95, Cost: 6},
+			want: []Event{
+				{Type: "threshold", Metric: MetricContext, Threshold: 90, Value: 95},
+				{Type: "threshold", Metric: MetricCost, Threshold: 5, Value: 6},
+			},
+			wantFired: Fired{Context: []float64{70, 90}, Cost: []float64{5}},
+		},
+		{
+			name:      "threshold reached exactly",
+			values:    Values{Context: 90},
+			fired:     Fired{Context: []float64{70}},
+			want:      []Event{{Type: "threshold", Metric: MetricContext, Threshold: 90, Value: 90}},
+			wantFired: Fired{Context: []float64{70, 90}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fired := tt.fired
+			got := Check(thresholds, tt.values, &fired)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(fired, tt.wantFired) {
+				t.Errorf("fired = %+v, want %+v", fired, tt.wantFired)
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		event Event
+		want  string
+	}{
+		{Event{Metric: MetricContext, Threshold: 90, Value: 91.4, Project: "/work/app"}, "app: context 91% used, crossed 90%"},
+		{Event{Metric: MetricCost, Threshold: 5, Value: 5.123}, "session: session cost $5.12, crossed $5"},
+	}
+	for _, tt := range tests {
+		tt.event.Describe()
+		if tt.event.Message != tt.want {
+			t.Errorf("Describe() = %q, want %q", tt.event.Message, tt.want)
+		}
+	}
+}
+
+func TestTrackerFiresOnce(t *testing.T) {
+	tracker := &Tracker{Store: state.New(t.TempDir())}
+	thresholds := Thresholds{Context: []float64{70}}
+
+	events, err := tracker.Check("s1", thresholds, Values{Context: 75})
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Check() = %+v, %v, want one event", events, err)
+	}
+	// a later run of the same session stays quiet
+	if events, _ := tracker.Check("s1", thresholds, Values{Context: 80}); len(events) != 0 {
+		t.Errorf("Check() again = %+v, want none", events)
+	}
+	// other sessions are tracked separately
+	if events, _ := tracker.Check("s2", thresholds, Values{Context: 80}); len(events) != 1 {
+		t.Errorf("Check() other session = %+v, want one event", events)
+	}
+}
+
+func TestTrackerWaitsForLock(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("state locks are a no-op without flock")
+	}
+	store := state.New(t.TempDir())
+	tracker := &Tracker{Store: store}
+	thresholds := Thresholds{Context: []float64{70}}
+
+	// another run holding the session record is between its load and save
+	unlock, err := store.Lock("notify-s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan []Event)
+	go func() {
+		events, _ := tracker.Check("s1", thresholds, Values{Context: 75})
+		done <- events
+	}()
+	select {
+	case <-done:
+		t.Fatal("Check() returned while another run holds the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// that run fired the threshold, so this one stays quiet once it gets the lock
+	if err := store.Save("notify-s1", Fired{Context: []float64{70}}); err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	select {
+	case events := <-done:
+		if len(events) != 0 {
+			t.Errorf("Check() after the other run = %+v, want none", events)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Check() did not return after the lock was released")
+	}
+}
+
+func TestStart(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "event.json")
+	event := Event{Type: "threshold", Metric: MetricCost, Threshold: 5, Value: 6, SessionID: "s1"}
+
+	// the stub command just captures its stdin
+	proc, err := Start("cat > "+out, event)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if _, err := proc.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Event
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("stdin is not an event: %v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(got, event) {
+		t.Errorf("stdin = %+v, want %+v", got, event)
+	}
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compression formats reported by DetectCompression
+const (
+	CompressionNone = ""
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// magic bytes at the start of compressed streams
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// DetectCompression returns the compression format of a stream starting with header,
+// detection goes by magic bytes so archives are recognized whatever their name
+func DetectCompression(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return CompressionGzip
+	case bytes.HasPrefix(header, zstdMagic):
+		return CompressionZstd
+	default:
+		return CompressionNone
+	}
+}
+
+// Decompress returns a reader of the decompressed content of r and its compression format,
+// uncompressed input is passed through; the reader must be closed to release the decoder
+func Decompress(r io.Reader) (io.ReadCloser, string, error) {
+	br := bufio.NewReaderSize(r, 64*1024)
+	header, err := br.Peek(len(zstdMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, CompressionNone, fmt.Errorf("error reading transcript: %w", err)
+	}
+
+	compression := DetectCompression(header)
+	switch compression {
+	case CompressionGzip:
+		reader, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, compression, fmt.Errorf("failed to read gzip transcript: %w", err)
+		}
+		return reader, compression, nil
+	case CompressionZstd:
+		decoder, err := zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, compression, fmt.Errorf("failed to read zstd transcript: %w", err)
+		}
+		return decoder.IOReadCloser(), compression, nil
+	default:
+		return io.NopCloser(br), compression, nil
+	}
+}
@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const compressTranscript = `{"type":"assistant","timestamp":"2025-10-01T10:00:00Z","message":{"role":"assistant","usage":{"input_tokens":5,"cache_read_input_tokens":100}}}
+{"type":"assistant","timestamp":"2025-10-01T10:01:00Z","message":{"role":"assistant","usage":{"input_tokens":7,"cache_read_input_tokens":200}}}
+`
+
+func gzipData(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zstdData(t *testing.T, data string) []byte {
+	t.Helper()
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer encoder.Close()
+	return encoder.EncodeAll([]byte(data), nil)
+}
+
+func TestDetectCompression(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{"gzip", []byte{0x1f, 0x8b, 0x08, 0x00}, CompressionGzip},
+		{"zstd", []byte{0x28, 0xb5, 0x2f, 0xfd}, CompressionZstd},
+		{"jsonl", []byte(`{"ty`), CompressionNone},
+		{"short", []byte{0x28, 0xb5}, CompressionNone},
+		{"empty", nil, CompressionNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectCompression(tt.header); got != tt.want {
+				t.Errorf("DetectCompression() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		compression string
+	}{
+		{"plain", []byte(compressTranscript), CompressionNone},
+		{"gzip", gzipData(t, compressTranscript), CompressionGzip},
+		{"zstd", zstdData(t, compressTranscript), CompressionZstd},
+		// concatenated gzip members, as left by appending to an archive
+		{"gzip multistream", append(gzipData(t, compressTranscript[:10]), gzipData(t, compressTranscript[10:])...), CompressionGzip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, compression, err := Decompress(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("Decompress() error = %v", err)
+			}
+			defer reader.Close()
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("read error = %v", err)
+			}
+			if compression != tt.compression || string(got) != compressTranscript {
+				t.Errorf("Decompress() = %q, %q, want the transcript as %q", got, compression, tt.compression)
+			}
+		})
+	}
+
+	reader, _, err := Decompress(bytes.NewReader(nil))
+	if err != nil {
+		t.Fatalf("Decompress() of empty input error = %v", err)
+	}
+	if got, _ := io.ReadAll(reader); len(got) != 0 {
+		t.Errorf("Decompress() of empty input = %q", got)
+	}
+}
+
+func TestParseCompressedTranscript(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		// detection goes by content, not by name
+		"session.jsonl.gz":  gzipData(t, compressTranscript),
+		"session.jsonl.zst": zstdData(t, compressTranscript),
+		"renamed.jsonl":     zstdData(t, compressTranscript),
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			session, err := ParseSession(path)
+			if err != nil {
+				t.Fatalf("ParseSession() error = %v", err)
+			}
+			if session.Usage.CacheReadInputTokens != 200 || session.Lines != 2 {
+				t.Errorf("ParseSession() = %+v, want the last usage of 2 lines", session)
+			}
+			turns, err := ParseTurns(path)
+			if err != nil {
+				t.Fatalf("ParseTurns() error = %v", err)
+			}
+			if len(turns) != 2 {
+				t.Errorf("ParseTurns() = %d turns, want 2", len(turns))
+			}
+		})
+	}
+
+	// a corrupt archive is an error, not an empty session
+	path := filepath.Join(dir, "corrupt.jsonl.gz")
+	data := gzipData(t, compressTranscript)
+	if err := os.WriteFile(path, data[:len(data)/2], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseSession(path); err == nil || errors.Is(err, ErrInvalidPath) {
+		t.Errorf("ParseSession() of a truncated archive error = %v, want a read error", err)
+	}
+}
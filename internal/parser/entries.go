@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// maxLineSize matches the scanner limit used by ParseTranscript
+const maxLineSize = 1024 * 1024
+
+// Entry is a transcript line that carries API usage, used for cost aggregation
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	SessionID string    `json:"session_id,omitempty"`
+	Cwd       string    `json:"cwd,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	MessageID string    `json:"message_id,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	Usage     Usage     `json:"usage"`
+}
+
+// DedupKey identifies the API response an entry belongs to
+// Claude Code writes one line per content block and copies messages into
+// resumed sessions, all of them sharing message and request ids
+// Returns empty string when the entry cannot be deduplicated
+func (e *Entry) DedupKey() string {
+	if e.MessageID == "" && e.RequestID == "" {
+		return ""
+	}
+	return e.MessageID + ":" + e.RequestID
+}
+
+// ReadUsageEntries reads every timestamped entry with usage data from r
+// Returns the entries and the number of bytes consumed up to the end of the
+// last complete line, so callers can resume reading an appended file there;
+// a trailing line without newline is treated as a partial write and left unread
+func ReadUsageEntries(r io.Reader) ([]Entry, int64, error) {
+	var entries []Entry
+	var consumed int64
+	br := bufio.NewReaderSize(r, 64*1024)
+
+	for {
+		line, n, err := readLine(br)
+		if errors.Is(err, io.EOF) {
+			return entries, consumed, nil
+		}
+		if err != nil {
+			return entries, consumed, fmt.Errorf("error reading transcript: %w", err)
+		}
+		consumed += n
+
+		if entry, ok := parseUsageEntry(line); ok {
+			entries = append(entries, entry)
+		}
+	}
+}
+
+// readLine returns the next newline-terminated line and the number of bytes it occupied
+// lines longer than maxLineSize are consumed but returned truncated, they cannot
+// be valid entries anyway; io.EOF is returned together with a partial final line
+func readLine(br *bufio.Reader) ([]byte, int64, error) {
+	var line []byte
+	var n int64
+	for {
+		chunk, err := br.ReadSlice('\n')
+		n += int64(len(chunk))
+		if room := maxLineSize - len(line); room > 0 {
+			line = append(line, chunk[:min(room, len(chunk))]...)
+		}
+		switch {
+		case err == nil:
+			return line, n, nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		default:
+			return line, n, err
+		}
+	}
+}
+
+func parseUsageEntry(line []byte) (Entry, bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return Entry{}, false
+	}
+
+	var msg Message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		// skip malformed lines
+		return Entry{}, false
+	}
+	// same rule as the status line, synthetic and errored entries never count
+	if msg.Message.Role == "" || msg.Kind() != EntryUsage {
+		return Entry{}, false
+	}
+	ts, ok := parseTimestamp(msg.Timestamp)
+	if !ok {
+		return Entry{}, false
+	}
+
+	return Entry{
+		Timestamp: ts,
+		SessionID: msg.SessionID,
+		Cwd:       msg.Cwd,
+		Model:     msg.Message.Model,
+		MessageID: msg.Message.ID,
+		RequestID: msg.RequestID,
+		Usage:     msg.Message.Usage,
+	}, true
+}
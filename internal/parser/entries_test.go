@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadUsageEntries(t *testing.T) {
+	lines := []string{
+		`{"type":"user","timestamp":"2025-10-01T10:00:00Z","message":{"role":"user","content":"hi"}}`,
+		`{"type":"assistant","timestamp":"2025-10-01T10:00:05Z","sessionId":"s1","cwd":"/work/app","requestId":"req_1","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":5,"cache_read_input_tokens":100,"cache_creation_input_tokens":20,"output_tokens":10}}}`,
+		`not json`,
+		`{"type":"assistant","message":{"role":"assistant","usage":{"input_tokens":1}}}`,
+		`{"type":"assistant","timestamp":"2025-10-01T10:01:00Z","message":{"role":"assistant","usage":{"input_tokens":0,"output_tokens":0}}}`,
+		`{"type":"assistant","timestamp":"2025-10-01T10:01:30Z","message":{"role":"assistant","model":"<synthetic>","usage":{"input_tokens":4}}}`,
+		`{"type":"assistant","timestamp":"2025-10-01T10:01:40Z","isApiErrorMessage":true,"message":{"role":"assistant","model":"claude-opus-4-1","usage":{"input_tokens":4}}}`,
+		`{"type":"assistant","timestamp":"2025-10-01T10:02:00Z","requestId":"req_2","message":{"id":"msg_2","role":"assistant","model":"claude-opus-4-1","usage":{"input_tokens":7,"output_tokens":3}}}`,
+	}
+	complete := strings.Join(lines, "\n") + "\n"
+	partial := `{"type":"assistant","timestamp":"2025-10-01T10:03:00Z","message":{"role":"assis`
+
+	entries, consumed, err := ReadUsageEntries(strings.NewReader(complete + partial))
+	if err != nil {
+		t.Fatalf("ReadUsageEntries() error = %v", err)
+	}
+	if consumed != int64(len(complete)) {
+		t.Errorf("consumed = %d, want %d (partial line must not be consumed)", consumed, len(complete))
+	}
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+
+	first := entries[0]
+	if !first.Timestamp.Equal(time.Date(2025, 10, 1, 10, 0, 5, 0, time.UTC)) {
+		t.Errorf("Timestamp = %v", first.Timestamp)
+	}
+	if first.SessionID != "s1" || first.Cwd != "/work/app" || first.Model != "claude-sonnet-4-5-20250929" {
+		t.Errorf("metadata = %+v", first)
+	}
+	if first.Usage.CacheReadInputTokens != 100 || first.Usage.CacheCreationInputTokens != 20 {
+		t.Errorf("Usage = %+v", first.Usage)
+	}
+	if first.DedupKey() != "msg_1:req_1" {
+		t.Errorf("DedupKey() = %q, want msg_1:req_1", first.DedupKey())
+	}
+	if entries[1].Model != "claude-opus-4-1" {
+		t.Errorf("second entry model = %q", entries[1].Model)
+	}
+}
+
+func TestReadUsageEntriesResume(t *testing.T) {
+	first := `{"type":"assistant","timestamp":"2025-10-01T10:00:05Z","message":{"id":"a","role":"assistant","usage":{"input_tokens":5}}}` + "\n"
+	second := `{"type":"assistant","timestamp":"2025-10-01T10:00:06Z","message":{"id":"b","role":"assistant","usage":{"input_tokens":6}}}` + "\n"
+
+	_, offset, err := ReadUsageEntries(strings.NewReader(first))
+	if err != nil {
+		t.Fatalf("ReadUsageEntries() error = %v", err)
+	}
+
+	// appending to the file and resuming at offset yields only the new entry
+	entries, consumed, err := ReadUsageEntries(strings.NewReader((first + second)[offset:]))
+	if err != nil {
+		t.Fatalf("ReadUsageEntries() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].MessageID != "b" {
+		t.Errorf("entries = %+v, want only message b", entries)
+	}
+	if consumed != int64(len(second)) {
+		t.Errorf("consumed = %d, want %d", consumed, len(second))
+	}
+}
+
+func TestReadUsageEntriesLongLine(t *testing.T) {
+	long := `{"type":"user","content":"` + strings.Repeat("x", 2*maxLineSize) + `"}` + "\n"
+	valid := `{"type":"assistant","timestamp":"2025-10-01T10:00:05Z","message":{"role":"assistant","usage":{"input_tokens":5}}}` + "\n"
+
+	entries, consumed, err := ReadUsageEntries(strings.NewReader(long + valid))
+	if err != nil {
+		t.Fatalf("ReadUsageEntries() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("len(entries) = %d, want 1", len(entries))
+	}
+	if consumed != int64(len(long)+len(valid)) {
+		t.Errorf("consumed = %d, want %d", consumed, len(long)+len(valid))
+	}
+}
+
+func TestEntryDedupKey(t *testing.T) {
+	if key := (&Entry{}).DedupKey(); key != "" {
+		t.Errorf("DedupKey() = %q, want empty", key)
+	}
+	if key := (&Entry{RequestID: "req"}).DedupKey(); key != ":req" {
+		t.Errorf("DedupKey() = %q, want :req", key)
+	}
+}
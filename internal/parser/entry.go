@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// SyntheticModel is the model Claude Code records on messages it wrote itself
+const SyntheticModel = "<synthetic>"
+
+// interruptedPrefix starts the user message Claude Code writes when a request is cancelled,
+// followed by "]" or " for tool use]"
+const interruptedPrefix = "[Request interrupted by user"
+
+// apiErrorPrefix starts the text of failed API requests in older transcripts
+// that lack the isApiErrorMessage flag
+const apiErrorPrefix = "API Error"
+
+// EntryKind classifies a transcript entry for usage accounting
+type EntryKind int
+
+const (
+	// EntryOther carries no usage: user prompts, summaries, system entries
+	EntryOther EntryKind = iota
+	// EntryUsage carries non-zero token usage
+	EntryUsage
+	// EntryZeroUsage is an assistant message whose usage counts are all zero
+	EntryZeroUsage
+	// EntrySynthetic is a placeholder message written by Claude Code instead of the model
+	EntrySynthetic
+	// EntryAPIError is a failed API request recorded as an assistant message
+	EntryAPIError
+	// EntryInterrupted is the marker of a request the user interrupted
+	EntryInterrupted
+)
+
+// String returns the kind name used in debug logs and timelines
+func (k EntryKind) String() string {
+	switch k {
+	case EntryUsage:
+		return "usage"
+	case EntryZeroUsage:
+		return "zero_usage"
+	case EntrySynthetic:
+		return "synthetic"
+	case EntryAPIError:
+		return "api_error"
+	case EntryInterrupted:
+		return "interrupted"
+	default:
+		return "other"
+	}
+}
+
+// Kind classifies the entry, synthetic and errored entries never count as usage
+// even if they carry token counts
+func (m *Message) Kind() EntryKind {
+	if m.IsAPIErrorMessage {
+		return EntryAPIError
+	}
+	if m.Message.Model == SyntheticModel {
+		text := m.text()
+		switch {
+		case strings.HasPrefix(text, apiErrorPrefix):
+			return EntryAPIError
+		case strings.HasPrefix(text, interruptedPrefix):
+			return EntryInterrupted
+		}
+		return EntrySynthetic
+	}
+	if m.Message.Role == "user" && bytes.Contains(m.Message.Content, []byte(interruptedPrefix)) &&
+		strings.HasPrefix(m.text(), interruptedPrefix) {
+		return EntryInterrupted
+	}
+	if hasValidUsage(&m.Message.Usage) {
+		return EntryUsage
+	}
+	if m.Message.Role == "assistant" {
+		return EntryZeroUsage
+	}
+	return EntryOther
+}
+
+// text returns the message content as a string or its first text block, empty otherwise
+func (m *Message) text() string {
+	content := m.Message.Content
+	if len(content) == 0 {
+		return ""
+	}
+	var text string
+	if json.Unmarshal(content, &text) == nil {
+		return text
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(content, &blocks) != nil {
+		return ""
+	}
+	for _, block := range blocks {
+		if block.Type == "text" {
+			return block.Text
+		}
+	}
+	return ""
+}
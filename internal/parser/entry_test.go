@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMessageKind(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want EntryKind
+	}{
+		{
+			name: "assistant response",
+			line: `{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":5,"output_tokens":2}}}`,
+			want: EntryUsage,
+		},
+		{
+			name: "assistant message with zero usage",
+			line: `{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":0,"output_tokens":0}}}`,
+			want: EntryZeroUsage,
+		},
+		{
+			name: "flagged api error",
+			line: `{"type":"assistant","isApiErrorMessage":true,"message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"API Error: 529 overloaded"}],"usage":{"input_tokens":0}}}`,
+			want: EntryAPIError,
+		},
+		{
+			name: "api error without the flag",
+			line: `{"type":"assistant","message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"API Error: Request timed out."}]}}`,
+			want: EntryAPIError,
+		},
+		{
+			name: "synthetic placeholder",
+			line: `{"type":"assistant","message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"No response requested."}]}}`,
+			want: EntrySynthetic,
+		},
+		{
+			name: "synthetic message with usage",
+			line: `{"type":"assistant","message":{"role":"assistant","model":"<synthetic>","usage":{"input_tokens":5}}}`,
+			want: EntrySynthetic,
+		},
+		{
+			name: "interrupted request",
+			line: `{"type":"user","message":{"role":"user","content":[{"type":"text","text":"[Request interrupted by user]"}]}}`,
+			want: EntryInterrupted,
+		},
+		{
+			name: "interrupted tool use as a string",
+			line: `{"type":"user","message":{"role":"user","content":"[Request interrupted by user for tool use]"}}`,
+			want: EntryInterrupted,
+		},
+		{
+			name: "prompt quoting the interruption marker",
+			line: `{"type":"user","message":{"role":"user","content":"why do I see [Request interrupted by user]?"}}`,
+			want: EntryOther,
+		},
+		{
+			name: "user prompt",
+			line: `{"type":"user","message":{"role":"user","content":"hi"}}`,
+			want: EntryOther,
+		},
+		{
+			name: "summary",
+			line: `{"type":"summary","summary":"earlier work"}`,
+			want: EntryOther,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg Message
+			if err := json.Unmarshal([]byte(tt.line), &msg); err != nil {
+				t.Fatal(err)
+			}
+			if got := msg.Kind(); got != tt.want {
+				t.Errorf("Kind() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSessionFromReaderErrors(t *testing.T) {
+	input := `{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":5,"cache_read_input_tokens":1000}}}
+{"type":"assistant","isApiErrorMessage":true,"message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"API Error: 500"}],"usage":{"input_tokens":0}}}
+{"type":"user","message":{"role":"user","content":[{"type":"text","text":"[Request interrupted by user]"}]}}
+{"type":"assistant","message":{"role":"assistant","model":"<synthetic>","usage":{"input_tokens":7}}}
+{"type":"assistant","isApiErrorMessage":true,"message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"API Error: 529"}]}}`
+
+	got, err := parseSessionFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseSessionFromReader() error = %v", err)
+	}
+	if got.APIErrors != 2 || got.Interruptions != 1 {
+		t.Errorf("APIErrors, Interruptions = %d, %d, want 2, 1", got.APIErrors, got.Interruptions)
+	}
+	if got.UsageLine != 1 || got.Model != "claude-sonnet-4-5" || got.Usage.CacheReadInputTokens != 1000 {
+		t.Errorf("UsageLine, Model, Usage = %d, %q, %+v, want the last real response", got.UsageLine, got.Model, got.Usage)
+	}
+
+	turns, err := ReadTurns(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadTurns() error = %v", err)
+	}
+	kinds := make([]string, len(turns))
+	for i, turn := range turns {
+		kinds[i] = turn.Kind
+	}
+	if want := "usage api_error interrupted synthetic api_error"; strings.Join(kinds, " ") != want {
+		t.Errorf("turn kinds = %v, want %s", kinds, want)
+	}
+	if !turns[0].StatusLine || turns[3].Usage != nil {
+		t.Errorf("turns = %+v, want the status line on the first and no usage on the synthetic entry", turns)
+	}
+}
@@ -3,12 +3,9 @@ package parser
 import (
 	"bufio"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
-	"strings"
+	"time"
 )
 
 // Usage represents token usage statistics from Claude API
@@ -22,42 +19,81 @@ type Usage struct {
 
 // Message represents a single message in the JSONL transcript
 type Message struct {
-	Message struct {
+	Type             string `json:"type"`
+	Subtype          string `json:"subtype"`
+	Timestamp        string `json:"timestamp"`
+	UUID             string `json:"uuid"`
+	SessionID        string `json:"sessionId"`
+	Cwd              string `json:"cwd"`
+	RequestID        string `json:"requestId"`
+	IsSidechain      bool   `json:"isSidechain"`
+	IsCompactSummary bool   `json:"isCompactSummary"`
+	// IsAPIErrorMessage marks assistant messages that record a failed API request
+	IsAPIErrorMessage bool `json:"isApiErrorMessage"`
+	Message           struct {
+		ID    string `json:"id"`
 		Role  string `json:"role"`
+		Model string `json:"model"`
 		Usage Usage  `json:"usage"`
+		// Content is kept raw, it is only decoded to classify entries
+		Content json.RawMessage `json:"content"`
 	} `json:"message"`
 }
 
+// Session summarizes a transcript: the latest usage plus entry timestamps
+type Session struct {
+	// Usage is the last message usage data, zero usage when none was found
+	Usage *Usage
+	// FirstTimestamp and LastTimestamp bound all timestamped entries
+	FirstTimestamp time.Time
+	LastTimestamp  time.Time
+	// LastAssistantTimestamp is when the last assistant message arrived
+	LastAssistantTimestamp time.Time
+	// Timestamps holds every entry timestamp in file order
+	Timestamps []time.Time
+	// Path is the resolved transcript path, empty when parsed from a reader
+	Path string
+	// Lines counts the scanned lines, Skipped those that were not valid JSON
+	Lines   int
+	Skipped int
+	// UsageLine is the line number Usage was taken from, 0 when none was found
+	UsageLine int
+	// Model is the model of the entry Usage was taken from
+	Model string
+	// APIErrors counts failed API requests, Interruptions the requests the user cancelled
+	APIErrors     int
+	Interruptions int
+}
+
 // ParseTranscript reads a JSONL transcript file and returns the last message usage data
 // Returns error if file cannot be read or no messages with usage found
 func ParseTranscript(transcriptPath string) (*Usage, error) {
-	if transcriptPath == "" {
-		return nil, errors.New("transcript path is empty")
-	}
-
-	// resolve to absolute path to prevent path traversal
-	absPath, err := filepath.Abs(transcriptPath)
-	if err != nil {
-		return nil, fmt.Errorf("invalid path: %w", err)
-	}
-
-	// check for suspicious patterns (parent directory references)
-	if strings.Contains(filepath.ToSlash(absPath), "..") {
-		return nil, errors.New("invalid path: contains parent directory references")
-	}
-
-	file, err := os.Open(absPath)
+	session, err := ParseSession(transcriptPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open transcript: %w", err)
+		return nil, err
 	}
-	defer file.Close()
+	return session.Usage, nil
+}
 
-	return parseTranscriptFromReader(file)
+// ParseSession reads a JSONL transcript file and returns usage and timing data
+// the file may be anywhere, see PathPolicy.ParseSession to restrict it
+func ParseSession(transcriptPath string) (*Session, error) {
+	return DefaultPolicy.ParseSession(transcriptPath)
 }
 
 // parseTranscriptFromReader parses transcript from io.Reader
 // separated for testing purposes
 func parseTranscriptFromReader(r io.Reader) (*Usage, error) {
+	session, err := parseSessionFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return session.Usage, nil
+}
+
+// parseSessionFromReader parses transcript from io.Reader
+func parseSessionFromReader(r io.Reader) (*Session, error) {
+	session := &Session{}
 	var lastUsage *Usage
 	scanner := bufio.NewScanner(r)
 
@@ -66,18 +102,38 @@ func parseTranscriptFromReader(r io.Reader) (*Usage, error) {
 	scanner.Buffer(buf, 1024*1024)
 
 	for scanner.Scan() {
+		session.Lines++
 		var msg Message
 		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
 			// skip malformed lines
+			session.Skipped++
 			continue
 		}
 
+		if ts, ok := parseTimestamp(msg.Timestamp); ok {
+			session.addTimestamp(ts)
+			if msg.Message.Role == "assistant" {
+				session.LastAssistantTimestamp = ts
+			}
+		}
+
+		kind := msg.Kind()
+		switch kind {
+		case EntryAPIError:
+			session.APIErrors++
+		case EntryInterrupted:
+			session.Interruptions++
+		}
+
 		// accept any message with usage data, regardless of role
-		// this catches user prompts and tool calls that may have usage info
-		if msg.Message.Role != "" && hasValidUsage(&msg.Message.Usage) {
+		// this catches user prompts and tool calls that may have usage info,
+		// synthetic and errored entries are never used for context
+		if msg.Message.Role != "" && kind == EntryUsage {
 			// copy to avoid pointer to loop variable issue
 			usageCopy := msg.Message.Usage
 			lastUsage = &usageCopy
+			session.UsageLine = session.Lines
+			session.Model = msg.Message.Model
 		}
 	}
 
@@ -87,15 +143,39 @@ func parseTranscriptFromReader(r io.Reader) (*Usage, error) {
 
 	if lastUsage == nil {
 		// return zero usage instead of error for empty transcripts
-		return &Usage{
+		lastUsage = &Usage{
 			InputTokens:              0,
 			CacheReadInputTokens:     0,
 			CacheCreationInputTokens: 0,
 			OutputTokens:             0,
-		}, nil
+		}
+	}
+	session.Usage = lastUsage
+
+	return session, nil
+}
+
+// addTimestamp records an entry timestamp and widens the session bounds
+func (s *Session) addTimestamp(ts time.Time) {
+	s.Timestamps = append(s.Timestamps, ts)
+	if s.FirstTimestamp.IsZero() || ts.Before(s.FirstTimestamp) {
+		s.FirstTimestamp = ts
 	}
+	if ts.After(s.LastTimestamp) {
+		s.LastTimestamp = ts
+	}
+}
 
-	return lastUsage, nil
+// parseTimestamp parses RFC 3339 entry timestamps, missing or malformed values are ignored
+func parseTimestamp(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	ts, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
 }
 
 // hasValidUsage checks if usage struct contains meaningful data
@@ -1,8 +1,12 @@
 package parser
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseTranscriptFromReader(t *testing.T) {
@@ -118,20 +122,34 @@ not json at all`,
 
 func TestParseTranscript(t *testing.T) {
 	tests := []struct {
-		name    string
-		path    string
-		wantErr bool
+		name        string
+		path        string
+		wantErr     bool
+		wantInvalid bool
 	}{
 		{
-			name:    "empty path",
-			path:    "",
-			wantErr: true,
+			name:        "empty path",
+			path:        "",
+			wantErr:     true,
+			wantInvalid: true,
 		},
 		{
 			name:    "non-existent file",
 			path:    "/nonexistent/path/file.jsonl",
 			wantErr: true,
 		},
+		{
+			// a name containing .. is not a parent reference, it only does not exist
+			name:    "dots in a directory name",
+			path:    "/tmp/a..b/file.jsonl",
+			wantErr: true,
+		},
+		{
+			name:        "directory",
+			path:        "/tmp",
+			wantErr:     true,
+			wantInvalid: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -140,6 +158,9 @@ func TestParseTranscript(t *testing.T) {
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ParseTranscript() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if errors.Is(err, ErrInvalidPath) != tt.wantInvalid {
+				t.Errorf("ParseTranscript() error = %v, want ErrInvalidPath %v", err, tt.wantInvalid)
+			}
 		})
 	}
 }
@@ -177,4 +198,79 @@ func TestParseTranscriptZeroUsage(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestParseSessionFromReaderTimestamps(t *testing.T) {
+	input := `{"type":"summary","summary":"earlier work"}
+{"type":"user","timestamp":"2025-10-01T10:00:00.000Z","message":{"role":"user","content":"hi"}}
+{"type":"assistant","timestamp":"2025-10-01T10:00:05.500Z","message":{"role":"assistant","usage":{"input_tokens":5,"cache_read_input_tokens":100,"output_tokens":10}}}
+{"type":"user","timestamp":"not a timestamp","message":{"role":"user","content":"skipped timestamp"}}
+{"type":"user","timestamp":"2025-10-01T11:12:00Z","message":{"role":"user","content":"later"}}`
+
+	got, err := parseSessionFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseSessionFromReader() error = %v", err)
+	}
+
+	wantFirst := time.Date(2025, 10, 1, 10, 0, 0, 0, time.UTC)
+	wantAssistant := time.Date(2025, 10, 1, 10, 0, 5, 500000000, time.UTC)
+	wantLast := time.Date(2025, 10, 1, 11, 12, 0, 0, time.UTC)
+
+	if !got.FirstTimestamp.Equal(wantFirst) {
+		t.Errorf("FirstTimestamp = %v, want %v", got.FirstTimestamp, wantFirst)
+	}
+	if !got.LastTimestamp.Equal(wantLast) {
+		t.Errorf("LastTimestamp = %v, want %v", got.LastTimestamp, wantLast)
+	}
+	if !got.LastAssistantTimestamp.Equal(wantAssistant) {
+		t.Errorf("LastAssistantTimestamp = %v, want %v", got.LastAssistantTimestamp, wantAssistant)
+	}
+	if len(got.Timestamps) != 3 {
+		t.Errorf("len(Timestamps) = %d, want 3", len(got.Timestamps))
+	}
+	if got.Usage == nil || got.Usage.CacheReadInputTokens != 100 {
+		t.Errorf("Usage = %+v, want last assistant usage", got.Usage)
+	}
+}
+
+func TestParseSessionFromReaderNoTimestamps(t *testing.T) {
+	got, err := parseSessionFromReader(strings.NewReader(`{"message":{"role":"user","content":"hi"}}`))
+	if err != nil {
+		t.Fatalf("parseSessionFromReader() error = %v", err)
+	}
+	if !got.FirstTimestamp.IsZero() || !got.LastTimestamp.IsZero() || !got.LastAssistantTimestamp.IsZero() {
+		t.Errorf("expected zero timestamps, got %+v", got)
+	}
+}
+
+func TestParseSessionFromReaderCounts(t *testing.T) {
+	input := `{"message":{"role":"assistant","usage":{"input_tokens":5}}}
+not json
+{"message":{"role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":7}}}
+{"message":{"role":"user","content":"hi"}}`
+
+	got, err := parseSessionFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseSessionFromReader() error = %v", err)
+	}
+	if got.Lines != 4 || got.Skipped != 1 || got.UsageLine != 3 {
+		t.Errorf("Lines, Skipped, UsageLine = %d, %d, %d, want 4, 1, 3", got.Lines, got.Skipped, got.UsageLine)
+	}
+	if got.Model != "claude-sonnet-4-5" {
+		t.Errorf("Model = %q, want the model of the usage entry", got.Model)
+	}
+}
+
+func TestParseSessionPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(`{"message":{"role":"user","content":"hi"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseSession(path)
+	if err != nil {
+		t.Fatalf("ParseSession() error = %v", err)
+	}
+	if got.Path != path || got.UsageLine != 0 {
+		t.Errorf("Path, UsageLine = %q, %d, want %q, 0", got.Path, got.UsageLine, path)
+	}
+}
@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidPath is returned for transcripts rejected by the path policy before opening
+var ErrInvalidPath = errors.New("invalid path")
+
+// DefaultMaxSize is the largest transcript DefaultPolicy opens
+const DefaultMaxSize = 1 << 30
+
+// DefaultPolicy allows transcripts anywhere, for paths given explicitly on the command line
+var DefaultPolicy = PathPolicy{MaxSize: DefaultMaxSize}
+
+// PathPolicy decides which transcript files may be opened
+type PathPolicy struct {
+	// Roots lists the directories a transcript must resolve under after following
+	// symlinks, empty allows every location unless Strict is set; missing roots are ignored
+	Roots []string
+	// Strict rejects every path when no root is listed, for paths that come from
+	// a payload rather than the command line
+	Strict bool
+	// MaxSize rejects larger files, 0 disables the limit
+	MaxSize int64
+}
+
+// ParseSession validates the transcript against the policy and returns usage and timing data
+// gzip and zstd compressed transcripts are decompressed on the fly
+func (p PathPolicy) ParseSession(transcriptPath string) (*Session, error) {
+	return p.ParseSessionContext(context.Background(), transcriptPath)
+}
+
+// ParseSessionContext is ParseSession that stops reading once ctx is done
+func (p PathPolicy) ParseSessionContext(ctx context.Context, transcriptPath string) (*Session, error) {
+	file, err := p.Open(transcriptPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, _, err := Decompress(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	session, err := parseSessionFromReader(ContextReader(ctx, reader))
+	if err != nil {
+		return nil, err
+	}
+	session.Path = file.Name()
+	return session, nil
+}
+
+// ContextReader returns a reader that fails with the context error once ctx is done,
+// so that abandoned reads of large or slow files stop at the next read call
+func ContextReader(ctx context.Context, r io.Reader) io.Reader {
+	return &contextReader{ctx: ctx, r: r}
+}
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// Open resolves the transcript path through symlinks, checks that it is a regular
+// file within the roots and the size limit, and opens the resolved file for reading
+func (p PathPolicy) Open(transcriptPath string) (*os.File, error) {
+	if transcriptPath == "" {
+		return nil, fmt.Errorf("%w: transcript path is empty", ErrInvalidPath)
+	}
+	absPath, err := filepath.Abs(transcriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPath, err)
+	}
+	resolved, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	if !p.allowed(resolved) {
+		return nil, fmt.Errorf("%w: %s is outside the allowed roots", ErrInvalidPath, resolved)
+	}
+
+	// checked before opening, opening a FIFO or a device may block or have side effects
+	fi, err := os.Stat(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	if err := p.checkFile(fi); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	// the file may have been replaced between the checks and opening it
+	if opened, err := file.Stat(); err != nil || !os.SameFile(fi, opened) {
+		file.Close()
+		return nil, fmt.Errorf("%w: transcript changed while opening", ErrInvalidPath)
+	}
+	return file, nil
+}
+
+// checkFile rejects anything but regular files within the size limit
+func (p PathPolicy) checkFile(fi os.FileInfo) error {
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("%w: transcript is not a regular file (%s)", ErrInvalidPath, fi.Mode().Type())
+	}
+	if p.MaxSize > 0 && fi.Size() > p.MaxSize {
+		return fmt.Errorf("%w: transcript is %d bytes, the limit is %d", ErrInvalidPath, fi.Size(), p.MaxSize)
+	}
+	return nil
+}
+
+// allowed reports whether the resolved path lies within one of the roots,
+// roots are resolved through symlinks as well
+func (p PathPolicy) allowed(resolved string) bool {
+	if len(p.Roots) == 0 {
+		return !p.Strict
+	}
+	for _, root := range p.Roots {
+		root, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		root, err = filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		if within(resolved, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether path is root or below it, both must be clean absolute paths
+// names that merely start with .. such as "..notes" are not parent references
+func within(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
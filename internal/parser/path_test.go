@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestPathPolicyOpen(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	write := func(path, content string) string {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	symlink := func(target, link string) string {
+		t.Helper()
+		if err := os.Symlink(target, link); err != nil {
+			t.Fatal(err)
+		}
+		return link
+	}
+
+	inside := write(filepath.Join(root, "-work-app", "s1.jsonl"), "{}\n")
+	secret := write(filepath.Join(outside, "secret.jsonl"), "{}\n")
+	odd := write(filepath.Join(root, "..notes", "a..b", "s 2 ü.jsonl"), "{}\n")
+	large := write(filepath.Join(root, "large.jsonl"), "0123456789\n")
+	fifo := filepath.Join(root, "fifo.jsonl")
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Fatalf("mkfifo: %v", err)
+	}
+	// a symlinked root is resolved as well
+	linkedRoot := symlink(root, filepath.Join(outside, "linked-root"))
+
+	policy := PathPolicy{Roots: []string{linkedRoot, filepath.Join(outside, "missing")}, MaxSize: 10}
+	tests := []struct {
+		name        string
+		path        string
+		wantInvalid bool
+		wantMissing bool
+	}{
+		{name: "inside a root", path: inside},
+		{name: "odd names", path: odd},
+		{name: "relative traversal back inside", path: filepath.Join(root, "-work-app", "..", "-work-app", "s1.jsonl")},
+		{name: "symlink inside a root", path: symlink(inside, filepath.Join(root, "link.jsonl"))},
+		{name: "outside the roots", path: secret, wantInvalid: true},
+		{name: "traversal out of a root", path: filepath.Join(root, "..", filepath.Base(outside), "secret.jsonl"), wantInvalid: true},
+		{name: "symlink escape", path: symlink(secret, filepath.Join(root, "escape.jsonl")), wantInvalid: true},
+		{name: "symlinked directory escape", path: filepath.Join(symlink(outside, filepath.Join(root, "escape-dir")), "secret.jsonl"), wantInvalid: true},
+		{name: "fifo", path: fifo, wantInvalid: true},
+		{name: "directory", path: filepath.Join(root, "-work-app"), wantInvalid: true},
+		{name: "too large", path: large, wantInvalid: true},
+		{name: "dangling symlink", path: symlink(filepath.Join(root, "gone.jsonl"), filepath.Join(root, "dangling.jsonl")), wantMissing: true},
+		{name: "missing", path: filepath.Join(root, "missing.jsonl"), wantMissing: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := policy.Open(tt.path)
+			if err == nil {
+				file.Close()
+			}
+			if errors.Is(err, ErrInvalidPath) != tt.wantInvalid {
+				t.Errorf("Open() error = %v, want ErrInvalidPath %v", err, tt.wantInvalid)
+			}
+			if errors.Is(err, fs.ErrNotExist) != tt.wantMissing {
+				t.Errorf("Open() error = %v, want ErrNotExist %v", err, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestPathPolicyWithoutRoots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.jsonl")
+	if err := os.WriteFile(path, []byte(`{"message":{"role":"assistant","usage":{"input_tokens":5}}}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	session, err := PathPolicy{}.ParseSession(path)
+	if err != nil {
+		t.Fatalf("ParseSession() error = %v", err)
+	}
+	if session.Usage.InputTokens != 5 {
+		t.Errorf("Usage = %+v, want the transcript usage", session.Usage)
+	}
+	if _, err := (PathPolicy{Roots: []string{t.TempDir()}}).ParseTurns(path); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("ParseTurns() error = %v, want ErrInvalidPath", err)
+	}
+	// a strict policy fails closed when there is no root to allow
+	if _, err := (PathPolicy{Strict: true}).ParseSession(path); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("strict ParseSession() error = %v, want ErrInvalidPath", err)
+	}
+}
+
+func TestParseContextCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.jsonl")
+	if err := os.WriteFile(path, []byte(`{"message":{"role":"assistant","usage":{"input_tokens":5}}}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// an abandoned parse stops at the next read instead of reading the whole file
+	if _, err := DefaultPolicy.ParseSessionContext(ctx, path); !errors.Is(err, context.Canceled) {
+		t.Errorf("ParseSessionContext() error = %v, want context.Canceled", err)
+	}
+	if _, err := DefaultPolicy.ParseTurnsContext(ctx, path); !errors.Is(err, context.Canceled) {
+		t.Errorf("ParseTurnsContext() error = %v, want context.Canceled", err)
+	}
+	if _, err := DefaultPolicy.ParseSessionContext(context.Background(), path); err != nil {
+		t.Errorf("ParseSessionContext() error = %v", err)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		path string
+		root string
+		want bool
+	}{
+		{"/a/b/c.jsonl", "/a/b", true},
+		{"/a/b", "/a/b", true},
+		{"/a/..b/c.jsonl", "/a", true},
+		{"/a/bc/d.jsonl", "/a/b", false},
+		{"/a/c.jsonl", "/a/b", false},
+	}
+	for _, tt := range tests {
+		if got := within(tt.path, tt.root); got != tt.want {
+			t.Errorf("within(%q, %q) = %v, want %v", tt.path, tt.root, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxReportedLines caps the malformed line numbers kept in LineStats
+const maxReportedLines = 10
+
+// LineStats counts transcript lines by how the parser treats them
+type LineStats struct {
+	Lines int
+	// Malformed lines are not valid JSON and are skipped by every reader
+	Malformed int
+	// MalformedLines holds the first malformed line numbers, 1-based
+	MalformedLines []int
+	// Oversized lines exceed the 1 MiB line limit
+	Oversized int
+	// WithUsage counts entries that carry usage data
+	WithUsage int
+	// Partial is set when the last line has no newline, usually a write in progress
+	Partial bool
+}
+
+// ReadLineStats classifies every line of a transcript
+func ReadLineStats(r io.Reader) (LineStats, error) {
+	var stats LineStats
+	br := bufio.NewReaderSize(r, 64*1024)
+
+	for lineNo := 1; ; lineNo++ {
+		line, n, err := readLine(br)
+		eof := errors.Is(err, io.EOF)
+		if err != nil && !eof {
+			return stats, fmt.Errorf("error reading transcript: %w", err)
+		}
+		if eof && n == 0 {
+			return stats, nil
+		}
+
+		stats.Lines++
+		stats.Partial = eof
+		if n > maxLineSize {
+			stats.Oversized++
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			var msg Message
+			if err := json.Unmarshal(line, &msg); err != nil {
+				stats.Malformed++
+				if len(stats.MalformedLines) < maxReportedLines {
+					stats.MalformedLines = append(stats.MalformedLines, lineNo)
+				}
+			} else if msg.Kind() == EntryUsage {
+				stats.WithUsage++
+			}
+		}
+		if eof {
+			return stats, nil
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadLineStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  LineStats
+	}{
+		{
+			name:  "empty",
+			input: "",
+			want:  LineStats{},
+		},
+		{
+			name: "mixed lines",
+			input: `{"type":"user","message":{"role":"user"}}
+not json
+{"type":"assistant","message":{"role":"assistant","usage":{"input_tokens":5}}}
+{"type":"assistant","message":{"role":"assistant","model":"<synthetic>","usage":{"input_tokens":5}}}
+
+{"broken":
+`,
+			want: LineStats{Lines: 6, Malformed: 2, MalformedLines: []int{2, 6}, WithUsage: 1},
+		},
+		{
+			name:  "partial last line",
+			input: "{\"type\":\"user\"}\n{\"type\":\"assis",
+			want:  LineStats{Lines: 2, Malformed: 1, MalformedLines: []int{2}, Partial: true},
+		},
+		{
+			name:  "oversized line",
+			input: `{"x":"` + strings.Repeat("a", maxLineSize) + "\"}\n",
+			want:  LineStats{Lines: 1, Malformed: 1, MalformedLines: []int{1}, Oversized: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadLineStats(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ReadLineStats() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadLineStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Turn is a single transcript entry as it appears in the file, used for timelines
+type Turn struct {
+	// Line is the 1-based line number in the transcript
+	Line      int       `json:"line"`
+	Timestamp time.Time `json:"timestamp,omitzero"`
+	SessionID string    `json:"session_id,omitempty"`
+	Cwd       string    `json:"cwd,omitempty"`
+	Type      string    `json:"type"`
+	Role      string    `json:"role,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	MessageID string    `json:"message_id,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	Sidechain bool      `json:"sidechain,omitempty"`
+	// Compaction marks compact boundaries and compacted conversation summaries
+	Compaction bool `json:"compaction,omitempty"`
+	// Kind is the entry classification, see Message.Kind
+	Kind string `json:"kind"`
+	// Usage is nil when the entry carries no usage data
+	Usage *Usage `json:"usage,omitempty"`
+	// StatusLine marks the entry whose usage ParseTranscript reports
+	StatusLine bool `json:"status_line,omitempty"`
+}
+
+// ParseTurns reads a JSONL transcript file and returns every well-formed entry
+// the file may be anywhere, see PathPolicy.ParseTurns to restrict it
+func ParseTurns(transcriptPath string) ([]Turn, error) {
+	return DefaultPolicy.ParseTurns(transcriptPath)
+}
+
+// ParseTurns validates the transcript against the policy and returns every well-formed entry
+// gzip and zstd compressed transcripts are decompressed on the fly
+func (p PathPolicy) ParseTurns(transcriptPath string) ([]Turn, error) {
+	return p.ParseTurnsContext(context.Background(), transcriptPath)
+}
+
+// ParseTurnsContext is ParseTurns that stops reading once ctx is done
+func (p PathPolicy) ParseTurnsContext(ctx context.Context, transcriptPath string) ([]Turn, error) {
+	file, err := p.Open(transcriptPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, _, err := Decompress(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ReadTurns(ContextReader(ctx, reader))
+}
+
+// ReadTurns returns every well-formed entry from r, skipping malformed lines
+func ReadTurns(r io.Reader) ([]Turn, error) {
+	var turns []Turn
+	statusLine := -1
+	br := bufio.NewReaderSize(r, 64*1024)
+
+	for lineNo := 1; ; lineNo++ {
+		line, _, err := readLine(br)
+		eof := errors.Is(err, io.EOF)
+		if err != nil && !eof {
+			return nil, fmt.Errorf("error reading transcript: %w", err)
+		}
+		// a final line without newline is still parsed, like ParseTranscript does
+		if eof && len(line) == 0 {
+			break
+		}
+
+		var msg Message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			if eof {
+				break
+			}
+			continue
+		}
+
+		turn := Turn{
+			Line:       lineNo,
+			SessionID:  msg.SessionID,
+			Cwd:        msg.Cwd,
+			Type:       msg.Type,
+			Role:       msg.Message.Role,
+			Model:      msg.Message.Model,
+			MessageID:  msg.Message.ID,
+			RequestID:  msg.RequestID,
+			Sidechain:  msg.IsSidechain,
+			Compaction: msg.IsCompactSummary || msg.Subtype == "compact_boundary",
+		}
+		if ts, ok := parseTimestamp(msg.Timestamp); ok {
+			turn.Timestamp = ts
+		}
+		kind := msg.Kind()
+		turn.Kind = kind.String()
+		if kind == EntryUsage {
+			usage := msg.Message.Usage
+			turn.Usage = &usage
+			// same selection rule as parseSessionFromReader
+			if msg.Message.Role != "" {
+				statusLine = len(turns)
+			}
+		}
+		turns = append(turns, turn)
+		if eof {
+			break
+		}
+	}
+
+	if statusLine >= 0 {
+		turns[statusLine].StatusLine = true
+	}
+	return turns, nil
+}
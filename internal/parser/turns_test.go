@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadTurns(t *testing.T) {
+	input := strings.Join([]string{
+		`{"type":"user","timestamp":"2025-10-01T10:00:00Z","message":{"role":"user","content":"hi"}}`,
+		`{"type":"assistant","timestamp":"2025-10-01T10:00:05Z","requestId":"r1","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":5,"cache_read_input_tokens":100,"output_tokens":10}}}`,
+		`garbage`,
+		`{"type":"assistant","timestamp":"2025-10-01T10:00:06Z","isSidechain":true,"message":{"id":"m2","role":"assistant","model":"claude-haiku-4-5","usage":{"input_tokens":50}}}`,
+		`{"type":"system","subtype":"compact_boundary","timestamp":"2025-10-01T10:30:00Z"}`,
+		`{"type":"user","isCompactSummary":true,"timestamp":"2025-10-01T10:30:01Z","message":{"role":"user","content":"summary"}}`,
+		`{"type":"assistant","timestamp":"2025-10-01T10:31:00Z","message":{"id":"m3","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":7,"cache_creation_input_tokens":3000}}}`,
+	}, "\n")
+
+	turns, err := ReadTurns(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadTurns() error = %v", err)
+	}
+	if len(turns) != 6 {
+		t.Fatalf("len(turns) = %d, want 6", len(turns))
+	}
+
+	tests := []struct {
+		index      int
+		line       int
+		wantUsage  bool
+		sidechain  bool
+		compaction bool
+		statusLine bool
+	}{
+		{index: 0, line: 1},
+		{index: 1, line: 2, wantUsage: true},
+		{index: 2, line: 4, wantUsage: true, sidechain: true},
+		{index: 3, line: 5, compaction: true},
+		{index: 4, line: 6, compaction: true},
+		{index: 5, line: 7, wantUsage: true, statusLine: true},
+	}
+	for _, tt := range tests {
+		got := turns[tt.index]
+		if got.Line != tt.line {
+			t.Errorf("turns[%d].Line = %d, want %d", tt.index, got.Line, tt.line)
+		}
+		if (got.Usage != nil) != tt.wantUsage {
+			t.Errorf("turns[%d].Usage = %+v, wantUsage %v", tt.index, got.Usage, tt.wantUsage)
+		}
+		if got.Sidechain != tt.sidechain || got.Compaction != tt.compaction || got.StatusLine != tt.statusLine {
+			t.Errorf("turns[%d] flags = sidechain %v compaction %v status %v", tt.index, got.Sidechain, got.Compaction, got.StatusLine)
+		}
+	}
+
+	if turns[1].Model != "claude-sonnet-4-5" || turns[1].MessageID != "m1" || turns[1].RequestID != "r1" {
+		t.Errorf("turns[1] = %+v", turns[1])
+	}
+}
+
+func TestReadTurnsStatusLineMatchesParser(t *testing.T) {
+	input := `{"message":{"role":"assistant","usage":{"input_tokens":9,"cache_read_input_tokens":2000}}}
+{"message":{"usage":{"input_tokens":1}}}
+{"message":{"role":"user","content":"x"}}`
+
+	turns, err := ReadTurns(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadTurns() error = %v", err)
+	}
+	usage, err := parseTranscriptFromReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var marked *Turn
+	for i := range turns {
+		if turns[i].StatusLine {
+			marked = &turns[i]
+		}
+	}
+	if marked == nil || !reflect.DeepEqual(marked.Usage, usage) {
+		t.Errorf("status line turn = %+v, want usage %+v", marked, usage)
+	}
+}
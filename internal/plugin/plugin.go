@@ -0,0 +1,119 @@
+package plugin
+
+import (
+	"bytes"
+	"ccstatus/internal/state"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a plugin without a configured timeout
+const DefaultTimeout = 200 * time.Millisecond
+
+// maxOutput caps how much plugin output is read, only the first line is used
+const maxOutput = 64 * 1024
+
+// Plugin is a segment rendered by an external command
+type Plugin struct {
+	Name string
+	// Command is run with sh -c and the status JSON on stdin
+	Command string
+	// Dir is the working directory of the command, empty inherits ours
+	Dir     string
+	Timeout time.Duration
+	// TTL reuses the previous result, successful or not, for this long; 0 runs on every update
+	TTL time.Duration
+}
+
+// Run executes the plugin and returns the first line of its output
+// a non-zero exit status, a timeout or empty output is an error
+func (p *Plugin) Run(ctx context.Context, input []byte) (string, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", p.Command)
+	cmd.Dir = p.Dir
+	cmd.Stdin = bytes.NewReader(input)
+	// children of the shell may keep stdout open after it was killed
+	cmd.WaitDelay = 50 * time.Millisecond
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &limitedWriter{w: &stdout, n: maxOutput}
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("plugin %s: %w", p.Name, ctx.Err())
+		}
+		return "", fmt.Errorf("plugin %s: %w", p.Name, err)
+	}
+
+	line, _, _ := strings.Cut(stdout.String(), "\n")
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", fmt.Errorf("plugin %s: empty output", p.Name)
+	}
+	return line, nil
+}
+
+// cachedResult is the on-disk cache record of one plugin run
+type cachedResult struct {
+	Output string `json:"output"`
+	Error  string `json:"error,omitempty"`
+}
+
+// CachedRun is like Run but reuses a result younger than TTL kept in store
+// scope separates results that depend on where ccstatus runs, e.g. the working directory
+func (p *Plugin) CachedRun(ctx context.Context, store *state.Store, scope string, input []byte) (string, error) {
+	if p.TTL <= 0 {
+		return p.Run(ctx, input)
+	}
+
+	// the command is part of the key so editing the config takes effect at once
+	key := "plugin-" + p.Name + "\x00" + p.Command + "\x00" + scope
+	var cached cachedResult
+	if store.LoadFresh(key, p.TTL, &cached) {
+		if cached.Error != "" {
+			return "", errors.New(cached.Error)
+		}
+		return cached.Output, nil
+	}
+
+	output, err := p.Run(ctx, input)
+	if ctx.Err() != nil {
+		// the caller gave up, that says nothing about the plugin
+		return output, err
+	}
+	result := cachedResult{Output: output}
+	if err != nil {
+		// failures are cached too, a broken plugin must not cost its timeout on every update
+		result.Error = err.Error()
+	}
+	// a failed cache write only costs speed on the next run
+	_ = store.Save(key, result)
+	return output, err
+}
+
+// limitedWriter discards everything after n bytes without failing the command
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.n > 0 {
+		chunk := p[:min(len(p), l.n)]
+		l.n -= len(chunk)
+		if _, err := l.w.Write(chunk); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
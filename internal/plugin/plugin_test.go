@@ -0,0 +1,108 @@
+package plugin
+
+import (
+	"ccstatus/internal/state"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		timeout time.Duration
+		want    string
+		wantErr string
+	}{
+		{name: "first line", command: "printf 'ci: ok\\nmore\\n'", want: "ci: ok"},
+		{name: "reads stdin", command: "sed 's/.*\"ticket\":\"\\([^\"]*\\)\".*/\\1/'", want: "ABC-123"},
+		{name: "failure", command: "echo broken; exit 3", wantErr: "exit status 3"},
+		{name: "empty output", command: "true", wantErr: "empty output"},
+		{name: "timeout", command: "sleep 5", timeout: 50 * time.Millisecond, wantErr: "deadline exceeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{Name: "test", Command: tt.command, Timeout: tt.timeout}
+			start := time.Now()
+			got, err := p.Run(context.Background(), []byte(`{"ticket":"ABC-123"}`))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Run() error = %v, want %q", err, tt.wantErr)
+				}
+				if elapsed := time.Since(start); elapsed > time.Second {
+					t.Errorf("Run() took %v, want the timeout to stop it", elapsed)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Run() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunDir(t *testing.T) {
+	dir := t.TempDir()
+	p := &Plugin{Name: "pwd", Command: "pwd", Dir: dir}
+	got, err := p.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := filepath.EvalSymlinks(dir); got != dir && got != want {
+		t.Errorf("Run() = %q, want %q", got, dir)
+	}
+}
+
+func TestCachedRun(t *testing.T) {
+	dir := t.TempDir()
+	store := state.New(filepath.Join(dir, "state"))
+	counter := filepath.Join(dir, "runs")
+	// the stub counts its runs and reports the count
+	p := &Plugin{Name: "count", Command: "echo x >> " + counter + "; wc -l < " + counter, TTL: time.Minute}
+
+	for range 2 {
+		got, err := p.CachedRun(context.Background(), store, "/work/app", nil)
+		if err != nil || got != "1" {
+			t.Fatalf("CachedRun() = %q, %v, want the first result", got, err)
+		}
+	}
+	// another scope runs the command again
+	if got, _ := p.CachedRun(context.Background(), store, "/work/other", nil); got != "2" {
+		t.Errorf("CachedRun() other scope = %q, want 2", got)
+	}
+
+	// failures are cached as well
+	broken := &Plugin{Name: "broken", Command: "echo x >> " + counter + "; exit 1", TTL: time.Minute}
+	for range 2 {
+		if _, err := broken.CachedRun(context.Background(), store, "/work/app", nil); err == nil {
+			t.Fatal("CachedRun() error = nil, want error")
+		}
+	}
+	data, _ := os.ReadFile(counter)
+	if runs := strings.Count(string(data), "x"); runs != 3 {
+		t.Errorf("commands ran %d times, want 3", runs)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p := &Plugin{Name: "late", Command: "echo late", TTL: time.Minute}
+	if _, err := p.Run(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+
+	// giving up is not a plugin failure and is not cached
+	store := state.New(t.TempDir())
+	if _, err := p.CachedRun(ctx, store, "", nil); err == nil {
+		t.Fatal("CachedRun() error = nil, want error")
+	}
+	if got, err := p.CachedRun(context.Background(), store, "", nil); err != nil || got != "late" {
+		t.Errorf("CachedRun() after cancel = %q, %v, want a fresh run", got, err)
+	}
+}
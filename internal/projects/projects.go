@@ -0,0 +1,239 @@
+package projects
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EnvConfigDir is the Claude Code config dir override, may hold a comma-separated list
+const EnvConfigDir = "CLAUDE_CONFIG_DIR"
+
+// Transcript is a transcript file found under a projects directory
+type Transcript struct {
+	Path string
+	// Project is the encoded project directory name, e.g. -home-me-work-app
+	Project string
+	Size    int64
+	ModTime time.Time
+}
+
+// Dirs returns the existing Claude Code projects directories
+// CLAUDE_CONFIG_DIR is honored, otherwise ~/.config/claude and ~/.claude are checked
+func Dirs() []string {
+	var roots []string
+	if env := os.Getenv(EnvConfigDir); env != "" {
+		for _, root := range strings.Split(env, ",") {
+			if root = strings.TrimSpace(root); root != "" {
+				roots = append(roots, root)
+			}
+		}
+	} else if home, err := os.UserHomeDir(); err == nil {
+		roots = []string{
+			filepath.Join(home, ".config", "claude"),
+			filepath.Join(home, ".claude"),
+		}
+	}
+
+	var dirs []string
+	for _, root := range roots {
+		dir := filepath.Join(root, "projects")
+		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// transcriptExtensions are the file name suffixes of live and archived transcripts
+var transcriptExtensions = []string{".jsonl", ".jsonl.gz", ".jsonl.zst"}
+
+// IsTranscript reports whether path is named like a transcript, archives included
+func IsTranscript(path string) bool {
+	for _, ext := range transcriptExtensions {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionID returns the session id a transcript is named after: its base name
+// without the transcript extension, archives included
+func SessionID(path string) string {
+	name := filepath.Base(path)
+	for _, ext := range transcriptExtensions {
+		if trimmed, ok := strings.CutSuffix(name, ext); ok {
+			return trimmed
+		}
+	}
+	return name
+}
+
+// List returns every transcript under dirs, archives included, modified at or after since,
+// newest first; a zero since lists everything
+// unreadable subdirectories are skipped rather than failing the whole walk
+func List(dirs []string, since time.Time) ([]Transcript, error) {
+	var transcripts []Transcript
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == dir {
+					return err
+				}
+				return fs.SkipDir
+			}
+			if d.IsDir() || !IsTranscript(path) {
+				return nil
+			}
+			fi, err := d.Info()
+			if err != nil || !fi.Mode().IsRegular() {
+				return nil
+			}
+			if !since.IsZero() && fi.ModTime().Before(since) {
+				return nil
+			}
+			transcripts = append(transcripts, Transcript{
+				Path:    path,
+				Project: projectName(dir, path),
+				Size:    fi.Size(),
+				ModTime: fi.ModTime(),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(transcripts, func(i, j int) bool {
+		return transcripts[i].ModTime.After(transcripts[j].ModTime)
+	})
+	return transcripts, nil
+}
+
+// Sessions returns the transcripts that are the live file of a session, <project>/<id>.jsonl,
+// leaving out archives and transcripts nested below a session such as subagent sidechains
+func Sessions(transcripts []Transcript) []Transcript {
+	var sessions []Transcript
+	for _, transcript := range transcripts {
+		if transcript.Project != "" && filepath.Ext(transcript.Path) == ".jsonl" &&
+			filepath.Base(filepath.Dir(transcript.Path)) == transcript.Project {
+			sessions = append(sessions, transcript)
+		}
+	}
+	return sessions
+}
+
+// projectName returns the first path component below the projects dir
+func projectName(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return ""
+	}
+	first, _, found := strings.Cut(filepath.ToSlash(rel), "/")
+	if !found {
+		return ""
+	}
+	return first
+}
+
+// ErrSessionNotFound is returned when no transcript matches a session id
+var ErrSessionNotFound = errors.New("session not found")
+
+// FindSession returns the transcript of a session id, named <session-id>.jsonl
+// inside one of the project directories; an archived session is found as well
+func FindSession(dirs []string, sessionID string) (string, error) {
+	// the id is matched with a glob, its metacharacters would pick some other session
+	if sessionID == "" || strings.ContainsAny(sessionID, `/\*?[`) {
+		return "", fmt.Errorf("invalid session id %q", sessionID)
+	}
+	for _, ext := range transcriptExtensions {
+		for _, dir := range dirs {
+			matches, err := filepath.Glob(filepath.Join(dir, "*", sessionID+ext))
+			if err != nil {
+				return "", err
+			}
+			if len(matches) > 0 {
+				return matches[0], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+}
+
+// ErrNoTranscripts is returned when no transcript is left to fall back to
+var ErrNoTranscripts = errors.New("no transcripts found")
+
+// EncodeProject returns the directory name Claude Code keeps the transcripts of
+// a project under: the absolute path with every character but letters and digits replaced by -
+func EncodeProject(dir string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, dir)
+}
+
+// Latest returns the most recently modified transcript directly inside a project
+// directory, limited to the project of dir unless dir is empty
+// nested transcripts such as subagent sidechains and archives are never picked
+func Latest(dirs []string, dir string) (string, error) {
+	project := "*"
+	if dir != "" {
+		project = EncodeProject(dir)
+	}
+	var latest string
+	var latestTime time.Time
+	for _, root := range dirs {
+		matches, err := filepath.Glob(filepath.Join(root, project, "*.jsonl"))
+		if err != nil {
+			return "", err
+		}
+		for _, path := range matches {
+			fi, err := os.Stat(path)
+			if err != nil || !fi.Mode().IsRegular() {
+				continue
+			}
+			if latest == "" || fi.ModTime().After(latestTime) {
+				latest, latestTime = path, fi.ModTime()
+			}
+		}
+	}
+	if latest == "" {
+		return "", ErrNoTranscripts
+	}
+	return latest, nil
+}
+
+// Resolve finds a transcript for a status line run without a transcript path:
+// the session in the project of cwd, then the session in any project, then the
+// newest transcript of the project of cwd, and finally the newest transcript overall
+// empty sessionID or cwd skip their steps
+func Resolve(dirs []string, sessionID, cwd string) (string, error) {
+	if sessionID != "" && !strings.ContainsAny(sessionID, `/\`) {
+		if cwd != "" {
+			for _, root := range dirs {
+				path := filepath.Join(root, EncodeProject(cwd), sessionID+".jsonl")
+				if fi, err := os.Stat(path); err == nil && fi.Mode().IsRegular() {
+					return path, nil
+				}
+			}
+		}
+		if path, err := FindSession(dirs, sessionID); err == nil {
+			return path, nil
+		}
+	}
+	if cwd != "" {
+		if path, err := Latest(dirs, cwd); err == nil {
+			return path, nil
+		}
+	}
+	return Latest(dirs, "")
+}
@@ -0,0 +1,214 @@
+package projects
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTranscript(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirs(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(second, "projects"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// first has no projects dir and is skipped
+	t.Setenv(EnvConfigDir, first+", "+second)
+	got := Dirs()
+	if len(got) != 1 || got[0] != filepath.Join(second, "projects") {
+		t.Errorf("Dirs() = %v, want [%s]", got, filepath.Join(second, "projects"))
+	}
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeTranscript(t, filepath.Join(dir, "-work-app", "a.jsonl"), "{}\n", now.Add(-time.Hour))
+	writeTranscript(t, filepath.Join(dir, "-work-app", "b.jsonl"), "{}\n", now.Add(-10*time.Minute))
+	writeTranscript(t, filepath.Join(dir, "-work-lib", "c.jsonl"), "{}\n", now.Add(-48*time.Hour))
+	writeTranscript(t, filepath.Join(dir, "-work-lib", "notes.txt"), "x", now)
+	writeTranscript(t, filepath.Join(dir, "-work-lib", "d.jsonl.gz"), "x", now.Add(-72*time.Hour))
+	writeTranscript(t, filepath.Join(dir, "-work-lib", "e.jsonl.zst"), "x", now.Add(-96*time.Hour))
+	writeTranscript(t, filepath.Join(dir, "-work-lib", "f.tar.gz"), "x", now)
+
+	all, err := List([]string{dir}, time.Time{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(all) != 5 {
+		t.Fatalf("len(List()) = %d, want 5", len(all))
+	}
+	// newest first
+	if filepath.Base(all[0].Path) != "b.jsonl" || filepath.Base(all[2].Path) != "c.jsonl" || filepath.Base(all[4].Path) != "e.jsonl.zst" {
+		t.Errorf("List() order = %s, %s, %s", all[0].Path, all[1].Path, all[2].Path)
+	}
+	if all[0].Project != "-work-app" || all[2].Project != "-work-lib" {
+		t.Errorf("Project = %q, %q", all[0].Project, all[2].Project)
+	}
+
+	recent, err := List([]string{dir}, now.Add(-2*time.Hour))
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(recent) != 2 {
+		t.Errorf("len(List(since)) = %d, want 2", len(recent))
+	}
+
+	if _, err := List([]string{filepath.Join(dir, "missing")}, time.Time{}); err == nil {
+		t.Error("List() of missing dir error = nil")
+	}
+}
+
+func TestSessions(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeTranscript(t, filepath.Join(dir, "-work-app", "a.jsonl"), "{}\n", now)
+	writeTranscript(t, filepath.Join(dir, "-work-app", "a", "subagents", "agent-1.jsonl"), "{}\n", now)
+	writeTranscript(t, filepath.Join(dir, "-work-app", "old.jsonl.gz"), "x", now)
+	writeTranscript(t, filepath.Join(dir, "-work-lib", "b.jsonl"), "{}\n", now)
+	writeTranscript(t, filepath.Join(dir, "stray.jsonl"), "{}\n", now)
+
+	all, err := List([]string{dir}, time.Time{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var names []string
+	for _, transcript := range Sessions(all) {
+		names = append(names, filepath.Base(transcript.Path))
+	}
+	sort.Strings(names)
+	if got := strings.Join(names, " "); got != "a.jsonl b.jsonl" {
+		t.Errorf("Sessions() = %s, want a.jsonl b.jsonl", got)
+	}
+}
+
+func TestFindSession(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "-work-app", "af99e13e-377a-4064-ae40-3987bc91cdee.jsonl")
+	writeTranscript(t, path, "{}\n", time.Now())
+	archived := filepath.Join(dir, "-work-app", "00000000-0000-0000-0000-00000000000a.jsonl.zst")
+	writeTranscript(t, archived, "x", time.Now())
+
+	tests := []struct {
+		name    string
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{name: "existing session", id: "af99e13e-377a-4064-ae40-3987bc91cdee", want: path},
+		{name: "archived session", id: "00000000-0000-0000-0000-00000000000a", want: archived},
+		{name: "unknown session", id: "00000000-0000-0000-0000-000000000000", wantErr: true},
+		{name: "empty id", id: "", wantErr: true},
+		{name: "path separators rejected", id: "../-work-app/af99e13e-377a-4064-ae40-3987bc91cdee", wantErr: true},
+		{name: "wildcard rejected", id: "*", wantErr: true},
+		{name: "single character wildcard rejected", id: "af99e13e-377a-4064-ae40-3987bc91cde?", wantErr: true},
+		{name: "character class rejected", id: "[a]f99e13e-377a-4064-ae40-3987bc91cdee", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindSession([]string{dir}, tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FindSession() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionID(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/p/-work-app/af99e13e.jsonl", "af99e13e"},
+		{"/p/-work-app/af99e13e.jsonl.gz", "af99e13e"},
+		{"/p/-work-app/af99e13e.jsonl.zst", "af99e13e"},
+		{"/p/-work-app/notes.txt", "notes.txt"},
+	}
+	for _, tt := range tests {
+		if got := SessionID(tt.path); got != tt.want {
+			t.Errorf("SessionID(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeProject(t *testing.T) {
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"/home/me/work/app", "-home-me-work-app"},
+		{"/home/me/.config/my_app", "-home-me--config-my-app"},
+		{"C:\\Users\\me", "C--Users-me"},
+	}
+	for _, tt := range tests {
+		if got := EncodeProject(tt.dir); got != tt.want {
+			t.Errorf("EncodeProject(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	appSession := filepath.Join(dir, "-work-app", "s1.jsonl")
+	appLatest := filepath.Join(dir, "-work-app", "s2.jsonl")
+	otherSession := filepath.Join(dir, "-work-other", "s3.jsonl")
+	newest := filepath.Join(dir, "-work-newest", "s4.jsonl")
+	writeTranscript(t, appSession, "{}\n", now.Add(-3*time.Hour))
+	writeTranscript(t, appLatest, "{}\n", now.Add(-2*time.Hour))
+	writeTranscript(t, otherSession, "{}\n", now.Add(-time.Hour))
+	writeTranscript(t, newest, "{}\n", now.Add(-time.Minute))
+	// a subagent transcript is newer but never picked
+	writeTranscript(t, filepath.Join(dir, "-work-app", "s2", "subagents", "agent.jsonl"), "{}\n", now)
+
+	tests := []struct {
+		name      string
+		sessionID string
+		cwd       string
+		want      string
+	}{
+		{name: "session in the cwd project", sessionID: "s1", cwd: "/work/app", want: appSession},
+		{name: "session in another project", sessionID: "s3", cwd: "/work/app", want: otherSession},
+		{name: "unknown session falls back to the cwd project", sessionID: "missing", cwd: "/work/app", want: appLatest},
+		{name: "newest of the cwd project", cwd: "/work/app", want: appLatest},
+		{name: "unknown project falls back to the newest", cwd: "/work/unknown", want: newest},
+		{name: "nothing given", want: newest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve([]string{dir}, tt.sessionID, tt.cwd)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Resolve([]string{t.TempDir()}, "s1", "/work/app"); !errors.Is(err, ErrNoTranscripts) {
+		t.Errorf("Resolve() without transcripts error = %v, want ErrNoTranscripts", err)
+	}
+}
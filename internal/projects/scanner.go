@@ -0,0 +1,171 @@
+package projects
+
+import (
+	"bytes"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/state"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// scanKeyPrefix names the cache records, bumped whenever the entries a transcript
+// yields change so that older cached results are read again
+const scanKeyPrefix = "scan-v2-"
+
+// fingerprintSize is how many bytes at the start and before the resume offset
+// are kept to detect rewritten files
+const fingerprintSize = 64
+
+// Scanner reads usage entries from transcripts, reusing cached results for
+// unchanged files and parsing only the appended part of growing ones
+type Scanner struct {
+	// Store keeps per-file results between runs, nil disables caching
+	Store *state.Store
+}
+
+// scanRecord is the per-transcript cache record
+type scanRecord struct {
+	Size    int64          `json:"size"`
+	ModTime int64          `json:"mod_time"`
+	Offset  int64          `json:"offset"`
+	Head    []byte         `json:"head"`
+	Tail    []byte         `json:"tail"`
+	Entries []parser.Entry `json:"entries"`
+}
+
+// Entries returns usage entries from all transcripts, deduplicated and sorted by time
+// unreadable transcripts are skipped
+func (s *Scanner) Entries(transcripts []Transcript) []parser.Entry {
+	return s.EntriesContext(context.Background(), transcripts)
+}
+
+// EntriesContext is Entries that stops reading once ctx is done and returns
+// the entries of the transcripts read so far
+func (s *Scanner) EntriesContext(ctx context.Context, transcripts []Transcript) []parser.Entry {
+	var all []parser.Entry
+	seen := make(map[string]bool)
+
+	for _, transcript := range transcripts {
+		if ctx.Err() != nil {
+			break
+		}
+		entries, err := s.scanFile(ctx, transcript)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if key := entry.DedupKey(); key != "" {
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			all = append(all, entry)
+		}
+	}
+
+	slices.SortStableFunc(all, func(a, b parser.Entry) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return all
+}
+
+// scanFile returns the entries of a single transcript
+// a read cut short by ctx fails, so that a partial result is never cached
+func (s *Scanner) scanFile(ctx context.Context, transcript Transcript) ([]parser.Entry, error) {
+	key := scanKeyPrefix + transcript.Path
+	modTime := transcript.ModTime.UnixNano()
+
+	var cached scanRecord
+	hasCache := false
+	if s.Store != nil {
+		_, err := s.Store.Load(key, &cached)
+		hasCache = err == nil
+	}
+	if hasCache && cached.Size == transcript.Size && cached.ModTime == modTime {
+		return cached.Entries, nil
+	}
+
+	file, err := os.Open(transcript.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer file.Close()
+
+	record := scanRecord{Size: transcript.Size, ModTime: modTime}
+	if parser.DetectCompression(readAt(file, 0, 4)) != parser.CompressionNone {
+		// offsets into a compressed stream cannot be resumed from, archives are read whole
+		if record.Entries, err = readCompressed(ctx, file); err != nil {
+			return nil, err
+		}
+	} else if err := readAppended(ctx, file, &record, &cached, hasCache); err != nil {
+		return nil, err
+	}
+
+	if s.Store != nil {
+		// a failed cache write only costs speed on the next run
+		_ = s.Store.Save(key, record)
+	}
+	return record.Entries, nil
+}
+
+// readAppended parses the file from where the cached record stopped, or from the start
+// when the file was rewritten, and records the new offset and fingerprints
+func readAppended(ctx context.Context, file *os.File, record, cached *scanRecord, hasCache bool) error {
+	if hasCache && canResume(file, cached, record.Size) {
+		record.Offset = cached.Offset
+		record.Entries = cached.Entries
+	}
+
+	if _, err := file.Seek(record.Offset, io.SeekStart); err != nil {
+		return err
+	}
+	entries, consumed, err := parser.ReadUsageEntries(parser.ContextReader(ctx, file))
+	if err != nil {
+		return err
+	}
+	record.Entries = append(record.Entries, entries...)
+	record.Offset += consumed
+	record.Head = readAt(file, 0, min(fingerprintSize, record.Offset))
+	record.Tail = readTail(file, record.Offset)
+	return nil
+}
+
+// readCompressed returns the entries of an archived transcript
+func readCompressed(ctx context.Context, file *os.File) ([]parser.Entry, error) {
+	reader, _, err := parser.Decompress(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	// an archive is complete, its last line counts even without a trailing newline
+	entries, _, err := parser.ReadUsageEntries(parser.ContextReader(ctx, io.MultiReader(reader, strings.NewReader("\n"))))
+	return entries, err
+}
+
+// canResume reports whether the file still holds the previously parsed bytes
+// transcripts are append-only, so a file that shrunk, changed without growing,
+// or whose head or tail bytes differ was rewritten and must be parsed again
+func canResume(file *os.File, cached *scanRecord, size int64) bool {
+	if cached.Offset <= 0 || size <= cached.Offset {
+		return false
+	}
+	return bytes.Equal(readAt(file, 0, int64(len(cached.Head))), cached.Head) &&
+		bytes.Equal(readTail(file, cached.Offset), cached.Tail)
+}
+
+// readTail returns up to fingerprintSize bytes ending at offset
+func readTail(file *os.File, offset int64) []byte {
+	start := max(offset-fingerprintSize, 0)
+	return readAt(file, start, offset-start)
+}
+
+func readAt(file *os.File, offset, length int64) []byte {
+	buf := make([]byte, length)
+	n, _ := file.ReadAt(buf, offset)
+	return buf[:n]
+}
@@ -0,0 +1,161 @@
+package projects
+
+import (
+	"bytes"
+	"ccstatus/internal/state"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	lineA = `{"type":"assistant","timestamp":"2025-10-01T10:00:00Z","requestId":"r1","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":10}}}` + "\n"
+	lineB = `{"type":"assistant","timestamp":"2025-10-01T10:05:00Z","requestId":"r2","message":{"id":"m2","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":20}}}` + "\n"
+	lineC = `{"type":"assistant","timestamp":"2025-10-01T09:00:00Z","requestId":"r3","message":{"id":"m3","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":30}}}` + "\n"
+)
+
+func stat(t *testing.T, path string) Transcript {
+	t.Helper()
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Transcript{Path: path, Size: fi.Size(), ModTime: fi.ModTime()}
+}
+
+func TestScannerEntriesDedupAndSort(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	first := filepath.Join(dir, "p", "first.jsonl")
+	resumed := filepath.Join(dir, "p", "resumed.jsonl")
+	writeTranscript(t, first, lineA+lineB, now)
+	// resumed sessions repeat earlier messages
+	writeTranscript(t, resumed, lineA+lineC, now)
+
+	scanner := &Scanner{}
+	entries := scanner.Entries([]Transcript{stat(t, first), stat(t, resumed)})
+	if len(entries) != 3 {
+		t.Fatalf("len(Entries()) = %d, want 3", len(entries))
+	}
+	for i, want := range []string{"m3", "m1", "m2"} {
+		if entries[i].MessageID != want {
+			t.Errorf("entries[%d].MessageID = %q, want %q", i, entries[i].MessageID, want)
+		}
+	}
+}
+
+func TestScannerIncrementalCache(t *testing.T) {
+	dir := t.TempDir()
+	store := state.New(filepath.Join(t.TempDir(), "state"))
+	path := filepath.Join(dir, "p", "s.jsonl")
+	past := time.Now().Add(-time.Minute)
+	writeTranscript(t, path, lineA, past)
+
+	scanner := &Scanner{Store: store}
+	if got := scanner.Entries([]Transcript{stat(t, path)}); len(got) != 1 {
+		t.Fatalf("len(Entries()) = %d, want 1", len(got))
+	}
+
+	// append a line, only the new part has to be parsed
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(lineB); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	got := scanner.Entries([]Transcript{stat(t, path)})
+	if len(got) != 2 || got[1].MessageID != "m2" {
+		t.Fatalf("Entries() after append = %+v, want m1, m2", got)
+	}
+
+	var record scanRecord
+	if _, err := store.Load(scanKeyPrefix+path, &record); err != nil {
+		t.Fatalf("cache record missing: %v", err)
+	}
+	if record.Offset != int64(len(lineA+lineB)) || len(record.Entries) != 2 {
+		t.Errorf("record offset/entries = %d/%d", record.Offset, len(record.Entries))
+	}
+
+	// rewriting the file with different content of the same length forces a full parse
+	writeTranscript(t, path, lineC+lineB, time.Now())
+	got = scanner.Entries([]Transcript{stat(t, path)})
+	if len(got) != 2 || got[0].MessageID != "m3" {
+		t.Errorf("Entries() after rewrite = %+v, want m3, m2", got)
+	}
+}
+
+func TestScannerSkipsUnreadable(t *testing.T) {
+	scanner := &Scanner{}
+	entries := scanner.Entries([]Transcript{{Path: filepath.Join(t.TempDir(), "missing.jsonl")}})
+	if len(entries) != 0 {
+		t.Errorf("len(Entries()) = %d, want 0", len(entries))
+	}
+}
+
+func TestScannerEntriesContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "p", "s1.jsonl")
+	writeTranscript(t, path, lineA+lineB, time.Now())
+	store := state.New(filepath.Join(t.TempDir(), "state"))
+	scanner := &Scanner{Store: store}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if entries := scanner.EntriesContext(ctx, []Transcript{stat(t, path)}); len(entries) != 0 {
+		t.Errorf("len(EntriesContext()) after cancel = %d, want 0", len(entries))
+	}
+	// a read cut short by the context is not cached as the file's entries
+	if _, err := scanner.scanFile(ctx, stat(t, path)); !errors.Is(err, context.Canceled) {
+		t.Errorf("scanFile() error = %v, want context.Canceled", err)
+	}
+	var record scanRecord
+	if _, err := store.Load(scanKeyPrefix+path, &record); err == nil {
+		t.Errorf("cached record after cancel = %+v, want none", record)
+	}
+	if entries := scanner.Entries([]Transcript{stat(t, path)}); len(entries) != 2 {
+		t.Errorf("len(Entries()) = %d, want 2", len(entries))
+	}
+}
+
+func TestScannerCompressed(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	// the last line of an archive counts without a trailing newline
+	w.Write([]byte(lineA + strings.TrimSuffix(lineB, "\n")))
+	w.Close()
+	path := filepath.Join(dir, "p", "archived.jsonl.gz")
+	writeTranscript(t, path, gz.String(), now)
+	// a live transcript repeating an archived message is counted once
+	live := filepath.Join(dir, "p", "live.jsonl")
+	writeTranscript(t, live, lineA+lineC, now)
+
+	store := state.New(filepath.Join(t.TempDir(), "state"))
+	scanner := &Scanner{Store: store}
+	for range 2 {
+		entries := scanner.Entries([]Transcript{stat(t, path), stat(t, live)})
+		if len(entries) != 3 {
+			t.Fatalf("len(Entries()) = %d, want 3", len(entries))
+		}
+	}
+
+	// a recompressed archive is read whole again
+	var zst bytes.Buffer
+	encoder, _ := zstd.NewWriter(&zst)
+	encoder.Write([]byte(lineA + lineB + lineC))
+	encoder.Close()
+	writeTranscript(t, path, zst.String(), now.Add(time.Second))
+	if entries := scanner.Entries([]Transcript{stat(t, path)}); len(entries) != 3 {
+		t.Errorf("len(Entries()) after rewrite = %d, want 3", len(entries))
+	}
+}
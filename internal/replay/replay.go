@@ -0,0 +1,300 @@
+package replay
+
+import (
+	"bytes"
+	"ccstatus/internal/parser"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// files of a recording directory
+const (
+	InputFile      = "input.json"
+	TranscriptFile = "transcript.jsonl"
+	OutputFile     = "output.txt"
+	metaFile       = "meta.json"
+)
+
+// TailSize is how much of the transcript end a recording keeps
+// it covers the entries the status line reads; session duration and
+// block totals of a replay only see the kept part
+const TailSize = 1 << 20
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// Recording is one saved status line invocation
+type Recording struct {
+	Dir string
+	// Input is the raw stdin payload as Claude Code sent it
+	Input []byte
+	// Time is when the invocation happened
+	Time time.Time
+	// TranscriptPath is the original transcript path the status line read
+	TranscriptPath string
+	// Source is what the status line resolved the payload to, zero in older recordings
+	Source Source
+}
+
+// Source is the transcript and session a status line run resolved its payload to,
+// they differ from the payload when flags picked the transcript or it had none
+type Source struct {
+	SessionID      string `json:"session_id,omitempty"`
+	TranscriptPath string `json:"transcript_path,omitempty"`
+	Cwd            string `json:"cwd,omitempty"`
+	ProjectDir     string `json:"project_dir,omitempty"`
+}
+
+type meta struct {
+	Time           time.Time `json:"time"`
+	TranscriptPath string    `json:"transcript_path"`
+	// TailOffset is where the snapshot starts in the original transcript
+	TailOffset int64  `json:"tail_offset"`
+	Source     Source `json:"source"`
+}
+
+// Save writes a recording of one invocation under dir and returns its directory
+// the transcript of source, or else the one referenced by the payload, is snapshotted
+// from its last complete lines
+func Save(dir string, input, output []byte, source Source, now time.Time) (string, error) {
+	var payload struct {
+		SessionID      string `json:"session_id"`
+		TranscriptPath string `json:"transcript_path"`
+	}
+	// an undecodable payload is still worth keeping, it is what broke the run
+	_ = json.Unmarshal(input, &payload)
+	if source.SessionID != "" {
+		payload.SessionID = source.SessionID
+	}
+	if source.TranscriptPath != "" {
+		payload.TranscriptPath = source.TranscriptPath
+	}
+
+	name := now.UTC().Format("20060102-150405.000000")
+	if payload.SessionID != "" {
+		name += "-" + unsafeName.ReplaceAllString(payload.SessionID, "_")
+	}
+	recDir := filepath.Join(dir, name)
+	if err := os.MkdirAll(recDir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create recording dir: %w", err)
+	}
+
+	m := meta{Time: now, TranscriptPath: payload.TranscriptPath, Source: source}
+	var snapshotErr error
+	if payload.TranscriptPath != "" {
+		var tail []byte
+		tail, m.TailOffset, snapshotErr = readTail(payload.TranscriptPath, TailSize)
+		if snapshotErr == nil {
+			if err := os.WriteFile(filepath.Join(recDir, TranscriptFile), tail, 0o600); err != nil {
+				snapshotErr = fmt.Errorf("failed to write transcript snapshot: %w", err)
+			}
+		}
+	}
+
+	encodedMeta, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return recDir, err
+	}
+	for file, data := range map[string][]byte{InputFile: input, OutputFile: output, metaFile: encodedMeta} {
+		if err := os.WriteFile(filepath.Join(recDir, file), data, 0o600); err != nil {
+			return recDir, fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	}
+	// the payload is kept even when the transcript could not be snapshotted
+	return recDir, snapshotErr
+}
+
+// readTail returns up to size bytes from the end of path starting at a line boundary
+// and the offset of the first returned byte; the end is read directly when the file
+// is seekable, compressed transcripts are decompressed from the start instead
+func readTail(path string, size int64) ([]byte, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, 4)
+	n, _ := file.ReadAt(header, 0)
+	if parser.DetectCompression(header[:n]) != parser.CompressionNone {
+		return readStreamTail(file, size)
+	}
+
+	fi, err := file.Stat()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to stat transcript: %w", err)
+	}
+	offset := max(fi.Size()-size, 0)
+	data := make([]byte, fi.Size()-offset)
+	if _, err := file.ReadAt(data, offset); err != nil && !errors.Is(err, io.EOF) {
+		return nil, 0, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	data, offset = lineStart(data, offset)
+	return data, offset, nil
+}
+
+// readStreamTail keeps the last size bytes of the decompressed transcript,
+// the offset is into the decompressed content
+func readStreamTail(r io.Reader, size int64) ([]byte, int64, error) {
+	reader, _, err := parser.Decompress(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer reader.Close()
+
+	var data []byte
+	var offset int64
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := reader.Read(buf)
+		data = append(data, buf[:n]...)
+		if excess := int64(len(data)) - size; excess > 0 {
+			data = append(data[:0], data[excess:]...)
+			offset += excess
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read transcript: %w", err)
+		}
+	}
+	data, offset = lineStart(data, offset)
+	return data, offset, nil
+}
+
+// lineStart drops the line cut in half when data does not start at offset 0
+func lineStart(data []byte, offset int64) ([]byte, int64) {
+	if offset > 0 {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+			offset += int64(i + 1)
+		}
+	}
+	return data, offset
+}
+
+// Load reads a recording from its directory or from its input.json
+func Load(path string) (*Recording, error) {
+	dir := path
+	if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	rec := &Recording{Dir: dir}
+
+	input, err := os.ReadFile(filepath.Join(dir, InputFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	rec.Input = input
+
+	data, err := os.ReadFile(filepath.Join(dir, metaFile))
+	switch {
+	case err == nil:
+		var m meta
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse recording metadata: %w", err)
+		}
+		rec.Time, rec.TranscriptPath, rec.Source = m.Time, m.TranscriptPath, m.Source
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("failed to read recording metadata: %w", err)
+	}
+	return rec, nil
+}
+
+// Snapshot returns the saved transcript tail, nil when the payload had none
+func (r *Recording) Snapshot() ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(r.Dir, TranscriptFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Payload returns the recorded input with transcript_path pointed at transcriptPath
+// and the session and directories the run resolved, every other field is passed
+// through unchanged
+func (r *Recording) Payload(transcriptPath string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	// a run without payload, from a shell or with flags, only has the resolved source
+	if len(bytes.TrimSpace(r.Input)) > 0 {
+		if err := json.Unmarshal(r.Input, &fields); err != nil {
+			// replay undecodable input as is so the original error shows up
+			return r.Input, nil
+		}
+	}
+	if fields == nil {
+		fields = make(map[string]json.RawMessage)
+	}
+	set := func(name string, value any) error {
+		encoded, err := json.Marshal(value)
+		fields[name] = encoded
+		return err
+	}
+	if err := set("transcript_path", transcriptPath); err != nil {
+		return nil, err
+	}
+	if r.Source.SessionID != "" {
+		if err := set("session_id", r.Source.SessionID); err != nil {
+			return nil, err
+		}
+	}
+	if r.Source.Cwd != "" {
+		workspace := map[string]string{"current_dir": r.Source.Cwd, "project_dir": r.Source.ProjectDir}
+		if err := set("cwd", r.Source.Cwd); err != nil {
+			return nil, err
+		}
+		if err := set("workspace", workspace); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// Step is the transcript as it was right after one entry with usage was written
+type Step struct {
+	Line      int
+	Timestamp time.Time
+	// Transcript holds every line up to and including Line
+	Transcript []byte
+}
+
+// Steps splits a transcript into the states the status line could have seen,
+// one per entry that carries usage
+func Steps(transcript []byte) ([]Step, error) {
+	turns, err := parser.ReadTurns(bytes.NewReader(transcript))
+	if err != nil {
+		return nil, err
+	}
+
+	// byte offset just past each line, indexed by 1-based line number
+	ends := []int{0}
+	for offset := 0; offset < len(transcript); {
+		i := bytes.IndexByte(transcript[offset:], '\n')
+		if i < 0 {
+			ends = append(ends, len(transcript))
+			break
+		}
+		offset += i + 1
+		ends = append(ends, offset)
+	}
+
+	var steps []Step
+	for _, turn := range turns {
+		if turn.Usage == nil || turn.Line >= len(ends) {
+			continue
+		}
+		steps = append(steps, Step{
+			Line:       turn.Line,
+			Timestamp:  turn.Timestamp,
+			Transcript: transcript[:ends[turn.Line]],
+		})
+	}
+	return steps, nil
+}
@@ -0,0 +1,205 @@
+package replay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testTranscript = `{"type":"user","timestamp":"2025-10-01T10:00:00Z","message":{"role":"user"}}
+{"type":"assistant","timestamp":"2025-10-01T10:00:05Z","message":{"role":"assistant","usage":{"input_tokens":10}}}
+not json
+{"type":"assistant","timestamp":"2025-10-01T10:01:00Z","message":{"role":"assistant","usage":{"input_tokens":20}}}
+`
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	transcript := filepath.Join(dir, "session.jsonl")
+	if err := os.WriteFile(transcript, []byte(testTranscript), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	input := []byte(`{"session_id":"a/b","transcript_path":"` + transcript + `","model":{"id":"claude-sonnet-4-5"}}`)
+	now := time.Date(2025, 10, 1, 10, 2, 0, 0, time.UTC)
+
+	recDir, err := Save(filepath.Join(dir, "rec"), input, []byte("[ctx]"), Source{}, now)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if want := "20251001-100200.000000-a_b"; filepath.Base(recDir) != want {
+		t.Errorf("Save() dir = %q, want %q", filepath.Base(recDir), want)
+	}
+
+	rec, err := Load(filepath.Join(recDir, InputFile))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if string(rec.Input) != string(input) || !rec.Time.Equal(now) || rec.TranscriptPath != transcript {
+		t.Errorf("Load() = %+v", rec)
+	}
+	snapshot, err := rec.Snapshot()
+	if err != nil || string(snapshot) != testTranscript {
+		t.Errorf("Snapshot() = %q, %v, want full transcript", snapshot, err)
+	}
+	if output, _ := os.ReadFile(filepath.Join(recDir, OutputFile)); string(output) != "[ctx]" {
+		t.Errorf("output = %q, want [ctx]", output)
+	}
+}
+
+func TestSaveResolvedSource(t *testing.T) {
+	dir := t.TempDir()
+	transcript := filepath.Join(dir, "af99e13e.jsonl")
+	if err := os.WriteFile(transcript, []byte(testTranscript), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// a shell run with --project: no payload, the transcript was resolved from the flag
+	source := Source{SessionID: "af99e13e", TranscriptPath: transcript, Cwd: "/work/app", ProjectDir: "/work/app"}
+	recDir, err := Save(filepath.Join(dir, "rec"), nil, []byte("[ctx]"), source, time.Now())
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if !strings.HasSuffix(recDir, "-af99e13e") {
+		t.Errorf("Save() dir = %q, want the resolved session id", recDir)
+	}
+
+	rec, err := Load(recDir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if snapshot, _ := rec.Snapshot(); string(snapshot) != testTranscript {
+		t.Errorf("Snapshot() = %q, want the resolved transcript", snapshot)
+	}
+	if rec.TranscriptPath != transcript || rec.Source != source {
+		t.Errorf("Load() = %+v, want the resolved source", rec)
+	}
+
+	payload, err := rec.Payload("/replay/af99e13e.jsonl")
+	if err != nil {
+		t.Fatalf("Payload() error = %v", err)
+	}
+	want := `{"cwd":"/work/app","session_id":"af99e13e","transcript_path":"/replay/af99e13e.jsonl","workspace":{"current_dir":"/work/app","project_dir":"/work/app"}}`
+	if string(payload) != want {
+		t.Errorf("Payload() = %s, want %s", payload, want)
+	}
+}
+
+func TestSaveUndecodableInput(t *testing.T) {
+	dir := t.TempDir()
+	recDir, err := Save(dir, []byte("{broken"), nil, Source{}, time.Now())
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	rec, err := Load(recDir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if snapshot, _ := rec.Snapshot(); snapshot != nil {
+		t.Errorf("Snapshot() = %q, want none", snapshot)
+	}
+	payload, _ := rec.Payload("/tmp/x.jsonl")
+	if string(payload) != "{broken" {
+		t.Errorf("Payload() = %q, want input unchanged", payload)
+	}
+}
+
+func TestReadTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "t.jsonl")
+	if err := os.WriteFile(path, []byte("aaaa\nbbbb\ncccc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		size       int64
+		want       string
+		wantOffset int64
+	}{
+		{size: 100, want: "aaaa\nbbbb\ncccc\n", wantOffset: 0},
+		{size: 10, want: "cccc\n", wantOffset: 10},
+		{size: 7, want: "cccc\n", wantOffset: 10},
+	}
+
+	for _, tt := range tests {
+		got, offset, err := readTail(path, tt.size)
+		if err != nil {
+			t.Fatalf("readTail(%d) error = %v", tt.size, err)
+		}
+		if string(got) != tt.want || offset != tt.wantOffset {
+			t.Errorf("readTail(%d) = %q, %d, want %q, %d", tt.size, got, offset, tt.want, tt.wantOffset)
+		}
+	}
+}
+
+func TestReadTailCompressed(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("aaaa\nbbbb\ncccc\n"))
+	zw.Close()
+	path := filepath.Join(t.TempDir(), "t.jsonl.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		size       int64
+		want       string
+		wantOffset int64
+	}{
+		{size: 100, want: "aaaa\nbbbb\ncccc\n", wantOffset: 0},
+		{size: 10, want: "cccc\n", wantOffset: 10},
+		{size: 7, want: "cccc\n", wantOffset: 10},
+	}
+
+	for _, tt := range tests {
+		got, offset, err := readTail(path, tt.size)
+		if err != nil {
+			t.Fatalf("readTail(%d) error = %v", tt.size, err)
+		}
+		if string(got) != tt.want || offset != tt.wantOffset {
+			t.Errorf("readTail(%d) = %q, %d, want %q, %d", tt.size, got, offset, tt.want, tt.wantOffset)
+		}
+	}
+}
+
+func TestPayload(t *testing.T) {
+	rec := &Recording{Input: []byte(`{"transcript_path":"/orig.jsonl","model":{"id":"m"},"future_field":[1]}`)}
+	payload, err := rec.Payload("/replay/t.jsonl")
+	if err != nil {
+		t.Fatalf("Payload() error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(payload, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["transcript_path"] != "/replay/t.jsonl" {
+		t.Errorf("Payload() transcript_path = %v, want /replay/t.jsonl", got["transcript_path"])
+	}
+	if _, ok := got["future_field"]; !ok {
+		t.Errorf("Payload() dropped unknown fields: %s", payload)
+	}
+}
+
+func TestSteps(t *testing.T) {
+	steps, err := Steps([]byte(testTranscript))
+	if err != nil {
+		t.Fatalf("Steps() error = %v", err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("len(Steps()) = %d, want 2", len(steps))
+	}
+
+	lines := strings.SplitAfter(testTranscript, "\n")
+	if steps[0].Line != 2 || string(steps[0].Transcript) != strings.Join(lines[:2], "") {
+		t.Errorf("Steps()[0] = line %d %q", steps[0].Line, steps[0].Transcript)
+	}
+	if steps[1].Line != 4 || string(steps[1].Transcript) != testTranscript {
+		t.Errorf("Steps()[1] = line %d %q", steps[1].Line, steps[1].Transcript)
+	}
+	if want := time.Date(2025, 10, 1, 10, 1, 0, 0, time.UTC); !steps[1].Timestamp.Equal(want) {
+		t.Errorf("Steps()[1].Timestamp = %v, want %v", steps[1].Timestamp, want)
+	}
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// output formats accepted by Write
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+var columns = []string{"calls", "input", "output", "cache_write", "cache_read", "total_tokens", "cost_usd"}
+
+// Write renders the report in the given format
+func Write(w io.Writer, report *Report, format string) error {
+	switch format {
+	case FormatTable:
+		return writeTable(w, report)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case FormatCSV:
+		return writeCSV(w, report)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func writeTable(w io.Writer, report *Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	header := append([]string{strings.ToUpper(report.GroupBy)}, columns...)
+	for i := range header {
+		header[i] = strings.ToUpper(strings.ReplaceAll(header[i], "_", " "))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+
+	for _, row := range report.Rows {
+		fmt.Fprintln(tw, strings.Join(tableCells(row), "\t")+"\t")
+	}
+	fmt.Fprintln(tw, strings.Join(tableCells(report.Total), "\t")+"\t")
+	return tw.Flush()
+}
+
+func tableCells(row Row) []string {
+	return []string{
+		row.Key,
+		strconv.Itoa(row.Calls),
+		strconv.FormatInt(row.Tokens.InputTokens, 10),
+		strconv.FormatInt(row.Tokens.OutputTokens, 10),
+		strconv.FormatInt(row.Tokens.CacheCreationInputTokens, 10),
+		strconv.FormatInt(row.Tokens.CacheReadInputTokens, 10),
+		strconv.FormatInt(row.Tokens.Total(), 10),
+		fmt.Sprintf("$%.2f", row.Cost),
+	}
+}
+
+func writeCSV(w io.Writer, report *Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{report.GroupBy}, columns...)); err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		cells := tableCells(row)
+		// machine-readable cost without currency sign or rounding
+		cells[len(cells)-1] = strconv.FormatFloat(row.Cost, 'f', 6, 64)
+		if err := cw.Write(cells); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	report, err := Aggregate(testEntries(), Options{GroupBy: GroupMonth, Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		format       string
+		wantContains []string
+	}{
+		{
+			format:       FormatTable,
+			wantContains: []string{"MONTH", "CACHE WRITE", "2025-09", "$10.80", "total"},
+		},
+		{
+			format:       FormatCSV,
+			wantContains: []string{"month,calls,input,output,cache_write,cache_read,total_tokens,cost_usd\n", "2025-09,3,1000000,100000,0,1000000,2100000,10.800000\n"},
+		},
+		{
+			format:       FormatJSON,
+			wantContains: []string{`"group_by": "month"`, `"key": "2025-10"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, report, tt.format); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			for _, want := range tt.wantContains {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Write(%s) does not contain %q, got:\n%s", tt.format, want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteJSONRoundTrip(t *testing.T) {
+	report, err := Aggregate(testEntries(), Options{GroupBy: GroupModel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, report, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(decoded.Rows) != len(report.Rows) || decoded.Total.Calls != report.Total.Calls {
+		t.Errorf("decoded = %+v, want %+v", decoded, report)
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, &Report{}, "xml"); err == nil {
+		t.Error("Write() error = nil for unknown format")
+	}
+}
@@ -0,0 +1,162 @@
+package report
+
+import (
+	"ccstatus/internal/calculator"
+	"ccstatus/internal/parser"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// grouping keys accepted by Aggregate
+const (
+	GroupDay     = "day"
+	GroupWeek    = "week"
+	GroupMonth   = "month"
+	GroupProject = "project"
+	GroupModel   = "model"
+)
+
+// unknownKey labels entries without the grouping attribute
+const unknownKey = "(unknown)"
+
+// Groups lists the supported grouping keys
+var Groups = []string{GroupDay, GroupWeek, GroupMonth, GroupProject, GroupModel}
+
+// Row is the aggregated usage for one group
+type Row struct {
+	Key    string                 `json:"key"`
+	Calls  int                    `json:"calls"`
+	Tokens calculator.TokenTotals `json:"tokens"`
+	Cost   float64                `json:"cost"`
+	Models []string               `json:"models,omitempty"`
+}
+
+// Options selects how entries are grouped and filtered
+type Options struct {
+	GroupBy string
+	// Since and Until bound entry timestamps, zero values are open ends; Until is exclusive
+	Since time.Time
+	Until time.Time
+	// Location is used for day, week and month boundaries, defaults to time.Local
+	Location *time.Location
+}
+
+// Report is the result of Aggregate
+type Report struct {
+	GroupBy string `json:"group_by"`
+	Rows    []Row  `json:"rows"`
+	Total   Row    `json:"total"`
+}
+
+// ValidGroup reports whether group is a supported grouping key
+func ValidGroup(group string) bool {
+	for _, g := range Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// Aggregate groups entries and sums tokens and cost per group
+// Cost is computed per entry with calculator.Cost, the same as the status line
+func Aggregate(entries []parser.Entry, opts Options) (*Report, error) {
+	if !ValidGroup(opts.GroupBy) {
+		return nil, fmt.Errorf("unknown grouping %q", opts.GroupBy)
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	rows := make(map[string]*Row)
+	models := make(map[string]map[string]bool)
+	report := &Report{GroupBy: opts.GroupBy, Total: Row{Key: "total"}}
+
+	for i := range entries {
+		entry := &entries[i]
+		if !opts.Since.IsZero() && entry.Timestamp.Before(opts.Since) {
+			continue
+		}
+		if !opts.Until.IsZero() && !entry.Timestamp.Before(opts.Until) {
+			continue
+		}
+
+		key := groupKey(entry, opts.GroupBy, loc)
+		row, ok := rows[key]
+		if !ok {
+			row = &Row{Key: key}
+			rows[key] = row
+			models[key] = make(map[string]bool)
+		}
+
+		cost := calculator.Cost(&entry.Usage, entry.Model)
+		for _, r := range []*Row{row, &report.Total} {
+			r.Calls++
+			r.Tokens.Add(&entry.Usage)
+			r.Cost += cost
+		}
+		if entry.Model != "" {
+			models[key][entry.Model] = true
+		}
+	}
+
+	for key, row := range rows {
+		row.Models = sortedKeys(models[key])
+		report.Rows = append(report.Rows, *row)
+	}
+	sortRows(report.Rows, opts.GroupBy)
+	return report, nil
+}
+
+func groupKey(entry *parser.Entry, groupBy string, loc *time.Location) string {
+	ts := entry.Timestamp.In(loc)
+	switch groupBy {
+	case GroupDay:
+		return ts.Format(time.DateOnly)
+	case GroupWeek:
+		// weeks start on Monday and are labeled by that date
+		offset := (int(ts.Weekday()) + 6) % 7
+		return ts.AddDate(0, 0, -offset).Format(time.DateOnly)
+	case GroupMonth:
+		return ts.Format("2006-01")
+	case GroupProject:
+		if entry.Cwd == "" {
+			return unknownKey
+		}
+		return entry.Cwd
+	case GroupModel:
+		if entry.Model == "" {
+			return unknownKey
+		}
+		return entry.Model
+	}
+	return unknownKey
+}
+
+// sortRows orders time periods chronologically and everything else by cost, highest first
+func sortRows(rows []Row, groupBy string) {
+	switch groupBy {
+	case GroupDay, GroupWeek, GroupMonth:
+		sort.Slice(rows, func(i, j int) bool {
+			return rows[i].Key < rows[j].Key
+		})
+	default:
+		sort.Slice(rows, func(i, j int) bool {
+			if rows[i].Cost != rows[j].Cost {
+				return rows[i].Cost > rows[j].Cost
+			}
+			return rows[i].Key < rows[j].Key
+		})
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
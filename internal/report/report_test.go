@@ -0,0 +1,129 @@
+package report
+
+import (
+	"ccstatus/internal/calculator"
+	"ccstatus/internal/parser"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testEntries() []parser.Entry {
+	at := func(s string) time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			panic(err)
+		}
+		return ts
+	}
+	return []parser.Entry{
+		{Timestamp: at("2025-09-28T23:30:00Z"), Cwd: "/work/app", Model: "claude-sonnet-4-5", Usage: parser.Usage{InputTokens: 1_000_000}},
+		{Timestamp: at("2025-09-29T10:00:00Z"), Cwd: "/work/app", Model: "claude-opus-4-1", Usage: parser.Usage{OutputTokens: 100_000}},
+		{Timestamp: at("2025-09-30T12:00:00Z"), Cwd: "/work/lib", Model: "claude-sonnet-4-5", Usage: parser.Usage{CacheReadInputTokens: 1_000_000}},
+		{Timestamp: at("2025-10-01T08:00:00Z"), Model: "", Usage: parser.Usage{InputTokens: 10}},
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		wantKeys []string
+		wantCost []float64
+	}{
+		{
+			name:     "by day",
+			opts:     Options{GroupBy: GroupDay, Location: time.UTC},
+			wantKeys: []string{"2025-09-28", "2025-09-29", "2025-09-30", "2025-10-01"},
+			wantCost: []float64{3, 7.5, 0.3, 0},
+		},
+		{
+			name:     "by week starting monday",
+			opts:     Options{GroupBy: GroupWeek, Location: time.UTC},
+			wantKeys: []string{"2025-09-22", "2025-09-29"},
+			wantCost: []float64{3, 7.8},
+		},
+		{
+			name:     "by month",
+			opts:     Options{GroupBy: GroupMonth, Location: time.UTC},
+			wantKeys: []string{"2025-09", "2025-10"},
+			wantCost: []float64{10.8, 0},
+		},
+		{
+			name:     "by project sorted by cost",
+			opts:     Options{GroupBy: GroupProject, Location: time.UTC},
+			wantKeys: []string{"/work/app", "/work/lib", unknownKey},
+			wantCost: []float64{10.5, 0.3, 0},
+		},
+		{
+			name:     "by model",
+			opts:     Options{GroupBy: GroupModel, Location: time.UTC},
+			wantKeys: []string{"claude-opus-4-1", "claude-sonnet-4-5", unknownKey},
+			wantCost: []float64{7.5, 3.3, 0},
+		},
+		{
+			name: "since and until",
+			opts: Options{
+				GroupBy:  GroupDay,
+				Location: time.UTC,
+				Since:    time.Date(2025, 9, 29, 0, 0, 0, 0, time.UTC),
+				Until:    time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantKeys: []string{"2025-09-29", "2025-09-30"},
+			wantCost: []float64{7.5, 0.3},
+		},
+		{
+			name:     "local day boundaries",
+			opts:     Options{GroupBy: GroupDay, Location: time.FixedZone("UTC+2", 2*60*60)},
+			wantKeys: []string{"2025-09-29", "2025-09-30", "2025-10-01"},
+			wantCost: []float64{10.5, 0.3, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Aggregate(testEntries(), tt.opts)
+			if err != nil {
+				t.Fatalf("Aggregate() error = %v", err)
+			}
+
+			var keys []string
+			var totalCost float64
+			for i, row := range got.Rows {
+				keys = append(keys, row.Key)
+				totalCost += row.Cost
+				if i < len(tt.wantCost) && math.Abs(row.Cost-tt.wantCost[i]) > 1e-9 {
+					t.Errorf("row %q cost = %v, want %v", row.Key, row.Cost, tt.wantCost[i])
+				}
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+			if math.Abs(got.Total.Cost-totalCost) > 1e-9 {
+				t.Errorf("Total.Cost = %v, want sum of rows %v", got.Total.Cost, totalCost)
+			}
+		})
+	}
+}
+
+func TestAggregateTotalsAndModels(t *testing.T) {
+	got, err := Aggregate(testEntries(), Options{GroupBy: GroupProject})
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+
+	want := calculator.TokenTotals{InputTokens: 1_000_010, OutputTokens: 100_000, CacheReadInputTokens: 1_000_000}
+	if got.Total.Tokens != want || got.Total.Calls != 4 {
+		t.Errorf("Total = %+v, want tokens %+v and 4 calls", got.Total, want)
+	}
+	if models := got.Rows[0].Models; !reflect.DeepEqual(models, []string{"claude-opus-4-1", "claude-sonnet-4-5"}) {
+		t.Errorf("Models = %v", models)
+	}
+}
+
+func TestAggregateUnknownGroup(t *testing.T) {
+	if _, err := Aggregate(nil, Options{GroupBy: "year"}); err == nil {
+		t.Error("Aggregate() error = nil for unknown grouping")
+	}
+}
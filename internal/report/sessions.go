@@ -0,0 +1,133 @@
+package report
+
+import (
+	"ccstatus/internal/calculator"
+	"ccstatus/internal/formatter"
+	"ccstatus/internal/parser"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// SessionSummary is one line of the multi-session overview
+type SessionSummary struct {
+	Path      string `json:"path"`
+	SessionID string `json:"session_id,omitempty"`
+	// Project is the working directory recorded in the transcript
+	Project           string    `json:"project"`
+	Model             string    `json:"model"`
+	ContextTokens     int64     `json:"context_tokens"`
+	MaxTokens         int64     `json:"max_tokens"`
+	ContextPercentage float64   `json:"context_percentage"`
+	Level             string    `json:"level"`
+	Cost              float64   `json:"cost"`
+	Calls             int       `json:"calls"`
+	Compactions       int       `json:"compactions"`
+	LastActivity      time.Time `json:"last_activity,omitzero"`
+	Error             string    `json:"error,omitempty"`
+}
+
+// SummarizeSession reduces a transcript to the current context of its main
+// conversation and its total cost, sidechain usage counts toward cost only
+// context and model come from one entry, picked by the status-line rule within the main chain
+func SummarizeSession(path string, turns []parser.Turn) (SessionSummary, error) {
+	summary := SessionSummary{Path: path}
+	timeline, err := BuildTimeline(turns, SidechainAll)
+	if err != nil {
+		return summary, err
+	}
+	summary.Cost = timeline.Cost
+	summary.Compactions = timeline.Compactions
+
+	var contextUsage *parser.Usage
+	for _, row := range timeline.Rows {
+		if row.SessionID != "" {
+			summary.SessionID = row.SessionID
+		}
+		if row.Cwd != "" {
+			summary.Project = row.Cwd
+		}
+		if row.Timestamp.After(summary.LastActivity) {
+			summary.LastActivity = row.Timestamp
+		}
+		if row.Usage != nil && !row.Duplicate {
+			summary.Calls++
+		}
+		if row.Sidechain {
+			continue
+		}
+		// same selection rule as the parser, limited to the main conversation
+		if row.Usage != nil && row.Role != "" {
+			contextUsage = row.Usage
+			summary.Model = row.Model
+		}
+	}
+
+	info := calculator.Calculate(contextUsage, summary.Model)
+	summary.ContextTokens = info.CurrentTokens
+	summary.MaxTokens = info.MaxTokens
+	summary.ContextPercentage = info.Percentage
+	summary.Level = calculator.GetUsageLevel(info.Percentage)
+	return summary, nil
+}
+
+// SortByRisk orders sessions closest to the context limit first,
+// ties go to the most recently active session
+func SortByRisk(summaries []SessionSummary) {
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if summaries[i].ContextPercentage != summaries[j].ContextPercentage {
+			return summaries[i].ContextPercentage > summaries[j].ContextPercentage
+		}
+		return summaries[i].LastActivity.After(summaries[j].LastActivity)
+	})
+}
+
+// WriteSessions renders the overview as a table or JSON, now is used for relative times
+func WriteSessions(w io.Writer, summaries []SessionSummary, format string, now time.Time) error {
+	switch format {
+	case FormatTable:
+		return writeSessionsTable(w, summaries, now)
+	case FormatJSON:
+		if summaries == nil {
+			summaries = []SessionSummary{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summaries)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func writeSessionsTable(w io.Writer, summaries []SessionSummary, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROJECT\tMODEL\tCONTEXT\tCOST\tCALLS\tLAST ACTIVITY\tSESSION")
+	for _, s := range summaries {
+		context := fmt.Sprintf("%.1f%% (%d/%d)", s.ContextPercentage, s.ContextTokens, s.MaxTokens)
+		if s.Error != "" {
+			context = "error: " + s.Error
+		}
+		cells := []string{
+			orDash(s.Project),
+			orDash(s.Model),
+			context,
+			fmt.Sprintf("$%.2f", s.Cost),
+			fmt.Sprint(s.Calls),
+			sinceActivity(s.LastActivity, now),
+			orDash(s.SessionID),
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// sinceActivity renders how long ago a session was last active
+func sinceActivity(ts, now time.Time) string {
+	if ts.IsZero() {
+		return "-"
+	}
+	return formatter.FormatDuration(now.Sub(ts)) + " ago"
+}
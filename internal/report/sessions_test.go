@@ -0,0 +1,113 @@
+package report
+
+import (
+	"bytes"
+	"ccstatus/internal/parser"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sessionsTranscript = `{"type":"user","timestamp":"2025-10-01T10:00:00Z","sessionId":"s1","cwd":"/work/app","message":{"role":"user","content":"hi"}}
+{"type":"assistant","timestamp":"2025-10-01T10:00:05Z","sessionId":"s1","cwd":"/work/app","requestId":"r1","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":1000,"cache_read_input_tokens":99000,"output_tokens":100}}}
+{"type":"assistant","timestamp":"2025-10-01T10:00:05Z","sessionId":"s1","cwd":"/work/app","requestId":"r1","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":1000,"cache_read_input_tokens":99000,"output_tokens":100}}}
+{"type":"assistant","timestamp":"2025-10-01T10:00:06Z","sessionId":"s1","cwd":"/work/app","isSidechain":true,"requestId":"r2","message":{"id":"m2","role":"assistant","model":"claude-haiku-4-5","usage":{"input_tokens":5000}}}
+`
+
+func TestSummarizeSession(t *testing.T) {
+	turns, err := parser.ReadTurns(strings.NewReader(sessionsTranscript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := SummarizeSession("/tmp/s1.jsonl", turns)
+	if err != nil {
+		t.Fatalf("SummarizeSession() error = %v", err)
+	}
+
+	if got.SessionID != "s1" || got.Project != "/work/app" {
+		t.Errorf("SummarizeSession() session = %q project = %q, want s1 /work/app", got.SessionID, got.Project)
+	}
+	// the sidechain model and usage do not replace the main conversation
+	if got.Model != "claude-sonnet-4-5" {
+		t.Errorf("SummarizeSession().Model = %q, want claude-sonnet-4-5", got.Model)
+	}
+	if got.ContextTokens != 100000 || got.ContextPercentage != 50 || got.Level != "green" {
+		t.Errorf("SummarizeSession() context = %d %.1f%% %s, want 100000 50.0%% green", got.ContextTokens, got.ContextPercentage, got.Level)
+	}
+	if got.Calls != 2 {
+		t.Errorf("SummarizeSession().Calls = %d, want 2", got.Calls)
+	}
+	if want := time.Date(2025, 10, 1, 10, 0, 6, 0, time.UTC); !got.LastActivity.Equal(want) {
+		t.Errorf("SummarizeSession().LastActivity = %v, want %v", got.LastActivity, want)
+	}
+}
+
+func TestSortByRisk(t *testing.T) {
+	base := time.Date(2025, 10, 1, 10, 0, 0, 0, time.UTC)
+	summaries := []SessionSummary{
+		{Path: "low", ContextPercentage: 10},
+		{Path: "high-old", ContextPercentage: 90, LastActivity: base},
+		{Path: "high-new", ContextPercentage: 90, LastActivity: base.Add(time.Minute)},
+		{Path: "mid", ContextPercentage: 60},
+	}
+	SortByRisk(summaries)
+
+	want := []string{"high-new", "high-old", "mid", "low"}
+	for i, path := range want {
+		if summaries[i].Path != path {
+			t.Errorf("SortByRisk()[%d] = %q, want %q", i, summaries[i].Path, path)
+		}
+	}
+}
+
+func TestWriteSessions(t *testing.T) {
+	now := time.Date(2025, 10, 1, 10, 12, 0, 0, time.UTC)
+	summaries := []SessionSummary{
+		{
+			Path:              "/tmp/s1.jsonl",
+			SessionID:         "s1",
+			Project:           "/work/app",
+			Model:             "claude-sonnet-4-5",
+			ContextTokens:     100000,
+			MaxTokens:         200000,
+			ContextPercentage: 50,
+			Cost:              1.5,
+			Calls:             3,
+			LastActivity:      now.Add(-12 * time.Minute),
+		},
+		{Path: "/tmp/broken.jsonl", Error: "permission denied"},
+	}
+
+	var table bytes.Buffer
+	if err := WriteSessions(&table, summaries, FormatTable, now); err != nil {
+		t.Fatalf("WriteSessions(table) error = %v", err)
+	}
+	for _, want := range []string{"PROJECT", "/work/app", "50.0% (100000/200000)", "$1.50", "12m ago", "error: permission denied"} {
+		if !strings.Contains(table.String(), want) {
+			t.Errorf("WriteSessions(table) does not contain %q, got:\n%s", want, table.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := WriteSessions(&out, summaries, FormatJSON, now); err != nil {
+		t.Fatalf("WriteSessions(json) error = %v", err)
+	}
+	var decoded []SessionSummary
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteSessions(json) output is not valid JSON: %v", err)
+	}
+	if len(decoded) != 2 || decoded[0].ContextPercentage != 50 || decoded[1].Error == "" {
+		t.Errorf("WriteSessions(json) decoded = %+v", decoded)
+	}
+
+	var empty bytes.Buffer
+	WriteSessions(&empty, nil, FormatJSON, now)
+	if strings.TrimSpace(empty.String()) != "[]" {
+		t.Errorf("WriteSessions(json, nil) = %q, want []", empty.String())
+	}
+
+	if err := WriteSessions(&out, summaries, "xml", now); err == nil {
+		t.Error("WriteSessions(xml) error = nil, want error")
+	}
+}
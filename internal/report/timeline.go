@@ -0,0 +1,180 @@
+package report
+
+import (
+	"ccstatus/internal/calculator"
+	"ccstatus/internal/parser"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// sidechain filters accepted by BuildTimeline
+const (
+	SidechainAll  = "all"
+	SidechainMain = "main"
+	SidechainOnly = "only"
+)
+
+// TimelineRow is one transcript entry with derived context and cost
+type TimelineRow struct {
+	parser.Turn
+	// ContextTokens is the context size after this entry, carried over from the
+	// previous entry of the same chain when the entry has no usage
+	ContextTokens     int64   `json:"context_tokens"`
+	ContextPercentage float64 `json:"context_percentage"`
+	Cost              float64 `json:"cost"`
+	// Duplicate marks repeated lines of an API response already counted earlier
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+// Timeline is the per-turn view of a single transcript
+type Timeline struct {
+	Rows        []TimelineRow          `json:"rows"`
+	Tokens      calculator.TokenTotals `json:"tokens"`
+	Cost        float64                `json:"cost"`
+	Compactions int                    `json:"compactions"`
+}
+
+// BuildTimeline derives context size and cost for every turn
+func BuildTimeline(turns []parser.Turn, sidechain string) (*Timeline, error) {
+	switch sidechain {
+	case SidechainAll, SidechainMain, SidechainOnly:
+	default:
+		return nil, fmt.Errorf("unknown sidechain filter %q", sidechain)
+	}
+
+	timeline := &Timeline{}
+	seen := make(map[string]bool)
+	// main and sidechain conversations have separate context windows
+	contexts := map[bool]calculator.ContextInfo{}
+	models := map[bool]string{}
+
+	for _, turn := range turns {
+		if (sidechain == SidechainMain && turn.Sidechain) || (sidechain == SidechainOnly && !turn.Sidechain) {
+			continue
+		}
+
+		row := TimelineRow{Turn: turn}
+		if turn.Model != "" {
+			models[turn.Sidechain] = turn.Model
+		}
+		if turn.Compaction {
+			timeline.Compactions++
+		}
+
+		if turn.Usage != nil {
+			contexts[turn.Sidechain] = calculator.Calculate(turn.Usage, models[turn.Sidechain])
+
+			entry := parser.Entry{MessageID: turn.MessageID, RequestID: turn.RequestID}
+			if key := entry.DedupKey(); key != "" && seen[key] {
+				row.Duplicate = true
+			} else {
+				if key != "" {
+					seen[key] = true
+				}
+				row.Cost = calculator.Cost(turn.Usage, turn.Model)
+				timeline.Cost += row.Cost
+				timeline.Tokens.Add(turn.Usage)
+			}
+		}
+
+		info := contexts[turn.Sidechain]
+		row.ContextTokens = info.CurrentTokens
+		row.ContextPercentage = info.Percentage
+		timeline.Rows = append(timeline.Rows, row)
+	}
+	return timeline, nil
+}
+
+// WriteTimeline renders the timeline as a table or JSON
+func WriteTimeline(w io.Writer, timeline *Timeline, format string) error {
+	switch format {
+	case FormatTable:
+		return writeTimelineTable(w, timeline)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(timeline)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func writeTimelineTable(w io.Writer, timeline *Timeline) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tTIME\tROLE\tMODEL\tINPUT\tCACHE READ\tCACHE WRITE\tOUTPUT\tCONTEXT\tCOST\tNOTES")
+
+	for _, row := range timeline.Rows {
+		cells := []string{
+			strconv.Itoa(row.Line),
+			formatTimestamp(row.Timestamp),
+			orDash(turnRole(row.Turn)),
+			orDash(row.Model),
+		}
+		if row.Usage != nil {
+			cells = append(cells,
+				strconv.FormatInt(row.Usage.InputTokens, 10),
+				strconv.FormatInt(row.Usage.CacheReadInputTokens, 10),
+				strconv.FormatInt(row.Usage.CacheCreationInputTokens, 10),
+				strconv.FormatInt(row.Usage.OutputTokens, 10),
+			)
+		} else {
+			cells = append(cells, "-", "-", "-", "-")
+		}
+		cells = append(cells,
+			fmt.Sprintf("%d (%.1f%%)", row.ContextTokens, row.ContextPercentage),
+			fmt.Sprintf("$%.4f", row.Cost),
+			strings.Join(rowNotes(row), ","),
+		)
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d entries, %d tokens, $%.4f, %d compactions\n",
+		len(timeline.Rows), timeline.Tokens.Total(), timeline.Cost, timeline.Compactions)
+	return err
+}
+
+// turnRole prefers the message role and falls back to the entry type (system, summary)
+func turnRole(turn parser.Turn) string {
+	if turn.Role != "" {
+		return turn.Role
+	}
+	return turn.Type
+}
+
+func rowNotes(row TimelineRow) []string {
+	var notes []string
+	if row.Compaction {
+		notes = append(notes, "compact")
+	}
+	if row.Sidechain {
+		notes = append(notes, "sidechain")
+	}
+	if row.Duplicate {
+		notes = append(notes, "dup")
+	}
+	if row.StatusLine {
+		notes = append(notes, "status-line")
+	}
+	return notes
+}
+
+func formatTimestamp(ts time.Time) string {
+	if ts.IsZero() {
+		return "-"
+	}
+	return ts.Local().Format(time.DateTime)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,110 @@
+package report
+
+import (
+	"bytes"
+	"ccstatus/internal/parser"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+const timelineTranscript = `{"type":"user","timestamp":"2025-10-01T10:00:00Z","message":{"role":"user","content":"hi"}}
+{"type":"assistant","timestamp":"2025-10-01T10:00:05Z","requestId":"r1","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":1000,"cache_read_input_tokens":99000,"output_tokens":100}}}
+{"type":"assistant","timestamp":"2025-10-01T10:00:05Z","requestId":"r1","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":1000,"cache_read_input_tokens":99000,"output_tokens":100}}}
+{"type":"assistant","timestamp":"2025-10-01T10:00:06Z","isSidechain":true,"requestId":"r2","message":{"id":"m2","role":"assistant","model":"claude-haiku-4-5","usage":{"input_tokens":5000}}}
+{"type":"system","subtype":"compact_boundary","timestamp":"2025-10-01T10:30:00Z"}
+{"type":"assistant","timestamp":"2025-10-01T10:31:00Z","requestId":"r3","message":{"id":"m3","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":20000}}}
+`
+
+func buildTestTimeline(t *testing.T, sidechain string) *Timeline {
+	t.Helper()
+	turns, err := parser.ReadTurns(strings.NewReader(timelineTranscript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeline, err := BuildTimeline(turns, sidechain)
+	if err != nil {
+		t.Fatalf("BuildTimeline() error = %v", err)
+	}
+	return timeline
+}
+
+func TestBuildTimeline(t *testing.T) {
+	timeline := buildTestTimeline(t, SidechainAll)
+	if len(timeline.Rows) != 6 {
+		t.Fatalf("len(Rows) = %d, want 6", len(timeline.Rows))
+	}
+
+	wantContext := []int64{0, 100000, 100000, 5000, 100000, 20000}
+	for i, want := range wantContext {
+		if got := timeline.Rows[i].ContextTokens; got != want {
+			t.Errorf("Rows[%d].ContextTokens = %d, want %d", i, got, want)
+		}
+	}
+	if !timeline.Rows[2].Duplicate || timeline.Rows[2].Cost != 0 {
+		t.Errorf("Rows[2] = %+v, want zero-cost duplicate", timeline.Rows[2])
+	}
+	if math.Abs(timeline.Rows[1].ContextPercentage-50) > 1e-9 {
+		t.Errorf("Rows[1].ContextPercentage = %v, want 50", timeline.Rows[1].ContextPercentage)
+	}
+
+	// 1000*3 + 99000*0.3 + 100*15 sonnet, 5000*1 haiku, 20000*3 sonnet, per million
+	wantCost := (3000 + 29700 + 1500 + 5000 + 60000) / 1e6
+	if math.Abs(timeline.Cost-wantCost) > 1e-12 {
+		t.Errorf("Cost = %v, want %v", timeline.Cost, wantCost)
+	}
+	if timeline.Compactions != 1 {
+		t.Errorf("Compactions = %d, want 1", timeline.Compactions)
+	}
+}
+
+func TestBuildTimelineSidechainFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		wantRows int
+	}{
+		{filter: SidechainAll, wantRows: 6},
+		{filter: SidechainMain, wantRows: 5},
+		{filter: SidechainOnly, wantRows: 1},
+	}
+	for _, tt := range tests {
+		if got := len(buildTestTimeline(t, tt.filter).Rows); got != tt.wantRows {
+			t.Errorf("BuildTimeline(%s) rows = %d, want %d", tt.filter, got, tt.wantRows)
+		}
+	}
+
+	if _, err := BuildTimeline(nil, "none"); err == nil {
+		t.Error("BuildTimeline() error = nil for unknown filter")
+	}
+}
+
+func TestWriteTimeline(t *testing.T) {
+	timeline := buildTestTimeline(t, SidechainAll)
+
+	var table bytes.Buffer
+	if err := WriteTimeline(&table, timeline, FormatTable); err != nil {
+		t.Fatalf("WriteTimeline() error = %v", err)
+	}
+	for _, want := range []string{"LINE", "CACHE READ", "100000 (50.0%)", "compact", "sidechain", "dup", "status-line", "1 compactions"} {
+		if !strings.Contains(table.String(), want) {
+			t.Errorf("table does not contain %q:\n%s", want, table.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := WriteTimeline(&out, timeline, FormatJSON); err != nil {
+		t.Fatalf("WriteTimeline() error = %v", err)
+	}
+	var decoded Timeline
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(decoded.Rows) != 6 || decoded.Rows[1].Usage == nil || decoded.Rows[1].Line != 2 {
+		t.Errorf("decoded rows = %+v", decoded.Rows)
+	}
+
+	if err := WriteTimeline(&out, timeline, FormatCSV); err == nil {
+		t.Error("WriteTimeline() error = nil for csv")
+	}
+}
@@ -0,0 +1,206 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// member is a top-level "key": value pair located by byte offsets
+type member struct {
+	Key        string
+	KeyStart   int
+	ValueStart int
+	ValueEnd   int
+}
+
+// document is a JSON object whose top-level members are known by position,
+// so single members can be replaced or removed without touching the rest of the text
+type document struct {
+	data    []byte
+	open    int // offset of the top-level '{'
+	close   int // offset of the matching '}'
+	members []member
+}
+
+// parseDocument locates the top-level members of a JSON object
+func parseDocument(data []byte) (*document, error) {
+	if !json.Valid(data) {
+		return nil, errors.New("invalid JSON")
+	}
+	doc := &document{data: data}
+	pos := skipSpace(data, 0)
+	if pos >= len(data) || data[pos] != '{' {
+		return nil, errors.New("top-level value is not an object")
+	}
+	doc.open = pos
+	pos = skipSpace(data, pos+1)
+
+	for data[pos] != '}' {
+		keyStart := pos
+		keyEnd := skipValue(data, pos)
+		var key string
+		if err := json.Unmarshal(data[keyStart:keyEnd], &key); err != nil {
+			return nil, fmt.Errorf("invalid key at offset %d: %w", keyStart, err)
+		}
+		pos = skipSpace(data, keyEnd)
+		pos = skipSpace(data, pos+1) // ':'
+		valueStart := pos
+		pos = skipValue(data, pos)
+		doc.members = append(doc.members, member{Key: key, KeyStart: keyStart, ValueStart: valueStart, ValueEnd: pos})
+		pos = skipSpace(data, pos)
+		if data[pos] == ',' {
+			pos = skipSpace(data, pos+1)
+		}
+	}
+	doc.close = pos
+	return doc, nil
+}
+
+// find returns the index of the member with key, -1 when absent
+func (d *document) find(key string) int {
+	for i, m := range d.members {
+		if m.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// set replaces the value of key or appends it as the last member,
+// indentation follows the existing members
+func (d *document) set(key string, value any) ([]byte, error) {
+	indent, multiline := d.indent()
+	prefix := ""
+	unit := ""
+	if multiline {
+		prefix, unit = indent, indent
+	}
+	encoded, err := encodeValue(value, prefix, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	if i := d.find(key); i >= 0 {
+		m := d.members[i]
+		return splice(d.data, m.ValueStart, m.ValueEnd, encoded), nil
+	}
+
+	encodedKey, _ := json.Marshal(key)
+	entry := string(encodedKey) + ": " + string(encoded)
+	if !multiline {
+		entry = string(encodedKey) + ":" + string(encoded)
+	}
+
+	if len(d.members) == 0 {
+		inner := entry
+		if multiline {
+			inner = "\n" + indent + entry + "\n"
+		}
+		return splice(d.data, d.open+1, d.close, []byte(inner)), nil
+	}
+	last := d.members[len(d.members)-1]
+	separator := ","
+	if multiline {
+		separator = ",\n" + indent
+	}
+	return splice(d.data, last.ValueEnd, last.ValueEnd, []byte(separator+entry)), nil
+}
+
+// remove deletes key together with its separator, reports false when absent
+func (d *document) remove(key string) ([]byte, bool) {
+	i := d.find(key)
+	if i < 0 {
+		return d.data, false
+	}
+	m := d.members[i]
+	switch {
+	case i > 0:
+		// drop from the end of the previous value, which takes the comma along
+		return splice(d.data, d.members[i-1].ValueEnd, m.ValueEnd, nil), true
+	case len(d.members) > 1:
+		return splice(d.data, m.KeyStart, d.members[1].KeyStart, nil), true
+	default:
+		return splice(d.data, d.open+1, d.close, nil), true
+	}
+}
+
+// indent returns the whitespace before the members and whether the object spans lines
+// a new or empty object defaults to two spaces
+func (d *document) indent() (string, bool) {
+	if len(d.members) == 0 {
+		return "  ", true
+	}
+	start := d.members[0].KeyStart
+	line := bytes.LastIndexByte(d.data[:start], '\n')
+	if line < d.open {
+		return "", false
+	}
+	return string(d.data[line+1 : start]), true
+}
+
+// encodeValue renders value indented as a member of the top-level object
+func encodeValue(value any, prefix, unit string) ([]byte, error) {
+	if unit == "" {
+		return json.Marshal(value)
+	}
+	return json.MarshalIndent(value, prefix, unit)
+}
+
+func splice(data []byte, start, end int, insert []byte) []byte {
+	out := make([]byte, 0, len(data)-(end-start)+len(insert))
+	out = append(out, data[:start]...)
+	out = append(out, insert...)
+	return append(out, data[end:]...)
+}
+
+func skipSpace(data []byte, pos int) int {
+	for pos < len(data) {
+		switch data[pos] {
+		case ' ', '\t', '\n', '\r':
+			pos++
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+// skipValue returns the offset just past the JSON value starting at pos
+// the input is known to be valid JSON
+func skipValue(data []byte, pos int) int {
+	switch data[pos] {
+	case '"':
+		for pos++; pos < len(data); pos++ {
+			switch data[pos] {
+			case '\\':
+				pos++
+			case '"':
+				return pos + 1
+			}
+		}
+		return pos
+	case '{', '[':
+		depth := 0
+		for ; pos < len(data); pos++ {
+			switch data[pos] {
+			case '"':
+				pos = skipValue(data, pos) - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return pos + 1
+				}
+			}
+		}
+		return pos
+	default:
+		for pos < len(data) && !bytes.ContainsRune([]byte(",}] \t\r\n"), rune(data[pos])) {
+			pos++
+		}
+		return pos
+	}
+}
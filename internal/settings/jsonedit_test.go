@@ -0,0 +1,110 @@
+package settings
+
+import "testing"
+
+var testEntry = StatusLine{Type: "command", Command: "/bin/ccstatus"}
+
+func TestDocumentSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "empty object",
+			input: "{}\n",
+			want:  "{\n  \"statusLine\": {\n    \"type\": \"command\",\n    \"command\": \"/bin/ccstatus\"\n  }\n}\n",
+		},
+		{
+			name:  "append keeps order and tab indentation",
+			input: "{\n\t\"model\": \"opus\",\n\t\"env\": {\"A\": \"}\"}\n}",
+			want:  "{\n\t\"model\": \"opus\",\n\t\"env\": {\"A\": \"}\"},\n\t\"statusLine\": {\n\t\t\"type\": \"command\",\n\t\t\"command\": \"/bin/ccstatus\"\n\t}\n}",
+		},
+		{
+			name:  "replace existing value in place",
+			input: "{\n    \"statusLine\": {\"type\": \"command\", \"command\": \"old\"},\n    \"z\": 1\n}\n",
+			want:  "{\n    \"statusLine\": {\n        \"type\": \"command\",\n        \"command\": \"/bin/ccstatus\"\n    },\n    \"z\": 1\n}\n",
+		},
+		{
+			name:  "compact single line",
+			input: `{"a":[1,2],"b":"x\"y"}`,
+			want:  `{"a":[1,2],"b":"x\"y","statusLine":{"type":"command","command":"/bin/ccstatus"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseDocument([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("parseDocument() error = %v", err)
+			}
+			got, err := doc.set(statusLineKey, testEntry)
+			if err != nil {
+				t.Fatalf("set() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("set() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentRemove(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		found bool
+	}{
+		{
+			name:  "last member",
+			input: "{\n  \"a\": 1,\n  \"statusLine\": {}\n}\n",
+			want:  "{\n  \"a\": 1\n}\n",
+			found: true,
+		},
+		{
+			name:  "first member",
+			input: "{\n  \"statusLine\": {},\n  \"a\": 1\n}\n",
+			want:  "{\n  \"a\": 1\n}\n",
+			found: true,
+		},
+		{
+			name:  "middle member",
+			input: `{"a":1,"statusLine":{},"b":2}`,
+			want:  `{"a":1,"b":2}`,
+			found: true,
+		},
+		{
+			name:  "only member",
+			input: "{\n  \"statusLine\": {}\n}\n",
+			want:  "{}\n",
+			found: true,
+		},
+		{
+			name:  "absent",
+			input: `{"a":1}`,
+			want:  `{"a":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseDocument([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("parseDocument() error = %v", err)
+			}
+			got, found := doc.remove(statusLineKey)
+			if string(got) != tt.want || found != tt.found {
+				t.Errorf("remove() = %q, %v, want %q, %v", got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestParseDocumentErrors(t *testing.T) {
+	for _, input := range []string{"", "[1]", "{", `{"a":}`} {
+		if _, err := parseDocument([]byte(input)); err == nil {
+			t.Errorf("parseDocument(%q) error = nil, want error", input)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package settings
+
+import (
+	"ccstatus/internal/state"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// settings scopes, in the order Claude Code applies them (later wins)
+const (
+	ScopeUser    = "user"
+	ScopeProject = "project"
+	ScopeLocal   = "local"
+)
+
+// Scopes lists the supported settings scopes
+var Scopes = []string{ScopeUser, ScopeProject, ScopeLocal}
+
+// statusLineKey is the settings.json member managed by install
+const statusLineKey = "statusLine"
+
+// envConfigDir is the Claude Code config dir override, the first entry holds user settings
+const envConfigDir = "CLAUDE_CONFIG_DIR"
+
+var (
+	// ErrNotInstalled is returned when uninstalling from a file without a statusLine
+	ErrNotInstalled = errors.New("no statusLine entry found")
+	// ErrForeignStatusLine is returned when the statusLine runs some other command
+	ErrForeignStatusLine = errors.New("statusLine entry is not a ccstatus command")
+)
+
+// StatusLine is the statusLine entry of a Claude Code settings file
+type StatusLine struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+	Padding *int   `json:"padding,omitempty"`
+}
+
+// Path returns the settings file for scope
+// project and local settings live in projectDir/.claude
+func Path(scope, projectDir string) (string, error) {
+	switch scope {
+	case ScopeUser:
+		if env := os.Getenv(envConfigDir); env != "" {
+			first, _, _ := strings.Cut(env, ",")
+			return filepath.Join(strings.TrimSpace(first), "settings.json"), nil
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find home dir: %w", err)
+		}
+		return filepath.Join(home, ".claude", "settings.json"), nil
+	case ScopeProject:
+		return filepath.Join(projectDir, ".claude", "settings.json"), nil
+	case ScopeLocal:
+		return filepath.Join(projectDir, ".claude", "settings.local.json"), nil
+	}
+	return "", fmt.Errorf("unknown scope %q, want one of: %s", scope, strings.Join(Scopes, ", "))
+}
+
+// ReadStatusLine returns the statusLine entry of the settings file at path,
+// nil when the file or the entry does not exist
+func ReadStatusLine(path string) (*StatusLine, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	var settings struct {
+		StatusLine *StatusLine `json:"statusLine"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return settings.StatusLine, nil
+}
+
+// Change is a pending edit of a settings file
+type Change struct {
+	Path string
+	Old  []byte
+	New  []byte
+	// Exists is false when the file will be created
+	Exists bool
+	mode   fs.FileMode
+}
+
+// Changed reports whether applying the change would modify the file
+func (c *Change) Changed() bool {
+	return !c.Exists || string(c.Old) != string(c.New)
+}
+
+// Diff returns the change as a unified diff
+func (c *Change) Diff() string {
+	oldName := c.Path
+	if !c.Exists {
+		oldName = "/dev/null"
+	}
+	return Diff(oldName, c.Path, string(c.Old), string(c.New))
+}
+
+// Apply writes the new content, backing up an existing file first
+// returns the backup path, empty when there was nothing to back up
+func (c *Change) Apply(now time.Time) (string, error) {
+	if !c.Changed() {
+		return "", nil
+	}
+	backup := ""
+	if c.Exists {
+		backup = backupPath(c.Path, now)
+		if err := state.WriteFileAtomic(backup, c.Old, c.mode); err != nil {
+			return "", fmt.Errorf("failed to back up settings: %w", err)
+		}
+	}
+	// the rename would replace a symlinked settings file, as kept in dotfiles, write its target
+	target := c.Path
+	if resolved, err := filepath.EvalSymlinks(c.Path); err == nil {
+		target = resolved
+	}
+	if err := state.WriteFileAtomic(target, c.New, c.mode); err != nil {
+		return "", fmt.Errorf("failed to write settings: %w", err)
+	}
+	return backup, nil
+}
+
+// backupPath picks a backup name that does not overwrite an earlier backup
+func backupPath(path string, now time.Time) string {
+	base := path + ".bak-" + now.Format("20060102-150405")
+	backup := base
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(backup); errors.Is(err, fs.ErrNotExist) {
+			return backup
+		}
+		backup = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// PlanInstall prepares setting the statusLine entry to run binary, quoted for the shell
+// Claude Code runs it with; everything else in the file is kept byte for byte
+func PlanInstall(path, binary string) (*Change, error) {
+	change, doc, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	entry := StatusLine{Type: "command", Command: shellQuote(binary)}
+	if change.Exists && doc != nil {
+		if current, err := ReadStatusLine(path); err == nil && current != nil {
+			// keep user tweaks such as padding
+			entry.Padding = current.Padding
+		}
+	}
+
+	if doc == nil {
+		encoded, err := json.MarshalIndent(map[string]any{statusLineKey: entry}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		change.New = append(encoded, '\n')
+		return change, nil
+	}
+	change.New, err = doc.set(statusLineKey, entry)
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// PlanUninstall prepares removing a statusLine entry that runs ccstatus
+func PlanUninstall(path, command string) (*Change, error) {
+	change, doc, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, ErrNotInstalled
+	}
+	current, err := ReadStatusLine(path)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrNotInstalled
+	}
+	if !IsCcstatusCommand(current.Command, command) {
+		return nil, fmt.Errorf("%w: %s", ErrForeignStatusLine, current.Command)
+	}
+	change.New, _ = doc.remove(statusLineKey)
+	return change, nil
+}
+
+// IsCcstatusCommand reports whether a statusLine command runs binary or any ccstatus build
+func IsCcstatusCommand(command, binary string) bool {
+	program := CommandProgram(command)
+	if program == "" {
+		return false
+	}
+	return program == binary || strings.TrimSuffix(filepath.Base(program), ".exe") == "ccstatus"
+}
+
+// shellQuote quotes s as a single sh word, plain paths are kept as they are
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%_+=:,./-") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// CommandProgram returns the first word of a sh command line with quotes and escapes removed
+func CommandProgram(command string) string {
+	var word strings.Builder
+	var quote rune
+	escaped := false
+	for _, r := range strings.TrimLeft(command, " \t") {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ' ' || r == '\t' || r == '\n' || r == ';' || r == '|' || r == '&':
+			return word.String()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	return word.String()
+}
+
+// load reads the settings file, doc is nil for a missing or blank file
+func load(path string) (*Change, *document, error) {
+	change := &Change{Path: path, mode: 0o600}
+	fi, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return change, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to stat settings: %w", err)
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, nil, fmt.Errorf("%s is not a regular file", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	change.Old, change.New, change.Exists, change.mode = data, data, true, fi.Mode().Perm()
+	if strings.TrimSpace(string(data)) == "" {
+		return change, nil, nil
+	}
+
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return change, doc, nil
+}
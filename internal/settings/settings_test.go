@@ -0,0 +1,266 @@
+package settings
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPath(t *testing.T) {
+	t.Setenv(envConfigDir, "/cfg/a, /cfg/b")
+	tests := []struct {
+		scope   string
+		want    string
+		wantErr bool
+	}{
+		{scope: ScopeUser, want: "/cfg/a/settings.json"},
+		{scope: ScopeProject, want: "/work/app/.claude/settings.json"},
+		{scope: ScopeLocal, want: "/work/app/.claude/settings.local.json"},
+		{scope: "global", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			got, err := Path(tt.scope, "/work/app")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Path(%q) error = %v, wantErr %v", tt.scope, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Path(%q) = %q, want %q", tt.scope, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".claude", "settings.json")
+
+	change, err := PlanInstall(path, "/bin/ccstatus")
+	if err != nil {
+		t.Fatalf("PlanInstall() error = %v", err)
+	}
+	if !strings.Contains(change.Diff(), "--- /dev/null") {
+		t.Errorf("Diff() = %q, want creation from /dev/null", change.Diff())
+	}
+	backup, err := change.Apply(time.Now())
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if backup != "" {
+		t.Errorf("Apply() backup = %q, want none for a new file", backup)
+	}
+
+	entry, err := ReadStatusLine(path)
+	if err != nil || entry == nil || entry.Command != "/bin/ccstatus" || entry.Type != "command" {
+		t.Errorf("ReadStatusLine() = %+v, %v, want /bin/ccstatus command", entry, err)
+	}
+}
+
+func TestInstallExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	original := "{\n  \"model\": \"opus\",\n  \"statusLine\": {\"type\": \"command\", \"command\": \"/old/ccstatus\", \"padding\": 0}\n}\n"
+	if err := os.WriteFile(path, []byte(original), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	change, err := PlanInstall(path, "/new/ccstatus")
+	if err != nil {
+		t.Fatalf("PlanInstall() error = %v", err)
+	}
+	diff := change.Diff()
+	if !strings.Contains(diff, `-  "statusLine": {"type": "command", "command": "/old/ccstatus", "padding": 0}`) ||
+		!strings.Contains(diff, `+    "command": "/new/ccstatus",`) {
+		t.Errorf("Diff() =\n%s", diff)
+	}
+
+	now := time.Date(2025, 10, 1, 10, 0, 0, 0, time.UTC)
+	backup, err := change.Apply(now)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if want := path + ".bak-20251001-100000"; backup != want {
+		t.Errorf("Apply() backup = %q, want %q", backup, want)
+	}
+	if data, _ := os.ReadFile(backup); string(data) != original {
+		t.Errorf("backup content = %q, want original", data)
+	}
+	if fi, _ := os.Stat(path); fi.Mode().Perm() != 0o640 {
+		t.Errorf("settings mode = %v, want 0640", fi.Mode().Perm())
+	}
+
+	entry, _ := ReadStatusLine(path)
+	if entry == nil || entry.Command != "/new/ccstatus" || entry.Padding == nil || *entry.Padding != 0 {
+		t.Errorf("ReadStatusLine() = %+v, want new command with padding kept", entry)
+	}
+
+	// a backup from the same second is never overwritten
+	uninstall, err := PlanUninstall(path, "/new/ccstatus")
+	if err != nil {
+		t.Fatalf("PlanUninstall() error = %v", err)
+	}
+	second, err := uninstall.Apply(now)
+	if err != nil {
+		t.Fatalf("Apply() uninstall error = %v", err)
+	}
+	if second != backup+"-1" {
+		t.Errorf("Apply() second backup = %q, want %q", second, backup+"-1")
+	}
+	if data, _ := os.ReadFile(backup); string(data) != original {
+		t.Errorf("first backup content = %q, want original", data)
+	}
+	reinstall, err := PlanInstall(path, "/new/ccstatus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reinstall.Apply(now); err != nil {
+		t.Fatal(err)
+	}
+
+	// a second install is a no-op
+	again, err := PlanInstall(path, "/new/ccstatus")
+	if err != nil {
+		t.Fatalf("PlanInstall() again error = %v", err)
+	}
+	if again.Changed() {
+		t.Errorf("PlanInstall() again Changed() = true, diff:\n%s", again.Diff())
+	}
+}
+
+func TestUninstall(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "removes ccstatus entry",
+			content: "{\n  \"model\": \"opus\",\n  \"statusLine\": {\"type\": \"command\", \"command\": \"/somewhere/ccstatus\"}\n}\n",
+			want:    "{\n  \"model\": \"opus\"\n}\n",
+		},
+		{
+			name:    "foreign command kept",
+			content: `{"statusLine": {"type": "command", "command": "~/bin/my-status.sh"}}`,
+			wantErr: ErrForeignStatusLine,
+		},
+		{
+			name:    "not installed",
+			content: `{"model": "opus"}`,
+			wantErr: ErrNotInstalled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "settings.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			change, err := PlanUninstall(path, "/bin/ccstatus")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("PlanUninstall() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PlanUninstall() error = %v", err)
+			}
+			if string(change.New) != tt.want {
+				t.Errorf("PlanUninstall() new = %q, want %q", change.New, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallQuotesPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	binary := "/Users/me/My Tools/it's/ccstatus"
+
+	change, err := PlanInstall(path, binary)
+	if err != nil {
+		t.Fatalf("PlanInstall() error = %v", err)
+	}
+	if _, err := change.Apply(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := ReadStatusLine(path)
+	if want := `'/Users/me/My Tools/it'\''s/ccstatus'`; entry == nil || entry.Command != want {
+		t.Fatalf("ReadStatusLine() = %+v, want command %s", entry, want)
+	}
+	out, err := exec.Command("sh", "-c", "printf %s "+entry.Command).Output()
+	if err != nil || string(out) != binary {
+		t.Errorf("sh sees %q, %v, want %q", out, err, binary)
+	}
+
+	// the quoted entry is still recognized for uninstall
+	if _, err := PlanUninstall(path, binary); err != nil {
+		t.Errorf("PlanUninstall() error = %v", err)
+	}
+}
+
+func TestApplyKeepsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte(`{"model": "opus"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "settings.json")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	change, err := PlanInstall(link, "/bin/ccstatus")
+	if err != nil {
+		t.Fatalf("PlanInstall() error = %v", err)
+	}
+	if _, err := change.Apply(time.Now()); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if fi, err := os.Lstat(link); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Lstat() = %v, %v, want the symlink kept", fi, err)
+	}
+	if entry, _ := ReadStatusLine(target); entry == nil || entry.Command != "/bin/ccstatus" {
+		t.Errorf("ReadStatusLine(target) = %+v, want the link target updated", entry)
+	}
+}
+
+func TestPlanInstallInvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte(`{"a": 1,}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PlanInstall(path, "/bin/ccstatus"); err == nil {
+		t.Error("PlanInstall() error = nil, want error for invalid JSON")
+	}
+}
+
+func TestIsCcstatusCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"/bin/ccstatus", true},
+		{"/other/ccstatus --output json", true},
+		{`"/opt/my tools/ccstatus.exe"`, true},
+		{`'/opt/it'\''s/ccstatus' --output json`, true},
+		{`/opt/my\ tools/status.sh`, false},
+		{`"/opt/ccstatus.exe"`, true},
+		{"/bin/custom", true},
+		{"/bin/status.sh", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsCcstatusCommand(tt.command, "/bin/custom"); got != tt.want {
+			t.Errorf("IsCcstatusCommand(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package state
+
+import "os"
+
+// lockFile is a no-op where flock is not available, concurrent runs are not serialized
+func lockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,42 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreLock(t *testing.T) {
+	store := New(filepath.Join(t.TempDir(), "nested"))
+	unlock, err := store.Lock("k")
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	// a second holder, as another process would be, waits for the release
+	acquired := make(chan struct{})
+	go func() {
+		unlockSecond, err := store.Lock("k")
+		if err != nil {
+			t.Errorf("second Lock() error = %v", err)
+			close(acquired)
+			return
+		}
+		close(acquired)
+		unlockSecond()
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("second Lock() returned while the first is held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("second Lock() did not return after the release")
+	}
+}
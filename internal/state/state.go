@@ -0,0 +1,166 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EnvDir overrides the default state directory
+const EnvDir = "CCSTATUS_STATE_DIR"
+
+// Dir returns the directory used for caches and per-session state
+// Honors CCSTATUS_STATE_DIR, falls back to the user cache dir, then the temp dir
+func Dir() string {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return dir
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "ccstatus")
+	}
+	return filepath.Join(os.TempDir(), "ccstatus")
+}
+
+// Store keeps JSON-encoded values as individual files inside a directory
+// Writes are atomic so concurrent ccstatus processes never see partial files
+type Store struct {
+	dir string
+}
+
+// New returns a store rooted at dir, the directory is created lazily on first write
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Default returns a store rooted at Dir()
+func Default() *Store {
+	return New(Dir())
+}
+
+// Path returns the file used to hold the value stored under key
+func (s *Store) Path(key string) string {
+	return filepath.Join(s.dir, fileName(key))
+}
+
+// Load decodes the value stored under key into v and returns its modification time
+func (s *Store) Load(key string, v any) (time.Time, error) {
+	path := s.Path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	return fi.ModTime(), nil
+}
+
+// LoadFresh is like Load but reports false when the value is missing,
+// unreadable or older than ttl
+func (s *Store) LoadFresh(key string, ttl time.Duration, v any) bool {
+	modTime, err := s.Load(key, v)
+	if err != nil {
+		return false
+	}
+	return time.Since(modTime) <= ttl
+}
+
+// Save encodes v and stores it under key
+func (s *Store) Save(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	return WriteFileAtomic(s.Path(key), data, 0o600)
+}
+
+// Delete removes the value stored under key, missing values are not an error
+func (s *Store) Delete(key string) error {
+	if err := os.Remove(s.Path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Lock takes an exclusive lock on key that other processes wait for, for a load and
+// save that must not interleave with another run; the returned function releases it
+// Load and Save do not take the lock themselves
+func (s *Store) Lock(key string) (func(), error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create state dir: %w", err)
+	}
+	file, err := os.OpenFile(s.Path(key)+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", key, err)
+	}
+	// closing the file releases the lock
+	return func() { file.Close() }, nil
+}
+
+// WriteFileAtomic writes data to a temp file in the target directory and renames it into place
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create state dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to chmod temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	return nil
+}
+
+// fileName maps an arbitrary key to a safe, stable file name
+// a readable prefix is kept for debugging, the hash suffix keeps names unique
+func fileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	var b strings.Builder
+	for _, r := range key {
+		if b.Len() >= 40 {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	return b.String() + "-" + hex.EncodeToString(sum[:6]) + ".json"
+}
@@ -0,0 +1,99 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreSaveLoad(t *testing.T) {
+	store := New(filepath.Join(t.TempDir(), "nested"))
+
+	type value struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	if err := store.Save("git/repo", value{Name: "main", Count: 3}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	var got value
+	modTime, err := store.Load("git/repo", &got)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.Name != "main" || got.Count != 3 {
+		t.Errorf("Load() = %+v, want {main 3}", got)
+	}
+	if modTime.IsZero() {
+		t.Error("Load() returned zero modification time")
+	}
+
+	if !store.LoadFresh("git/repo", time.Minute, &got) {
+		t.Error("LoadFresh() = false for recently saved value")
+	}
+
+	// age the file beyond the ttl
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(store.Path("git/repo"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if store.LoadFresh("git/repo", time.Minute, &got) {
+		t.Error("LoadFresh() = true for expired value")
+	}
+
+	if err := store.Delete("git/repo"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Load("git/repo", &got); err == nil {
+		t.Error("Load() after Delete() returned nil error")
+	}
+	if err := store.Delete("git/repo"); err != nil {
+		t.Errorf("Delete() of missing key error = %v", err)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		wantStart string
+	}{
+		{
+			name:      "plain key kept readable",
+			key:       "session-abc",
+			wantStart: "session-abc-",
+		},
+		{
+			name:      "path separators replaced",
+			key:       "git//home/user/repo",
+			wantStart: "git__home_user_repo-",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fileName(tt.key)
+			if !strings.HasPrefix(got, tt.wantStart) {
+				t.Errorf("fileName(%q) = %q, want prefix %q", tt.key, got, tt.wantStart)
+			}
+			if strings.ContainsAny(got, `/\`) {
+				t.Errorf("fileName(%q) = %q contains path separators", tt.key, got)
+			}
+		})
+	}
+
+	if fileName("a/b") == fileName("a_b") {
+		t.Error("fileName() collides for keys differing only in unsafe characters")
+	}
+}
+
+func TestDirEnvOverride(t *testing.T) {
+	t.Setenv(EnvDir, "/custom/state")
+	if got := Dir(); got != "/custom/state" {
+		t.Errorf("Dir() = %q, want %q", got, "/custom/state")
+	}
+}
@@ -0,0 +1,268 @@
+package tui
+
+import (
+	"ccstatus/internal/calculator"
+	"ccstatus/internal/formatter"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/projects"
+	"ccstatus/internal/report"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// labelWidth aligns values after the row labels
+const labelWidth = 10
+
+// SessionView is everything the dashboard shows about one transcript
+type SessionView struct {
+	Path    string
+	Name    string
+	Cwd     string
+	Model   string
+	Context calculator.ContextInfo
+	// History is the context size after each main-chain API call
+	History      []int64
+	Tokens       calculator.TokenTotals
+	Cost         float64
+	CacheRatio   float64
+	Burn         calculator.BurnRate
+	Calls        int
+	Compactions  int
+	LastActivity time.Time
+	Err          error
+}
+
+// NewSessionView derives dashboard data from transcript turns
+// Context follows the same entry the status line uses, cost and rates reuse report.BuildTimeline
+func NewSessionView(path string, turns []parser.Turn, now time.Time) *SessionView {
+	view := &SessionView{
+		Path: path,
+		Name: projects.SessionID(path),
+	}
+
+	timeline, err := report.BuildTimeline(turns, report.SidechainAll)
+	if err != nil {
+		view.Err = err
+		return view
+	}
+	view.Tokens = timeline.Tokens
+	view.Cost = timeline.Cost
+	view.CacheRatio = calculator.CacheHitRatio(timeline.Tokens)
+	view.Compactions = timeline.Compactions
+
+	var statusUsage *parser.Usage
+	var entries []parser.Entry
+	for _, row := range timeline.Rows {
+		if row.Cwd != "" {
+			view.Cwd = row.Cwd
+			view.Name = filepath.Base(row.Cwd)
+		}
+		if !row.Timestamp.IsZero() {
+			view.LastActivity = row.Timestamp
+		}
+		if row.Sidechain {
+			continue
+		}
+		if row.Model != "" {
+			view.Model = row.Model
+		}
+		if row.StatusLine {
+			statusUsage = row.Usage
+		}
+		if row.Usage != nil && !row.Duplicate {
+			view.Calls++
+			view.History = append(view.History, row.ContextTokens)
+			entries = append(entries, parser.Entry{Timestamp: row.Timestamp, Model: row.Model, Usage: *row.Usage})
+		}
+	}
+
+	view.Context = calculator.Calculate(statusUsage, view.Model)
+	view.Burn = calculator.CalculateBurnRate(entries, now, calculator.DefaultBurnWindow)
+	return view
+}
+
+// Dashboard is the state of the watch screen
+type Dashboard struct {
+	Sessions []*SessionView
+	Selected int
+}
+
+// SetSessions replaces the sessions, the selection stays on the same transcript
+// when the order changes
+func (d *Dashboard) SetSessions(sessions []*SessionView) {
+	if d.Selected < len(d.Sessions) {
+		selected := d.Sessions[d.Selected].Path
+		for i, session := range sessions {
+			if session.Path == selected {
+				d.Selected = i
+				break
+			}
+		}
+	}
+	d.Sessions = sessions
+}
+
+// HandleKey applies a key press and reports whether the dashboard should quit
+func (d *Dashboard) HandleKey(key string) bool {
+	count := len(d.Sessions)
+	switch key {
+	case "q", KeyCtrlC, KeyEscape:
+		return true
+	case KeyRight, KeyDown, KeyTab, "l", "j":
+		if count > 0 {
+			d.Selected = (d.Selected + 1) % count
+		}
+	case KeyLeft, KeyUp, KeyShiftTab, "h", "k":
+		if count > 0 {
+			d.Selected = (d.Selected - 1 + count) % count
+		}
+	default:
+		if len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+			if index := int(key[0] - '1'); index < count {
+				d.Selected = index
+			}
+		}
+	}
+	return false
+}
+
+// Draw renders the dashboard into screen
+func (d *Dashboard) Draw(screen *Screen, now time.Time) {
+	screen.Clear()
+	if screen.Height == 0 || screen.Width == 0 {
+		return
+	}
+	if d.Selected >= len(d.Sessions) {
+		d.Selected = max(len(d.Sessions)-1, 0)
+	}
+
+	title := fmt.Sprintf(" ccstatus watch · %d sessions ", len(d.Sessions))
+	screen.Fill(0, 0, screen.Width, ' ', StyleReverse)
+	screen.Put(0, 0, title, StyleReverse+StyleBold)
+	clock := now.Format(time.TimeOnly) + " "
+	screen.Put(screen.Width-len(clock), 0, clock, StyleReverse)
+
+	if len(d.Sessions) == 0 {
+		screen.Put(1, 2, "no active transcripts, waiting for new activity…", StyleDim)
+		d.drawHelp(screen)
+		return
+	}
+
+	d.drawTabs(screen, 1)
+	screen.Fill(0, 2, screen.Width, '─', StyleDim)
+	y := d.drawDetails(screen, 3, d.Sessions[d.Selected], now)
+	d.drawOverview(screen, y, now)
+	d.drawHelp(screen)
+}
+
+func (d *Dashboard) drawTabs(screen *Screen, y int) {
+	x := 0
+	for i, session := range d.Sessions {
+		label := fmt.Sprintf(" %d:%s ", i+1, session.Name)
+		style := ""
+		if i == d.Selected {
+			style = StyleReverse
+		}
+		x = screen.Put(x, y, label, style) + 1
+	}
+}
+
+// drawDetails renders the selected session and returns the next free row
+func (d *Dashboard) drawDetails(screen *Screen, y int, session *SessionView, now time.Time) int {
+	row := func(label, value, style string) {
+		screen.Put(1, y, label, StyleDim)
+		screen.Put(1+labelWidth, y, value, style)
+		y++
+	}
+
+	row("Project", orDash(session.Cwd), "")
+	row("Session", session.Path, "")
+	row("Model", orDash(session.Model), StyleCyan)
+	if session.Err != nil {
+		row("Error", session.Err.Error(), StyleRed)
+		return y + 1
+	}
+	y++
+
+	valueWidth := max(screen.Width-labelWidth-2, 0)
+	info := session.Context
+	summary := fmt.Sprintf(" %d/%d %.1f%%", info.CurrentTokens, info.MaxTokens, info.Percentage)
+	gaugeWidth := max(valueWidth-len(summary), 0)
+	style := levelStyle(calculator.GetUsageLevel(info.Percentage))
+	screen.Put(1, y, "Context", StyleDim)
+	x := screen.Put(1+labelWidth, y, Gauge(info.Percentage, gaugeWidth), style)
+	screen.Put(x, y, summary, style)
+	y++
+
+	row("History", Sparkline(session.History, valueWidth, info.MaxTokens), style)
+	y++
+
+	row("Cost", fmt.Sprintf("%s   cache hit %.1f%%   burn %s/h   %s tok/min",
+		formatter.FormatCost(session.Cost),
+		session.CacheRatio*100,
+		formatter.FormatCost(session.Burn.CostPerHour),
+		formatter.FormatTokens(int64(session.Burn.TokensPerMinute)),
+	), "")
+	row("Activity", fmt.Sprintf("%d API calls   %d compactions   last %s",
+		session.Calls,
+		session.Compactions,
+		lastActivity(session.LastActivity, now),
+	), "")
+	return y + 1
+}
+
+// drawOverview lists every session with a compact gauge, risk at a glance
+func (d *Dashboard) drawOverview(screen *Screen, y int, now time.Time) {
+	if y >= screen.Height-2 {
+		return
+	}
+	screen.Put(1, y, "All sessions", StyleBold)
+	y++
+
+	for i, session := range d.Sessions {
+		if y >= screen.Height-1 {
+			break
+		}
+		marker := "  "
+		if i == d.Selected {
+			marker = "> "
+		}
+		info := session.Context
+		x := screen.Put(1, y, marker+padRight(session.Name, 20), "")
+		x = screen.Put(x+1, y, Gauge(info.Percentage, 20), levelStyle(calculator.GetUsageLevel(info.Percentage)))
+		screen.Put(x+1, y, fmt.Sprintf("%5.1f%%  %8s  %s",
+			info.Percentage,
+			formatter.FormatCost(session.Cost),
+			lastActivity(session.LastActivity, now),
+		), "")
+		y++
+	}
+}
+
+func (d *Dashboard) drawHelp(screen *Screen) {
+	screen.Put(1, screen.Height-1, "←/→ tab: switch   1-9: select   q: quit", StyleDim)
+}
+
+func lastActivity(ts, now time.Time) string {
+	if ts.IsZero() {
+		return "-"
+	}
+	return formatter.FormatDuration(now.Sub(ts)) + " ago"
+}
+
+func padRight(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,170 @@
+package tui
+
+import (
+	"ccstatus/internal/parser"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const dashboardTranscript = `{"type":"user","timestamp":"2025-10-01T10:00:00Z","cwd":"/home/me/work/ccstatus","message":{"role":"user","content":"hi"}}
+{"type":"assistant","timestamp":"2025-10-01T10:00:05Z","cwd":"/home/me/work/ccstatus","requestId":"r1","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":1000,"cache_read_input_tokens":99000,"output_tokens":100}}}
+{"type":"assistant","timestamp":"2025-10-01T10:00:05Z","cwd":"/home/me/work/ccstatus","requestId":"r1","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":1000,"cache_read_input_tokens":99000,"output_tokens":100}}}
+{"type":"assistant","timestamp":"2025-10-01T10:00:06Z","cwd":"/home/me/work/ccstatus","isSidechain":true,"requestId":"r2","message":{"id":"m2","role":"assistant","model":"claude-haiku-4-5","usage":{"input_tokens":5000}}}
+{"type":"assistant","timestamp":"2025-10-01T10:10:00Z","cwd":"/home/me/work/ccstatus","requestId":"r3","message":{"id":"m3","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":10,"cache_read_input_tokens":160000}}}
+`
+
+var dashboardNow = time.Date(2025, 10, 1, 10, 12, 0, 0, time.UTC)
+
+func testView(t *testing.T) *SessionView {
+	t.Helper()
+	turns, err := parser.ReadTurns(strings.NewReader(dashboardTranscript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSessionView("/tmp/projects/-home-me-work-ccstatus/abc.jsonl", turns, dashboardNow)
+}
+
+func TestNewSessionView(t *testing.T) {
+	view := testView(t)
+
+	if view.Name != "ccstatus" {
+		t.Errorf("Name = %q, want ccstatus", view.Name)
+	}
+	if view.Model != "claude-sonnet-4-5" {
+		t.Errorf("Model = %q, want claude-sonnet-4-5", view.Model)
+	}
+	if view.Context.CurrentTokens != 160010 {
+		t.Errorf("Context.CurrentTokens = %d, want 160010", view.Context.CurrentTokens)
+	}
+	// sidechain and duplicate lines are left out of the history
+	if len(view.History) != 2 || view.History[0] != 100000 || view.History[1] != 160010 {
+		t.Errorf("History = %v, want [100000 160010]", view.History)
+	}
+	if view.Calls != 2 {
+		t.Errorf("Calls = %d, want 2", view.Calls)
+	}
+	if !view.LastActivity.Equal(time.Date(2025, 10, 1, 10, 10, 0, 0, time.UTC)) {
+		t.Errorf("LastActivity = %v, want 10:10", view.LastActivity)
+	}
+	// cache reads over all input-side tokens, sidechain included
+	wantRatio := 259000.0 / (1000 + 99000 + 5000 + 10 + 160000)
+	if math.Abs(view.CacheRatio-wantRatio) > 1e-9 {
+		t.Errorf("CacheRatio = %v, want %v", view.CacheRatio, wantRatio)
+	}
+	if view.Burn.CostPerHour <= 0 {
+		t.Errorf("Burn.CostPerHour = %v, want positive", view.Burn.CostPerHour)
+	}
+}
+
+func TestDashboardDraw(t *testing.T) {
+	other := &SessionView{Name: "other", Path: "/tmp/other.jsonl"}
+	dashboard := &Dashboard{Sessions: []*SessionView{testView(t), other}}
+	screen := NewScreen(80, 24)
+	dashboard.Draw(screen, dashboardNow)
+	text := screen.String()
+
+	for _, want := range []string{
+		"ccstatus watch · 2 sessions",
+		"10:12:00",
+		"1:ccstatus",
+		"2:other",
+		"/home/me/work/ccstatus",
+		"claude-sonnet-4-5",
+		"160010/200000 80.0%",
+		"▅▇",
+		"cache hit",
+		"2 API calls",
+		"last 2m ago",
+		"> ccstatus",
+		"q: quit",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Draw() does not contain %q, got:\n%s", want, text)
+		}
+	}
+
+	lines := screen.Lines()
+	if !strings.HasPrefix(lines[23], " ←/→") {
+		t.Errorf("last line = %q, want key help", lines[23])
+	}
+	for y, line := range lines {
+		if n := len([]rune(line)); n > 80 {
+			t.Errorf("line %d is %d cells wide, want at most 80", y, n)
+		}
+	}
+
+	// the context gauge of a red session is drawn in red
+	for x := 0; x < screen.Width; x++ {
+		if cell := screen.Cell(x, 7); cell.Rune == '█' {
+			if cell.Style != StyleRed {
+				t.Errorf("gauge style = %q, want red", cell.Style)
+			}
+			break
+		}
+	}
+}
+
+func TestDashboardDrawEmpty(t *testing.T) {
+	screen := NewScreen(60, 5)
+	(&Dashboard{}).Draw(screen, dashboardNow)
+	if !strings.Contains(screen.String(), "no active transcripts") {
+		t.Errorf("Draw() = %q, want waiting notice", screen.String())
+	}
+}
+
+func TestDashboardHandleKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		keys         []string
+		wantSelected int
+		wantQuit     bool
+	}{
+		{"next", []string{KeyRight}, 1, false},
+		{"wraps forward", []string{KeyTab, KeyTab, KeyTab}, 0, false},
+		{"wraps backward", []string{KeyLeft}, 2, false},
+		{"vim keys", []string{"j", "j", "k"}, 1, false},
+		{"digit selects", []string{"3"}, 2, false},
+		{"digit out of range ignored", []string{"9"}, 0, false},
+		{"quit", []string{"q"}, 0, true},
+		{"ctrl-c quits", []string{KeyCtrlC}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dashboard := &Dashboard{Sessions: []*SessionView{{}, {}, {}}}
+			quit := false
+			for _, key := range tt.keys {
+				quit = dashboard.HandleKey(key)
+			}
+			if dashboard.Selected != tt.wantSelected {
+				t.Errorf("Selected = %d, want %d", dashboard.Selected, tt.wantSelected)
+			}
+			if quit != tt.wantQuit {
+				t.Errorf("HandleKey() = %v, want %v", quit, tt.wantQuit)
+			}
+		})
+	}
+}
+
+func TestDashboardSetSessions(t *testing.T) {
+	a, b, c := &SessionView{Path: "/p/a.jsonl"}, &SessionView{Path: "/p/b.jsonl"}, &SessionView{Path: "/p/c.jsonl"}
+	dashboard := &Dashboard{Sessions: []*SessionView{a, b, c}, Selected: 1}
+
+	// another session was written and moved to the top, b stays selected
+	dashboard.SetSessions([]*SessionView{c, a, b})
+	if dashboard.Selected != 2 {
+		t.Errorf("Selected = %d after reorder, want 2", dashboard.Selected)
+	}
+
+	// the selected session went inactive, the index is kept and clamped on draw
+	dashboard.SetSessions([]*SessionView{c, a})
+	if dashboard.Selected != 2 {
+		t.Errorf("Selected = %d after removal, want 2", dashboard.Selected)
+	}
+	dashboard.Draw(NewScreen(60, 20), dashboardNow)
+	if dashboard.Selected != 1 {
+		t.Errorf("Selected = %d after draw, want 1", dashboard.Selected)
+	}
+}
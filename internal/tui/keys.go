@@ -0,0 +1,48 @@
+package tui
+
+// key names produced by ParseKeys
+const (
+	KeyUp       = "up"
+	KeyDown     = "down"
+	KeyLeft     = "left"
+	KeyRight    = "right"
+	KeyTab      = "tab"
+	KeyShiftTab = "shift-tab"
+	KeyCtrlC    = "ctrl-c"
+	KeyEscape   = "esc"
+)
+
+// ParseKeys splits raw terminal input into key names
+// printable characters are returned as themselves, unknown sequences are dropped
+func ParseKeys(data []byte) []string {
+	var keys []string
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch {
+		case b == 0x1b:
+			if i+2 < len(data) && (data[i+1] == '[' || data[i+1] == 'O') {
+				if key, ok := arrowKeys[data[i+2]]; ok {
+					keys = append(keys, key)
+				}
+				i += 2
+				continue
+			}
+			keys = append(keys, KeyEscape)
+		case b == 0x03:
+			keys = append(keys, KeyCtrlC)
+		case b == '\t':
+			keys = append(keys, KeyTab)
+		case b >= 0x20 && b < 0x7f:
+			keys = append(keys, string(rune(b)))
+		}
+	}
+	return keys
+}
+
+var arrowKeys = map[byte]string{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'Z': KeyShiftTab,
+}
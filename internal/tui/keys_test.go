@@ -0,0 +1,31 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"letters", "jq", []string{"j", "q"}},
+		{"arrows", "\033[A\033[B\033[C\033[D", []string{KeyUp, KeyDown, KeyRight, KeyLeft}},
+		{"application mode arrows", "\033OC", []string{KeyRight}},
+		{"tab and shift tab", "\t\033[Z", []string{KeyTab, KeyShiftTab}},
+		{"ctrl-c", "\x03", []string{KeyCtrlC}},
+		{"bare escape", "\033", []string{KeyEscape}},
+		{"unknown sequence dropped", "\033[H2", []string{"2"}},
+		{"control bytes ignored", "\x01\r", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseKeys([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseKeys(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package tui
+
+import "os"
+
+// NotifyResize is a no-op where there is no SIGWINCH, the size is read once
+func NotifyResize(c chan<- os.Signal) {}
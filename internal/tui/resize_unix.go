@@ -0,0 +1,14 @@
+//go:build unix
+
+package tui
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// NotifyResize relays terminal size changes to c, stop it with signal.Stop
+func NotifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ANSI sequences used by the dashboard
+const (
+	styleReset    = "\033[0m"
+	StyleBold     = "\033[1m"
+	StyleDim      = "\033[2m"
+	StyleReverse  = "\033[7m"
+	StyleGreen    = "\033[32m"
+	StyleYellow   = "\033[33m"
+	StyleRed      = "\033[31m"
+	StyleCyan     = "\033[36m"
+	clearLineTail = "\033[K"
+	cursorHome    = "\033[H"
+)
+
+// Cell is a single character on the screen
+type Cell struct {
+	Rune  rune
+	Style string
+}
+
+// Screen is a virtual terminal buffer; widgets draw into it and Render
+// writes the whole frame at once, tests inspect it through Lines
+type Screen struct {
+	Width  int
+	Height int
+	cells  [][]Cell
+}
+
+// NewScreen returns a blank screen of the given size
+func NewScreen(width, height int) *Screen {
+	s := &Screen{}
+	s.Resize(width, height)
+	return s
+}
+
+// Resize changes the screen size and clears it
+func (s *Screen) Resize(width, height int) {
+	s.Width = max(width, 0)
+	s.Height = max(height, 0)
+	s.cells = make([][]Cell, s.Height)
+	for y := range s.cells {
+		s.cells[y] = make([]Cell, s.Width)
+	}
+	s.Clear()
+}
+
+// Clear fills the screen with blanks
+func (s *Screen) Clear() {
+	for y := range s.cells {
+		for x := range s.cells[y] {
+			s.cells[y][x] = Cell{Rune: ' '}
+		}
+	}
+}
+
+// Put writes text at x, y with style and returns the column after the text
+// text outside the screen is clipped
+func (s *Screen) Put(x, y int, text, style string) int {
+	if y < 0 || y >= s.Height {
+		return x + utf8.RuneCountInString(text)
+	}
+	for _, r := range text {
+		if x >= 0 && x < s.Width {
+			s.cells[y][x] = Cell{Rune: r, Style: style}
+		}
+		x++
+	}
+	return x
+}
+
+// Fill paints a horizontal run of the same rune
+func (s *Screen) Fill(x, y, width int, r rune, style string) {
+	s.Put(x, y, strings.Repeat(string(r), max(width, 0)), style)
+}
+
+// Cell returns the cell at x, y, blank when out of range
+func (s *Screen) Cell(x, y int) Cell {
+	if y < 0 || y >= s.Height || x < 0 || x >= s.Width {
+		return Cell{Rune: ' '}
+	}
+	return s.cells[y][x]
+}
+
+// Lines returns the screen content without styles, trailing blanks trimmed
+func (s *Screen) Lines() []string {
+	lines := make([]string, s.Height)
+	for y, row := range s.cells {
+		var b strings.Builder
+		for _, cell := range row {
+			b.WriteRune(cell.Rune)
+		}
+		lines[y] = strings.TrimRight(b.String(), " ")
+	}
+	return lines
+}
+
+// String returns the screen content as newline-separated lines
+func (s *Screen) String() string {
+	return strings.Join(s.Lines(), "\n")
+}
+
+// Render writes the frame with ANSI styles, overwriting the previous frame in place
+func (s *Screen) Render(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(cursorHome)
+	for y, row := range s.cells {
+		current := ""
+		for _, cell := range row {
+			if cell.Style != current {
+				b.WriteString(styleReset)
+				b.WriteString(cell.Style)
+				current = cell.Style
+			}
+			b.WriteRune(cell.Rune)
+		}
+		b.WriteString(styleReset)
+		b.WriteString(clearLineTail)
+		if y < len(s.cells)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
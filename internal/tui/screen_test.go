@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScreenPut(t *testing.T) {
+	tests := []struct {
+		name      string
+		x, y      int
+		text      string
+		wantLines []string
+		wantNext  int
+	}{
+		{
+			name:      "inside",
+			x:         1,
+			y:         0,
+			text:      "ab",
+			wantLines: []string{" ab", ""},
+			wantNext:  3,
+		},
+		{
+			name:      "clipped right",
+			x:         3,
+			y:         1,
+			text:      "abcd",
+			wantLines: []string{"", "   ab"},
+			wantNext:  7,
+		},
+		{
+			name:      "clipped left",
+			x:         -2,
+			y:         0,
+			text:      "abcd",
+			wantLines: []string{"cd", ""},
+			wantNext:  2,
+		},
+		{
+			name:      "row outside",
+			x:         0,
+			y:         5,
+			text:      "ab",
+			wantLines: []string{"", ""},
+			wantNext:  2,
+		},
+		{
+			name:      "multibyte runes take one cell",
+			x:         0,
+			y:         0,
+			text:      "█░▁",
+			wantLines: []string{"█░▁", ""},
+			wantNext:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			screen := NewScreen(5, 2)
+			next := screen.Put(tt.x, tt.y, tt.text, StyleBold)
+			if next != tt.wantNext {
+				t.Errorf("Put() = %d, want %d", next, tt.wantNext)
+			}
+			if got := screen.Lines(); !reflect.DeepEqual(got, tt.wantLines) {
+				t.Errorf("Lines() = %q, want %q", got, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestScreenRender(t *testing.T) {
+	screen := NewScreen(4, 2)
+	screen.Put(0, 0, "ab", StyleRed)
+	screen.Put(0, 1, "cd", "")
+
+	var buf bytes.Buffer
+	if err := screen.Render(&buf); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{cursorHome, StyleRed + "ab", "\r\n", clearLineTail} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() does not contain %q, got %q", want, got)
+		}
+	}
+	if strings.HasSuffix(got, "\r\n") {
+		t.Errorf("Render() ends with a newline, the terminal would scroll: %q", got)
+	}
+}
+
+func TestScreenResize(t *testing.T) {
+	screen := NewScreen(2, 1)
+	screen.Put(0, 0, "ab", "")
+	screen.Resize(3, 2)
+
+	if screen.Width != 3 || screen.Height != 2 {
+		t.Errorf("Resize() size = %dx%d, want 3x2", screen.Width, screen.Height)
+	}
+	if got := screen.Cell(0, 0); got.Rune != ' ' {
+		t.Errorf("Resize() kept content %q, want blank", got.Rune)
+	}
+	if got := screen.Cell(10, 10); got.Rune != ' ' {
+		t.Errorf("Cell() out of range = %q, want blank", got.Rune)
+	}
+}
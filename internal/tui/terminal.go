@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// terminal control sequences for the full-screen mode
+const (
+	enterAltScreen = "\033[?1049h\033[?25l\033[2J"
+	leaveAltScreen = "\033[?25h\033[?1049l"
+)
+
+// Terminal switches a tty into raw full-screen mode and back
+// stty is used instead of ioctls so the package stays free of platform code
+type Terminal struct {
+	in    *os.File
+	out   io.Writer
+	saved string
+}
+
+// OpenTerminal puts in into raw mode and switches out to the alternate screen
+func OpenTerminal(in *os.File, out io.Writer) (*Terminal, error) {
+	saved, err := stty(in, "-g")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terminal mode: %w", err)
+	}
+	if _, err := stty(in, "raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("failed to enable raw mode: %w", err)
+	}
+	t := &Terminal{in: in, out: out, saved: strings.TrimSpace(saved)}
+	io.WriteString(out, enterAltScreen)
+	return t, nil
+}
+
+// Size returns the terminal width and height
+func (t *Terminal) Size() (int, int, error) {
+	out, err := stty(t.in, "size")
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read terminal size: %w", err)
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected stty size output %q", out)
+	}
+	height, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid terminal height: %w", err)
+	}
+	width, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid terminal width: %w", err)
+	}
+	return width, height, nil
+}
+
+// Close restores the original terminal mode and screen
+func (t *Terminal) Close() error {
+	io.WriteString(t.out, leaveAltScreen)
+	if _, err := stty(t.in, t.saved); err != nil {
+		return fmt.Errorf("failed to restore terminal mode: %w", err)
+	}
+	return nil
+}
+
+// stty runs stty against the terminal attached to in
+func stty(in *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = in
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"math"
+	"strings"
+)
+
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// Gauge renders a horizontal bar of width cells filled to percentage (0-100)
+func Gauge(percentage float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	percentage = math.Max(0, math.Min(percentage, 100))
+	filled := int(math.Round(percentage / 100 * float64(width)))
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// Sparkline renders the last width values scaled against limit
+// a fixed scale keeps drops after compaction visible instead of re-normalizing
+func Sparkline(values []int64, width int, limit int64) string {
+	if width <= 0 || len(values) == 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	if limit <= 0 {
+		for _, v := range values {
+			limit = max(limit, v)
+		}
+		limit = max(limit, 1)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		ratio := math.Max(0, math.Min(float64(v)/float64(limit), 1))
+		index := int(math.Round(ratio * float64(len(sparkRunes)-1)))
+		b.WriteRune(sparkRunes[index])
+	}
+	return b.String()
+}
+
+// levelStyle maps a calculator usage level to a screen style
+func levelStyle(level string) string {
+	switch level {
+	case "green":
+		return StyleGreen
+	case "yellow":
+		return StyleYellow
+	case "red":
+		return StyleRed
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,52 @@
+package tui
+
+import "testing"
+
+func TestGauge(t *testing.T) {
+	tests := []struct {
+		name       string
+		percentage float64
+		width      int
+		want       string
+	}{
+		{"empty", 0, 4, "░░░░"},
+		{"half", 50, 4, "██░░"},
+		{"full", 100, 4, "████"},
+		{"over limit clamped", 150, 4, "████"},
+		{"negative clamped", -5, 4, "░░░░"},
+		{"zero width", 50, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Gauge(tt.percentage, tt.width); got != tt.want {
+				t.Errorf("Gauge(%v, %d) = %q, want %q", tt.percentage, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int64
+		width  int
+		limit  int64
+		want   string
+	}{
+		{"fixed scale", []int64{0, 50, 100}, 10, 100, "▁▅█"},
+		{"compaction drop stays visible", []int64{100, 10}, 10, 100, "█▂"},
+		{"keeps latest values", []int64{0, 0, 100, 100}, 2, 100, "██"},
+		{"auto scale without limit", []int64{5, 10}, 10, 0, "▅█"},
+		{"above limit clamped", []int64{200}, 10, 100, "█"},
+		{"no values", nil, 10, 100, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sparkline(tt.values, tt.width, tt.limit); got != tt.want {
+				t.Errorf("Sparkline(%v, %d, %d) = %q, want %q", tt.values, tt.width, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"ccstatus/internal/config"
+	"ccstatus/internal/logging"
+	"ccstatus/internal/parser"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// followInterval is how often `ccstatus logs --follow` polls the log
+const followInterval = 200 * time.Millisecond
+
+// runLogs implements `ccstatus logs`: prints the end of the log file, optionally following it
+func runLogs(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("logs", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	lines := flags.Int("n", 50, "number of lines to print")
+	follow := flags.Bool("follow", false, "keep printing records as they are written")
+	flags.BoolVar(follow, "f", false, "shorthand for --follow")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+	if *lines < 0 {
+		return fmt.Errorf("-n must not be negative")
+	}
+
+	path := logging.Path()
+	offset := logging.Size(path)
+	tail, err := logging.Tail(path, *lines)
+	if err != nil {
+		return err
+	}
+	for _, line := range tail {
+		fmt.Fprintln(stdout, line)
+	}
+	if !*follow {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return logging.Follow(ctx, stdout, path, offset, followInterval)
+}
+
+// openLog opens the log file at the level the config and environment ask for,
+// the file is only written when a warning or, with debug on, any record is logged
+func openLog(cfg *config.Config) *logging.Logger {
+	return logging.Open(logging.Path(), logging.Level(cfg.Debug))
+}
+
+// logPhase records how long a phase of the status line run took
+func logPhase(logger *logging.Logger, name string, start time.Time) {
+	logger.Debug("phase", "name", name, "took", time.Since(start))
+}
+
+// logSession records how the transcript was resolved and which usage entry was chosen
+func logSession(logger *logging.Logger, transcriptPath string, session *parser.Session) {
+	if session == nil {
+		return
+	}
+	logger.Debug("transcript",
+		"path", transcriptPath,
+		"resolved", session.Path,
+		"lines", session.Lines,
+		"skipped", session.Skipped,
+		"api_errors", session.APIErrors,
+		"interruptions", session.Interruptions,
+	)
+	if session.UsageLine == 0 {
+		logger.Debug("usage", "line", 0, "found", false)
+		return
+	}
+	logger.Debug("usage",
+		"line", session.UsageLine,
+		"input_tokens", session.Usage.InputTokens,
+		"cache_read_input_tokens", session.Usage.CacheReadInputTokens,
+		"cache_creation_input_tokens", session.Usage.CacheCreationInputTokens,
+		"output_tokens", session.Usage.OutputTokens,
+	)
+}
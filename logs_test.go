@@ -0,0 +1,82 @@
+package main
+
+import (
+	"ccstatus/internal/logging"
+	"ccstatus/internal/state"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunLogs(t *testing.T) {
+	t.Setenv(state.EnvDir, t.TempDir())
+	if err := os.WriteFile(logging.Path(), []byte("one\ntwo\nthree\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: "one\ntwo\nthree\n"},
+		{name: "last lines", args: []string{"-n", "2"}, want: "two\nthree\n"},
+		{name: "negative count", args: []string{"-n", "-1"}, wantErr: true},
+		{name: "arguments", args: []string{"extra"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			err := runLogs(tt.args, &stdout, &stderr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runLogs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("runLogs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunDebugLog(t *testing.T) {
+	transcript := fallbackEnv(t)
+
+	// logging is off by default, a run without warnings does not even create the file
+	if _, err := runFallback(transcript); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(logging.Path()); !os.IsNotExist(err) {
+		t.Fatalf("Stat() error = %v without debug enabled, want no log file", err)
+	}
+
+	t.Setenv(logging.EnvDebug, "1")
+	got, err := runFallback(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "level=") {
+		t.Errorf("status line = %q, debug records leaked to stdout", got)
+	}
+
+	data, err := os.ReadFile(logging.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	for _, want := range []string{
+		`msg=input payload=`,
+		`msg=transcript path=` + transcript + ` resolved=` + filepath.Clean(transcript) + ` lines=`,
+		`skipped=0`,
+		`msg=usage line=`,
+		`msg=context model=claude-sonnet-4-5 limit=200000 limit_source=exact tokens=30000`,
+		`msg=segment name=context`,
+		`msg=phase name=parse took=`,
+		`msg=phase name=total took=`,
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("log does not contain %q, got:\n%s", want, log)
+		}
+	}
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"bytes"
 	"ccstatus/internal/calculator"
+	"ccstatus/internal/config"
 	"ccstatus/internal/formatter"
-	"ccstatus/internal/parser"
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 )
 
 // ModelInfo represents model information from Claude Code
@@ -16,55 +22,284 @@ type ModelInfo struct {
 	DisplayName string `json:"display_name"`
 }
 
+// WorkspaceInfo represents workspace directories from Claude Code
+type WorkspaceInfo struct {
+	CurrentDir string `json:"current_dir"`
+	ProjectDir string `json:"project_dir"`
+}
+
 // StatusInput represents JSON input from Claude Code via stdin
 type StatusInput struct {
-	SessionID      string    `json:"session_id"`
-	Cwd            string    `json:"cwd"`
-	Model          ModelInfo `json:"model"`
-	TranscriptPath string    `json:"transcript_path"`
+	SessionID      string        `json:"session_id"`
+	Cwd            string        `json:"cwd"`
+	Model          ModelInfo     `json:"model"`
+	Workspace      WorkspaceInfo `json:"workspace"`
+	TranscriptPath string        `json:"transcript_path"`
+}
+
+// command implements a ccstatus subcommand
+type command func(args []string, stdout, stderr io.Writer) error
+
+// commands maps subcommand names to implementations,
+// without a subcommand ccstatus runs as a status line filter
+var commands = map[string]command{
+	"doctor":        runDoctor,
+	"history":       runHistory,
+	"install":       runInstall,
+	"logs":          runLogs,
+	"report":        runReport,
+	"serve-metrics": runServeMetrics,
+	"session":       runSession,
+	"sessions":      runSessions,
+	"watch":         runWatch,
 }
 
 func main() {
-	if err := run(os.Stdin, os.Stdout); err != nil {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:], os.Stdout, os.Stderr); err != nil {
+				if errors.Is(err, flag.ErrHelp) {
+					return
+				}
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	if err := runStatusLine(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// status line output modes, the formatter dialects are accepted as well
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// statusOptions control a single status line run
+type statusOptions struct {
+	// now is the time the status line is rendered for
+	now    time.Time
+	output string
+	// sessionID, project and latest pick the transcript instead of the payload
+	// transcript_path, for use outside Claude Code
+	sessionID string
+	project   string
+	latest    bool
+	// roots are allowed transcript locations on top of the configured ones
+	roots []string
+	// dryRun renders without side effects: no metrics, history, notifications
+	// or last-good snapshot, for replays and diagnostics
+	dryRun bool
+	// resolved receives the payload with the transcript the run picked, for recordings
+	resolved *StatusInput
+}
+
 // run is the main logic, separated for testing
 func run(stdin io.Reader, stdout io.Writer) error {
-	// read JSON input from stdin
-	var input StatusInput
-	decoder := json.NewDecoder(stdin)
-	if err := decoder.Decode(&input); err != nil {
-		return fmt.Errorf("failed to decode input: %w", err)
+	return runWith(stdin, stdout, statusOptions{now: time.Now(), output: outputText})
+}
+
+// runWith renders the status line in the requested output mode
+func runWith(stdin io.Reader, stdout io.Writer, opts statusOptions) error {
+	start := time.Now()
+
+	// a broken config must not break the status line, fall back to defaults
+	cfg, err := config.Load(config.Path())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
 	}
 
-	// validate input
-	if input.TranscriptPath == "" {
-		return fmt.Errorf("transcript_path is empty")
+	// the log never goes to stdout, that is the status line
+	logger := openLog(cfg)
+	defer logger.Close()
+	logPhase(logger, "config", start)
+
+	// the deadline bounds the status line only, JSON output is for scripts that can wait;
+	// it counts from the start, reading the payload and finding the transcript are covered too
+	deadline := cfg.Deadline.Std()
+	if opts.output == outputJSON {
+		deadline = 0
 	}
+	ctx, cancel := deadlineContext(start, deadline)
+	defer cancel()
 
-	// parse transcript to get usage
-	usage, err := parser.ParseTranscript(input.TranscriptPath)
-	if err != nil {
-		// show explicit error instead of silent degradation
-		fmt.Fprint(stdout, formatter.FormatError(fmt.Sprintf("parse error: %v", err)))
+	// read JSON input from stdin, keeping a copy of the payload for the log
+	phase := time.Now()
+	var payload bytes.Buffer
+	var input StatusInput
+	decoder := json.NewDecoder(io.TeeReader(stdin, &payload))
+	// no payload at all is fine, the transcript is resolved without one
+	if err := decoder.Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		err = fmt.Errorf("failed to decode input: %w", err)
+		logger.Warn("invalid input", "error", err, "payload", payload.String())
+		if opts.output == outputJSON {
+			fmt.Fprint(stdout, formatter.FormatJSON(errorDocument(&input, opts.now, formatter.ErrorInvalidInput, err)))
+		}
 		return err
 	}
+	logger.Debug("input", "payload", payload.String())
+	logPhase(logger, "decode", phase)
 
-	// extract model name
+	// find the transcript when the payload has none or a flag picks another one
+	// globbing the projects dirs may stall on slow storage, a missed deadline leaves
+	// the transcript unresolved and the segments fall back like on a missed parse
+	resolved, err := withDeadline(ctx, func() (StatusInput, error) {
+		resolved := input
+		err := resolveTranscript(&resolved, opts)
+		return resolved, err
+	})
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		logger.Debug("transcript lookup missed the deadline", "deadline", deadline)
+	case err != nil:
+		logger.Warn("invalid input", "error", err)
+		if opts.output == outputJSON {
+			fmt.Fprint(stdout, formatter.FormatJSON(errorDocument(&input, opts.now, formatter.ErrorMissingPath, err)))
+		}
+		return err
+	default:
+		input = resolved
+	}
+	logger.Debug("transcript selected", "session", input.SessionID, "cwd", input.Cwd, "path", input.TranscriptPath)
+	if opts.resolved != nil {
+		*opts.resolved = input
+	}
+
+	// parse transcript to get usage and timestamps
+	// on a missed deadline or a failure session is nil and the segments that need it fall back
+	phase = time.Now()
+	policy := transcriptPolicy(cfg, opts.roots)
+	session, parseErr := parseSessionWithin(ctx, policy, input.TranscriptPath)
+	logPhase(logger, "parse", phase)
+	if parseErr != nil && opts.output == outputJSON {
+		logger.Warn("transcript parse failed", "code", classifyParseError(parseErr), "session", input.SessionID, "transcript", input.TranscriptPath, "error", parseErr)
+		fmt.Fprint(stdout, formatter.FormatJSON(errorDocument(&input, opts.now, formatter.ErrorParse, parseErr)))
+		return parseErr
+	}
+	if errors.Is(parseErr, context.DeadlineExceeded) {
+		logger.Debug("transcript parse missed the deadline", "deadline", deadline)
+		parseErr = nil
+	}
+	logSession(logger, input.TranscriptPath, session)
+
+	// extract model name, without a payload the transcript tells the model
 	model := input.Model.ID
+	if model == "" && session != nil {
+		model = session.Model
+	}
 	if model == "" {
 		model = "claude"
 	}
 
 	// calculate context info with model-specific limits
-	info := calculator.Calculate(usage, model)
+	now := opts.now
+	status := &statusContext{
+		cfg:     cfg,
+		input:   &input,
+		model:   model,
+		now:     now,
+		ctx:     ctx,
+		session: session,
+		log:     logger,
+		policy:  policy,
+		dryRun:  opts.dryRun,
+	}
+	if session != nil {
+		status.info = calculator.CalculateSession(session, model)
+		status.times = calculator.CalculateTimes(session, now, cfg.Session.IdleThreshold.Std())
+		logger.Debug("context",
+			"model", model,
+			"limit", status.info.MaxTokens,
+			"limit_source", calculator.ModelLimitSource(model),
+			"tokens", status.info.CurrentTokens,
+		)
+	}
 
-	// format and output
-	output := formatter.Format(info, model)
-	fmt.Fprint(stdout, output)
+	phase = time.Now()
+	if opts.output == outputJSON {
+		fmt.Fprint(stdout, formatter.FormatJSON(status.document(session)))
+	} else {
+		var code string
+		switch {
+		case parseErr != nil:
+			code = classifyParseError(parseErr)
+		case status.lostUsage():
+			code = codeEmpty
+			parseErr = errors.New("transcript has no usage although the session had some")
+			status.session = nil
+		}
+		// dialects translate colors, so they are rendered even without a terminal
+		if formatter.IsDialect(opts.output) && opts.output != formatter.DialectStarship {
+			formatter.ForceColors(true)
+			defer formatter.ForceColors(false)
+		}
+		if code != "" {
+			status.logParseFailure(code, parseErr)
+			// with nothing to fall back to, show the error explicitly instead of silent degradation
+			if !status.hasSnapshot() {
+				fmt.Fprint(stdout, formatter.Translate(formatter.FormatError(fmt.Sprintf("parse error: %v", parseErr)), opts.output))
+				return parseErr
+			}
+		}
 
+		// format and output
+		segments := status.buildSegments()
+		if code != "" {
+			segments = append(segments, formatter.FormatErrorCode(code))
+		}
+		fmt.Fprint(stdout, formatter.Translate(strings.Join(segments, " "), opts.output))
+	}
+	logPhase(logger, "render", phase)
+
+	// side effects come after the output and are skipped once the deadline passed
+	// or without a parsed transcript, history and notifications catch up on the next run
+	if ctx.Err() != nil || status.session == nil || opts.dryRun {
+		logPhase(logger, "total", start)
+		return nil
+	}
+	phase = time.Now()
+	if err := status.recordMetrics(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	logPhase(logger, "metrics", phase)
+	phase = time.Now()
+	if err := status.recordHistory(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	logPhase(logger, "history", phase)
+	phase = time.Now()
+	if err := status.notify(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	logPhase(logger, "notify", phase)
+	logPhase(logger, "total", start)
 	return nil
 }
+
+// parseFlags parses flags that may appear before or after positional arguments
+// and returns the positional arguments
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
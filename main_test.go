@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testRoots allows the transcripts under testdata in status line runs
+var testRoots = []string{"testdata"}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		wantPositional []string
+		wantFormat     string
+	}{
+		{
+			name:           "flags before positional",
+			args:           []string{"--format", "json", "abc"},
+			wantPositional: []string{"abc"},
+			wantFormat:     "json",
+		},
+		{
+			name:           "flags after positional",
+			args:           []string{"abc", "--format=json"},
+			wantPositional: []string{"abc"},
+			wantFormat:     "json",
+		},
+		{
+			name:           "double dash ends flags",
+			args:           []string{"abc", "--", "--format"},
+			wantPositional: []string{"abc", "--format"},
+			wantFormat:     "table",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags.SetOutput(io.Discard)
+			format := flags.String("format", "table", "")
+
+			got, err := parseFlags(flags, tt.args)
+			if err != nil {
+				t.Fatalf("parseFlags() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.wantPositional) {
+				t.Errorf("parseFlags() = %v, want %v", got, tt.wantPositional)
+			}
+			if *format != tt.wantFormat {
+				t.Errorf("format = %q, want %q", *format, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestRunDialects(t *testing.T) {
+	transcript := fallbackEnv(t)
+	input := `{"session_id":"af99e13e","cwd":"/work/app","model":{"id":"claude-sonnet-4-5"},"transcript_path":"` + transcript + `"}`
+
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{output: "tmux", want: "#[fg=green][ctx: 30000/200000 15.0%]#[default] #[fg=cyan]claude-sonnet-4-5#[default]"},
+		{output: "zsh", want: "%F{green}[ctx: 30000/200000 15.0%%]%f%{\033[0m%} %F{cyan}claude-sonnet-4-5%f%{\033[0m%}"},
+		{output: "bash", want: `\[\e[32m\][ctx: 30000/200000 15.0%]\[\e[0m\] \[\e[36m\]claude-sonnet-4-5\[\e[0m\]`},
+		{output: "starship", want: "[ctx: 30000/200000 15.0%] claude-sonnet-4-5 /work/app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			if err := runStatusLine([]string{"--output", tt.output}, strings.NewReader(input), &stdout, &stderr); err != nil {
+				t.Fatalf("runStatusLine() error = %v", err)
+			}
+			if got := stdout.String(); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("runStatusLine() = %q, want prefix %q", got, tt.want)
+			}
+		})
+	}
+
+	var stdout, stderr strings.Builder
+	if err := runStatusLine([]string{"--output", "fish"}, strings.NewReader(input), &stdout, &stderr); err == nil {
+		t.Error("runStatusLine() with an unknown output error = nil, want error")
+	}
+}
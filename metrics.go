@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"ccstatus/internal/config"
+	"ccstatus/internal/metrics"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// recordMetrics stores this run as the latest sample of the session and
+// refreshes the textfile; failures are reported but never affect the status line
+func (s *statusContext) recordMetrics() error {
+	cfg := s.cfg.Metrics
+	if !cfg.Recording() {
+		return nil
+	}
+
+	sample := metrics.Sample{
+		SessionID:         s.input.SessionID,
+		Project:           s.project(),
+		Model:             s.model,
+		UpdatedAt:         s.now,
+		ContextTokens:     s.info.CurrentTokens,
+		ContextLimit:      s.info.MaxTokens,
+		ContextPercentage: s.info.Percentage,
+	}
+	if timeline := s.timeline(); timeline != nil {
+		sample.Cost = timeline.Cost
+		sample.Tokens = timeline.Tokens
+	}
+
+	collector := &metrics.Collector{Dir: metrics.DefaultDir()}
+	if err := collector.Record(sample); err != nil {
+		return fmt.Errorf("failed to record metrics: %w", err)
+	}
+	if cfg.Textfile == "" {
+		return nil
+	}
+	samples, err := collector.Samples(s.now, cfg.Expiry.Std())
+	if err != nil {
+		return err
+	}
+	if err := metrics.WriteTextfile(cfg.Textfile, samples, cfg.MaxSessions); err != nil {
+		return fmt.Errorf("failed to write metrics textfile: %w", err)
+	}
+	return nil
+}
+
+// runServeMetrics implements `ccstatus serve-metrics`: the recorded samples on a local /metrics endpoint
+func runServeMetrics(args []string, stdout, stderr io.Writer) error {
+	cfg, err := config.Load(config.Path())
+	if err != nil {
+		return err
+	}
+	flags := flag.NewFlagSet("serve-metrics", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	listen := flags.String("listen", cfg.Metrics.Listen, "address to listen on")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+	if !cfg.Metrics.Recording() {
+		fmt.Fprintln(stderr, `warning: metrics recording is off, set "metrics": {"enabled": true} in the config`)
+	}
+
+	collector := &metrics.Collector{Dir: metrics.DefaultDir()}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metricsHandler(collector, cfg.Metrics))
+	server := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(stdout, "serving metrics on http://%s/metrics\n", *listen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// metricsHandler renders the live samples on every scrape
+func metricsHandler(collector *metrics.Collector, cfg config.MetricsConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		samples, err := collector.Samples(time.Now(), cfg.Expiry.Std())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var body bytes.Buffer
+		if err := metrics.Write(&body, samples, cfg.MaxSessions); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(body.Bytes())
+	})
+}
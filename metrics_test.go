@@ -0,0 +1,63 @@
+package main
+
+import (
+	"ccstatus/internal/config"
+	"ccstatus/internal/metrics"
+	"ccstatus/internal/state"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunWritesMetricsTextfile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(state.EnvDir, filepath.Join(dir, "state"))
+	textfile := filepath.Join(dir, "ccstatus.prom")
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"metrics":{"textfile":"`+textfile+`"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvPath, configPath)
+
+	input := `{"session_id":"af99e13e","workspace":{"project_dir":"/work/app"},"model":{"id":"claude-sonnet-4-5"},"transcript_path":"testdata/json/transcript.jsonl"}`
+	now := time.Date(2025, 10, 1, 10, 5, 0, 0, time.UTC)
+	if err := runWith(strings.NewReader(input), io.Discard, statusOptions{now: now, output: outputText, roots: testRoots}); err != nil {
+		t.Fatalf("runWith() error = %v", err)
+	}
+
+	data, err := os.ReadFile(textfile)
+	if err != nil {
+		t.Fatalf("textfile not written: %v", err)
+	}
+	labels := `session="af99e13e",project="/work/app",model="claude-sonnet-4-5"`
+	for _, want := range []string{
+		"ccstatus_context_tokens{" + labels + "} 30000\n",
+		"ccstatus_session_cost_usd{" + labels + "} 0.1122\n",
+		"ccstatus_session_tokens_total{" + labels + `,type="output"} 600` + "\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("textfile does not contain %q, got:\n%s", want, data)
+		}
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	collector := &metrics.Collector{Dir: t.TempDir()}
+	if err := collector.Record(metrics.Sample{SessionID: "s1", UpdatedAt: time.Now(), ContextTokens: 42}); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	metricsHandler(collector, config.Default().Metrics).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", got)
+	}
+	if body := recorder.Body.String(); !strings.Contains(body, `ccstatus_context_tokens{session="s1",project="",model=""} 42`) {
+		t.Errorf("body does not contain the sample, got:\n%s", body)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"ccstatus/internal/notify"
+	"ccstatus/internal/state"
+)
+
+// notify runs the configured command for every threshold this run crossed first
+// failures are reported but never affect the status line
+func (s *statusContext) notify() error {
+	cfg := s.cfg.Notify
+	if cfg.Command == "" || (len(cfg.Context) == 0 && len(cfg.Cost) == 0) {
+		return nil
+	}
+
+	values := notify.Values{Context: s.info.Percentage}
+	if len(cfg.Cost) > 0 {
+		if timeline := s.timeline(); timeline != nil {
+			values.Cost = timeline.Cost
+		}
+	}
+	session := s.input.SessionID
+	if session == "" {
+		session = s.input.TranscriptPath
+	}
+	tracker := &notify.Tracker{Store: state.Default()}
+	events, err := tracker.Check(session, notify.Thresholds{Context: cfg.Context, Cost: cfg.Cost}, values)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		event.SessionID = s.input.SessionID
+		event.Project = s.project()
+		event.Model = s.model
+		event.TranscriptPath = s.input.TranscriptPath
+		event.Timestamp = s.now
+		event.Describe()
+		if _, err := notify.Start(cfg.Command, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
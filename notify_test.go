@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"ccstatus/internal/config"
+	"ccstatus/internal/notify"
+	"ccstatus/internal/state"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readEvents waits until the stub command wrote want events
+func readEvents(t *testing.T, path string, want int) []notify.Event {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(path)
+		var events []notify.Event
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			var event notify.Event
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				t.Fatalf("stub stdin is not an event: %v\n%s", err, data)
+			}
+			events = append(events, event)
+		}
+		if len(events) >= want || time.Now().After(deadline) {
+			return events
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunNotifiesOnce(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(state.EnvDir, filepath.Join(dir, "state"))
+	out := filepath.Join(dir, "events.jsonl")
+	cfg := `{"notify":{"command":"cat >> ` + out + `","context":[10,90],"cost":[0.1]}}`
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvPath, configPath)
+
+	input := `{"session_id":"af99e13e","cwd":"/work/app","model":{"id":"claude-sonnet-4-5"},"transcript_path":"testdata/json/transcript.jsonl"}`
+	opts := statusOptions{now: time.Date(2025, 10, 1, 10, 5, 0, 0, time.UTC), output: outputText, roots: testRoots}
+	if err := runWith(strings.NewReader(input), io.Discard, opts); err != nil {
+		t.Fatalf("runWith() error = %v", err)
+	}
+
+	events := readEvents(t, out, 2)
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want context and cost", events)
+	}
+	// commands run concurrently and may finish in any order
+	slices.SortFunc(events, func(a, b notify.Event) int { return strings.Compare(a.Metric, b.Metric) })
+	if events[0].Metric != notify.MetricContext || events[0].Threshold != 10 || events[0].Value != 15 {
+		t.Errorf("context event = %+v, want 15%% over 10%%", events[0])
+	}
+	if events[1].Metric != notify.MetricCost || events[1].SessionID != "af99e13e" || events[1].Message != "app: session cost $0.11, crossed $0.1" {
+		t.Errorf("cost event = %+v", events[1])
+	}
+
+	// the same thresholds stay quiet on the next run
+	if err := runWith(strings.NewReader(input), io.Discard, opts); err != nil {
+		t.Fatalf("runWith() error = %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if events := readEvents(t, out, 0); len(events) != 2 {
+		t.Errorf("events after second run = %d, want 2", len(events))
+	}
+}
+
+func TestRunDryRunHasNoSideEffects(t *testing.T) {
+	dir := t.TempDir()
+	stateDir := filepath.Join(dir, "state")
+	t.Setenv(state.EnvDir, stateDir)
+	out := filepath.Join(dir, "events.jsonl")
+	textfile := filepath.Join(dir, "ccstatus.prom")
+	cfg := `{"notify":{"command":"cat >> ` + out + `","context":[10]},"metrics":{"textfile":"` + textfile + `"},"history":{"enabled":true}}`
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvPath, configPath)
+
+	input := `{"session_id":"af99e13e","cwd":"/work/app","model":{"id":"claude-sonnet-4-5"},"transcript_path":"testdata/json/transcript.jsonl"}`
+	opts := statusOptions{now: time.Date(2025, 10, 1, 10, 5, 0, 0, time.UTC), output: outputText, roots: testRoots, dryRun: true}
+	if err := runWith(strings.NewReader(input), io.Discard, opts); err != nil {
+		t.Fatalf("runWith() error = %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	for _, path := range []string{out, textfile, filepath.Join(stateDir, "history.db")} {
+		if _, err := os.Stat(path); err == nil {
+			t.Errorf("%s was written by a dry run", path)
+		}
+	}
+	if snapshots, _ := filepath.Glob(filepath.Join(stateDir, "segments-*")); len(snapshots) != 0 {
+		t.Errorf("snapshots = %v, want none from a dry run", snapshots)
+	}
+}
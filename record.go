@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"ccstatus/internal/formatter"
+	"ccstatus/internal/replay"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runStatusLine is the default mode: render the status line from the stdin payload,
+// optionally recording the invocation or replaying a recorded one
+func runStatusLine(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("ccstatus", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	record := flags.String("record", "", "save each stdin payload and transcript tail under this directory")
+	replayPath := flags.String("replay", "", "render a recording instead of reading stdin")
+	step := flags.Bool("step", false, "with --replay, render the status line after every transcript entry with usage")
+	output := flags.String("output", outputText, "output format: text, json, or a tmux, zsh, bash or starship status line")
+	sessionID := flags.String("session", "", "render the session with this id instead of the payload transcript")
+	project := flags.String("project", "", "render the most recent session of this project directory")
+	latest := flags.Bool("latest", false, "render the most recently modified transcript")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unknown command %q", positional[0])
+	}
+
+	if *output != outputText && *output != outputJSON && !formatter.IsDialect(*output) {
+		return fmt.Errorf("unknown output format %q, want text, json, tmux, zsh, bash or starship", *output)
+	}
+	if *sessionID != "" && (*project != "" || *latest) {
+		return fmt.Errorf("--session cannot be combined with --project or --latest")
+	}
+	opts := statusOptions{output: *output, sessionID: *sessionID, project: *project, latest: *latest}
+	stdin = statusInput(stdin)
+
+	switch {
+	case *replayPath != "":
+		return replayRecording(*replayPath, *step, *output, stdout)
+	case *step:
+		return fmt.Errorf("--step requires --replay")
+	case *record != "":
+		return recordRun(*record, opts, stdin, stdout, stderr)
+	}
+	opts.now = time.Now()
+	return runWith(stdin, stdout, opts)
+}
+
+// recordRun renders the status line and saves the invocation,
+// a failed recording never breaks the status line itself
+func recordRun(dir string, opts statusOptions, stdin io.Reader, stdout, stderr io.Writer) error {
+	now := time.Now()
+	opts.now = now
+	input, err := io.ReadAll(stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+
+	// the recording keeps the transcript the run picked, flags and a missing
+	// transcript_path would otherwise resolve to another one at replay time
+	var rendered bytes.Buffer
+	var resolved StatusInput
+	opts.resolved = &resolved
+	runErr := runWith(bytes.NewReader(input), io.MultiWriter(stdout, &rendered), opts)
+	source := replay.Source{
+		SessionID:      resolved.SessionID,
+		TranscriptPath: resolved.TranscriptPath,
+		Cwd:            resolved.Workspace.CurrentDir,
+		ProjectDir:     resolved.Workspace.ProjectDir,
+	}
+	if source.Cwd == "" {
+		source.Cwd = resolved.Cwd
+	}
+	if _, err := replay.Save(dir, input, rendered.Bytes(), source, now); err != nil {
+		fmt.Fprintf(stderr, "warning: failed to record invocation: %v\n", err)
+	}
+	return runErr
+}
+
+// replayRecording re-renders a recording against its transcript snapshot
+func replayRecording(path string, step bool, output string, stdout io.Writer) error {
+	rec, err := replay.Load(path)
+	if err != nil {
+		return err
+	}
+	snapshot, err := rec.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to read transcript snapshot: %w", err)
+	}
+	at := rec.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if snapshot == nil {
+		if step {
+			return fmt.Errorf("recording has no transcript snapshot to step through")
+		}
+		// nothing to redirect, replay the payload exactly as recorded
+		err := runWith(bytes.NewReader(rec.Input), stdout, statusOptions{now: at, output: output, dryRun: true})
+		fmt.Fprintln(stdout)
+		return err
+	}
+
+	tmp, err := os.MkdirTemp("", "ccstatus-replay-")
+	if err != nil {
+		return fmt.Errorf("failed to create replay dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	name := filepath.Base(rec.TranscriptPath)
+	if rec.TranscriptPath == "" {
+		name = replay.TranscriptFile
+	}
+	transcript := filepath.Join(tmp, name)
+	payload, err := rec.Payload(transcript)
+	if err != nil {
+		return err
+	}
+
+	if !step {
+		if err := os.WriteFile(transcript, snapshot, 0o600); err != nil {
+			return fmt.Errorf("failed to write transcript: %w", err)
+		}
+		err := runWith(bytes.NewReader(payload), stdout, statusOptions{now: at, output: output, roots: []string{tmp}, dryRun: true})
+		fmt.Fprintln(stdout)
+		return err
+	}
+
+	steps, err := replay.Steps(snapshot)
+	if err != nil {
+		return err
+	}
+	for _, s := range steps {
+		if err := os.WriteFile(transcript, s.Transcript, 0o600); err != nil {
+			return fmt.Errorf("failed to write transcript: %w", err)
+		}
+		stepAt := at
+		if !s.Timestamp.IsZero() {
+			stepAt = s.Timestamp
+		}
+		var line bytes.Buffer
+		// errors are already rendered into the line, keep stepping
+		_ = runWith(bytes.NewReader(payload), &line, statusOptions{now: stepAt, output: output, roots: []string{tmp}, dryRun: true})
+		fmt.Fprintf(stdout, "%6d  %s  %s\n", s.Line, stepAt.Local().Format(time.DateTime), strings.TrimSpace(line.String()))
+	}
+	return nil
+}
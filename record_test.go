@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"ccstatus/internal/config"
+	"ccstatus/internal/projects"
+	"ccstatus/internal/state"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(state.EnvDir, filepath.Join(dir, "state"))
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"transcript_roots":["`+dir+`"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvPath, configPath)
+	transcript := filepath.Join(dir, "session.jsonl")
+	content := `{"type":"assistant","timestamp":"2025-10-01T10:00:05Z","message":{"role":"assistant","usage":{"input_tokens":1000,"cache_read_input_tokens":9000}}}
+{"type":"assistant","timestamp":"2025-10-01T10:02:05Z","message":{"role":"assistant","usage":{"input_tokens":1000,"cache_read_input_tokens":59000}}}
+`
+	if err := os.WriteFile(transcript, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	input := `{"session_id":"abc","transcript_path":"` + transcript + `","model":{"id":"claude-sonnet-4-5"}}`
+
+	var live bytes.Buffer
+	if err := runStatusLine([]string{"--record", filepath.Join(dir, "rec")}, strings.NewReader(input), &live, io.Discard); err != nil {
+		t.Fatalf("record run error = %v", err)
+	}
+	if !strings.Contains(live.String(), "60000/200000") {
+		t.Errorf("record run output = %q, want current context", live.String())
+	}
+
+	// the original transcript is gone, replay must use the snapshot
+	os.Remove(transcript)
+	recordings, _ := filepath.Glob(filepath.Join(dir, "rec", "*"))
+	if len(recordings) != 1 {
+		t.Fatalf("recordings = %v, want one", recordings)
+	}
+
+	var replayed bytes.Buffer
+	if err := runStatusLine([]string{"--replay", recordings[0]}, strings.NewReader(""), &replayed, io.Discard); err != nil {
+		t.Fatalf("replay error = %v", err)
+	}
+	if strings.TrimSpace(replayed.String()) != strings.TrimSpace(live.String()) {
+		t.Errorf("replay = %q, want %q", replayed.String(), live.String())
+	}
+
+	var stepped bytes.Buffer
+	if err := runStatusLine([]string{"--replay", recordings[0], "--step"}, strings.NewReader(""), &stepped, io.Discard); err != nil {
+		t.Fatalf("step replay error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stepped.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "10000/200000") || !strings.Contains(lines[1], "60000/200000") {
+		t.Errorf("step replay = %q, want one line per usage entry", stepped.String())
+	}
+}
+
+func TestRecordResolvedTranscript(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(state.EnvDir, filepath.Join(dir, "state"))
+	t.Setenv(config.EnvPath, filepath.Join(dir, "missing-config.json"))
+	t.Setenv(projects.EnvConfigDir, filepath.Join(dir, "claude"))
+	project := filepath.Join(dir, "claude", "projects", "-work-app")
+	if err := os.MkdirAll(project, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	transcript := filepath.Join(project, "af99e13e.jsonl")
+	content := `{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":1000,"cache_read_input_tokens":59000}}}
+`
+	if err := os.WriteFile(transcript, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// no payload, the flag picks the transcript
+	var live bytes.Buffer
+	if err := runStatusLine([]string{"--record", filepath.Join(dir, "rec"), "--session", "af99e13e"}, strings.NewReader(""), &live, io.Discard); err != nil {
+		t.Fatalf("record run error = %v", err)
+	}
+	recordings, _ := filepath.Glob(filepath.Join(dir, "rec", "*-af99e13e"))
+	if len(recordings) != 1 {
+		t.Fatalf("recordings = %v, want one named after the session", recordings)
+	}
+
+	// a newer transcript must not be picked up by the replay
+	os.Remove(transcript)
+	newer := `{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":1000,"cache_read_input_tokens":1000}}}
+`
+	if err := os.WriteFile(filepath.Join(project, "b0b0b0b0.jsonl"), []byte(newer), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var replayed bytes.Buffer
+	if err := runStatusLine([]string{"--replay", recordings[0]}, strings.NewReader(""), &replayed, io.Discard); err != nil {
+		t.Fatalf("replay error = %v", err)
+	}
+	if !strings.Contains(live.String(), "60000/200000") || strings.TrimSpace(replayed.String()) != strings.TrimSpace(live.String()) {
+		t.Errorf("replay = %q, want %q", replayed.String(), live.String())
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"ccstatus/internal/config"
+	"ccstatus/internal/history"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/projects"
+	"ccstatus/internal/report"
+	"ccstatus/internal/state"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// runReport implements `ccstatus report`: usage and cost across all transcripts
+func runReport(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("report", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	groupBy := flags.String("by", report.GroupDay, "group by: "+strings.Join(report.Groups, ", "))
+	format := flags.String("format", report.FormatTable, "output format: table, json, csv")
+	since := flags.String("since", "", "include usage on or after this date (YYYY-MM-DD)")
+	until := flags.String("until", "", "include usage on or before this date (YYYY-MM-DD)")
+	fromHistory := flags.Bool("history", false, "read usage from the history database instead of scanning transcripts")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	opts := report.Options{GroupBy: *groupBy, Location: time.Local}
+	if !report.ValidGroup(opts.GroupBy) {
+		return fmt.Errorf("unknown grouping %q, want one of: %s", opts.GroupBy, strings.Join(report.Groups, ", "))
+	}
+
+	if opts.Since, err = parseDate(*since); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if opts.Until, err = parseDate(*until); err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+	if !opts.Until.IsZero() {
+		// --until is inclusive, the filter is exclusive
+		opts.Until = opts.Until.AddDate(0, 0, 1)
+	}
+
+	var entries []parser.Entry
+	if *fromHistory {
+		entries, err = historyEntries(stderr, opts)
+	} else {
+		entries, err = transcriptEntries(opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := report.Aggregate(entries, opts)
+	if err != nil {
+		return err
+	}
+	return report.Write(stdout, result, *format)
+}
+
+// transcriptEntries scans the transcripts that may hold usage in the report range
+func transcriptEntries(opts report.Options) ([]parser.Entry, error) {
+	dirs := projects.Dirs()
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no Claude Code projects directory found")
+	}
+	// files last modified before --since cannot contain newer entries
+	transcripts, err := projects.List(dirs, opts.Since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transcripts: %w", err)
+	}
+	scanner := &projects.Scanner{Store: state.Default()}
+	return scanner.Entries(transcripts), nil
+}
+
+// historyEntries reads the report range from the history database
+func historyEntries(stderr io.Writer, opts report.Options) ([]parser.Entry, error) {
+	cfg, err := config.Load(config.Path())
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: %v\n", err)
+	}
+	db, err := history.Open(historyPath(cfg.History), 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	records, err := db.Query(history.Filter{Since: opts.Since, Until: opts.Until})
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]parser.Entry, len(records))
+	for i, record := range records {
+		entries[i] = record.Entry()
+	}
+	return entries, nil
+}
+
+// parseDate parses YYYY-MM-DD in local time, empty input yields the zero time
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation(time.DateOnly, value, time.Local)
+}
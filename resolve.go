@@ -0,0 +1,84 @@
+package main
+
+import (
+	"ccstatus/internal/config"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/projects"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// resolveTranscript fills in the transcript path of input: --session looks up exactly
+// that session, --project and --latest take the newest transcript of a project or of all;
+// otherwise a payload without transcript_path is resolved from its session_id and cwd
+func resolveTranscript(input *StatusInput, opts statusOptions) error {
+	if opts.project != "" {
+		project, err := filepath.Abs(opts.project)
+		if err != nil {
+			return fmt.Errorf("invalid project %q: %w", opts.project, err)
+		}
+		input.Cwd = project
+		input.Workspace = WorkspaceInfo{CurrentDir: project, ProjectDir: project}
+	}
+
+	dirs := projects.Dirs()
+	cwd := input.Workspace.CurrentDir
+	if cwd == "" {
+		cwd = input.Cwd
+	}
+	var path string
+	var err error
+	switch {
+	case opts.sessionID != "":
+		path, err = projects.FindSession(dirs, opts.sessionID)
+	case opts.project != "":
+		path, err = projects.Latest(dirs, cwd)
+	case opts.latest:
+		path, err = projects.Latest(dirs, "")
+	case input.TranscriptPath != "":
+		return nil
+	default:
+		// a shell prompt has no payload, the working directory is the project
+		if cwd == "" {
+			cwd, _ = os.Getwd()
+		}
+		path, err = projects.Resolve(dirs, input.SessionID, cwd)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find transcript: %w", err)
+	}
+
+	if input.Cwd == "" && input.Workspace.CurrentDir == "" {
+		input.Cwd = cwd
+	}
+	input.TranscriptPath = path
+	// transcripts are named after their session, the fallbacks may pick another one
+	input.SessionID = projects.SessionID(path)
+	return nil
+}
+
+// transcriptPolicy returns the path policy of the status line: transcripts must
+// resolve under a Claude Code projects dir, one of the configured roots or extra;
+// without any of them every transcript is rejected
+func transcriptPolicy(cfg *config.Config, extra []string) parser.PathPolicy {
+	roots := append(projects.Dirs(), extra...)
+	home, _ := os.UserHomeDir()
+	for _, root := range cfg.TranscriptRoots {
+		roots = append(roots, expandHome(root, home))
+	}
+	return parser.PathPolicy{Roots: roots, MaxSize: parser.DefaultMaxSize, Strict: true}
+}
+
+// statusInput returns the payload reader of a status line run, an interactive
+// terminal has no payload to wait for
+func statusInput(stdin io.Reader) io.Reader {
+	if file, ok := stdin.(*os.File); ok {
+		if fi, err := file.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+			return strings.NewReader("")
+		}
+	}
+	return stdin
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"ccstatus/internal/config"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/projects"
+	"ccstatus/internal/state"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunStatusLineResolvesTranscript(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(projects.EnvConfigDir, root)
+	t.Setenv(state.EnvDir, filepath.Join(root, "state"))
+	configPath := filepath.Join(root, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"segments":["context","cwd"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvPath, configPath)
+
+	data, err := os.ReadFile("testdata/json/transcript.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for name, age := range map[string]time.Duration{
+		"-work-app/s-app.jsonl":       2 * time.Hour,
+		"-work-other/s-other.jsonl":   time.Hour,
+		"-work-newest/s-newest.jsonl": time.Minute,
+	} {
+		path := filepath.Join(root, "projects", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const context = "[ctx: 30000/200000 15.0%] claude-sonnet-4-5 "
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		want    string
+		wantErr bool
+	}{
+		{name: "payload session id", stdin: `{"session_id":"s-other","cwd":"/work/app"}`, want: context + "/work/app"},
+		{name: "payload cwd", stdin: `{"cwd":"/work/app"}`, want: context + "/work/app"},
+		{name: "unknown project falls back to the newest", stdin: `{"cwd":"/work/unknown"}`, want: context + "/work/unknown"},
+		{name: "session flag", args: []string{"--session", "s-app"}, want: context},
+		{name: "project flag", args: []string{"--project", "/work/other"}, want: context + "/work/other"},
+		{name: "latest flag", args: []string{"--latest"}, stdin: `{"cwd":"/work/app","transcript_path":"/missing.jsonl"}`, want: context + "/work/app"},
+		{name: "unknown session", args: []string{"--session", "missing"}, wantErr: true},
+		{name: "conflicting flags", args: []string{"--session", "s-app", "--latest"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			err := runStatusLine(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runStatusLine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.TrimSpace(stdout.String()); got != strings.TrimSpace(tt.want) {
+				t.Errorf("runStatusLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTranscriptSessionID(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(projects.EnvConfigDir, root)
+	path := filepath.Join(root, "projects", "-work-app", "s-app.jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the fallback picked another session, the input follows it
+	input := StatusInput{SessionID: "gone", Cwd: "/work/app"}
+	if err := resolveTranscript(&input, statusOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if input.TranscriptPath != path || input.SessionID != "s-app" {
+		t.Errorf("resolveTranscript() = %q, %q, want %q, s-app", input.TranscriptPath, input.SessionID, path)
+	}
+
+	// an archived session keeps its bare id
+	archive := filepath.Join(root, "projects", "-work-app", "s-old.jsonl.gz")
+	if err := os.WriteFile(archive, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	input = StatusInput{}
+	if err := resolveTranscript(&input, statusOptions{sessionID: "s-old"}); err != nil {
+		t.Fatal(err)
+	}
+	if input.TranscriptPath != archive || input.SessionID != "s-old" {
+		t.Errorf("resolveTranscript() = %q, %q, want %q, s-old", input.TranscriptPath, input.SessionID, archive)
+	}
+}
+
+func TestTranscriptPolicyWithoutProjectsDir(t *testing.T) {
+	// no ~/.claude/projects and no transcript_roots: the payload path must not be trusted
+	t.Setenv(projects.EnvConfigDir, t.TempDir())
+	path := filepath.Join(t.TempDir(), "secret.jsonl")
+	if err := os.WriteFile(path, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := transcriptPolicy(config.Default(), nil).ParseSession(path); !errors.Is(err, parser.ErrInvalidPath) {
+		t.Errorf("ParseSession() error = %v, want ErrInvalidPath", err)
+	}
+}
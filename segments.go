@@ -0,0 +1,305 @@
+package main
+
+import (
+	"ccstatus/internal/calculator"
+	"ccstatus/internal/config"
+	"ccstatus/internal/formatter"
+	"ccstatus/internal/git"
+	"ccstatus/internal/logging"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/plugin"
+	"ccstatus/internal/projects"
+	"ccstatus/internal/report"
+	"ccstatus/internal/state"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// blockLookback bounds which transcripts are scanned for the 5-hour block;
+// longer than a block so that back-to-back blocks keep their real start time
+const blockLookback = 24 * time.Hour
+
+// statusContext carries everything segments are rendered from
+type statusContext struct {
+	cfg   *config.Config
+	input *StatusInput
+	model string
+	info  calculator.ContextInfo
+	times calculator.SessionTimes
+	now   time.Time
+	// ctx carries the run deadline
+	ctx context.Context
+	// session is the parsed transcript the status line is rendered for,
+	// nil when parsing missed the deadline
+	session *parser.Session
+	// log receives debug records and failure details
+	log *logging.Logger
+	// policy restricts the transcripts the status line reads
+	policy parser.PathPolicy
+	// dryRun keeps the last-good snapshot untouched
+	dryRun bool
+
+	// last is the snapshot of the previous run, loaded on first use by loadSegments()
+	last *lastSegments
+
+	// sessionTimeline is parsed on first use by timeline()
+	sessionTimeline *report.Timeline
+	timelineLoaded  bool
+}
+
+// buildSegments renders enabled segments in configured order, skipping empty ones
+// a segment that misses the deadline shows its previous text with a stale marker
+func (s *statusContext) buildSegments() []string {
+	cfg := s.cfg
+	input := s.input
+	cwd := input.Workspace.CurrentDir
+	if cwd == "" {
+		cwd = input.Cwd
+	}
+
+	// git status is shared by the git and cwd segments, look it up at most once
+	var gitStatus *git.Status
+	gitMissed := false
+	if cfg.Has(config.SegmentGit) || (cfg.Has(config.SegmentCwd) && cfg.Cwd.ProjectRelative) {
+		var err error
+		gitStatus, err = withDeadline(s.ctx, func() (*git.Status, error) {
+			status := lookupGit(s.ctx, cwd)
+			// git commands are killed at the deadline, that is a miss rather than no repository
+			if status == nil && s.ctx.Err() != nil {
+				return nil, s.ctx.Err()
+			}
+			return status, nil
+		})
+		gitMissed = err != nil
+	}
+
+	plugins, pluginsMissed := s.runPlugins(cwd)
+
+	rendered := make(map[string]string)
+	var segments []string
+	for _, name := range cfg.Segments {
+		start := time.Now()
+		var segment string
+		var err error
+		switch name {
+		case config.SegmentContext:
+			if s.session == nil {
+				err = errSessionMissing
+				break
+			}
+			segment = formatter.Format(s.info, s.model)
+		case config.SegmentCwd:
+			segment = formatter.FormatCwd(cwd, cwdOptions(cfg.Cwd, input.Workspace.ProjectDir, gitStatus))
+		case config.SegmentGit:
+			if gitMissed {
+				err = context.DeadlineExceeded
+				break
+			}
+			segment = formatter.FormatGit(gitStatus)
+		case config.SegmentSession:
+			if s.session == nil {
+				err = errSessionMissing
+				break
+			}
+			segment = formatter.FormatSession(s.times)
+		case config.SegmentErrors:
+			if s.session == nil {
+				err = errSessionMissing
+				break
+			}
+			segment = formatter.FormatAPIErrors(s.info)
+		case config.SegmentBlock:
+			segment, err = withDeadline(s.ctx, func() (string, error) {
+				return formatter.FormatBlock(currentBlock(s.ctx, s.now), s.now), nil
+			})
+		case config.SegmentBudget:
+			segment, err = withDeadline(s.ctx, func() (string, error) {
+				return formatter.FormatBudget(budgetStatuses(s.ctx, cfg.Budgets, s.project(), s.now)), nil
+			})
+		default:
+			segment = plugins[name]
+			if pluginsMissed[name] {
+				err = context.DeadlineExceeded
+			}
+		}
+
+		s.log.Debug("segment", "name", name, "took", time.Since(start), "missed", err != nil)
+		if err != nil {
+			segment = s.staleSegment(name)
+		} else {
+			rendered[name] = segment
+		}
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	s.saveSegments(rendered)
+	return segments
+}
+
+// runPlugins runs every enabled plugin segment concurrently and returns their output by name,
+// and the plugins that missed the deadline; a failing plugin is omitted like any other
+// empty segment, it never affects the rest of the line
+func (s *statusContext) runPlugins(cwd string) (map[string]string, map[string]bool) {
+	var enabled []string
+	for _, name := range s.cfg.Segments {
+		if _, ok := s.cfg.Plugins[name]; ok {
+			enabled = append(enabled, name)
+		}
+	}
+	if len(enabled) == 0 {
+		return nil, nil
+	}
+
+	missed := make(map[string]bool, len(enabled))
+	// the document reads the whole transcript, it is the plugin input and bound by the deadline too
+	var input []byte
+	var err error
+	if s.session != nil {
+		input, err = withDeadline(s.ctx, func() ([]byte, error) {
+			return []byte(formatter.FormatJSON(s.document(s.session))), nil
+		})
+	}
+	if s.session == nil || err != nil {
+		for _, name := range enabled {
+			missed[name] = true
+		}
+		return nil, missed
+	}
+
+	outputs := make([]string, len(enabled))
+	errs := make([]error, len(enabled))
+	var wg sync.WaitGroup
+	for i, name := range enabled {
+		cfg := s.cfg.Plugins[name]
+		p := &plugin.Plugin{
+			Name:    name,
+			Command: cfg.Command,
+			Dir:     cwd,
+			Timeout: cfg.Timeout.Std(),
+			TTL:     cfg.TTL.Std(),
+		}
+		wg.Go(func() {
+			outputs[i], errs[i] = withDeadline(s.ctx, func() (string, error) {
+				return p.CachedRun(s.ctx, state.Default(), cwd, input)
+			})
+		})
+	}
+	wg.Wait()
+
+	results := make(map[string]string, len(enabled))
+	for i, name := range enabled {
+		results[name] = outputs[i]
+		// a plugin that failed on its own is just empty, only the global deadline falls back
+		missed[name] = errs[i] != nil && s.ctx.Err() != nil
+	}
+	return results, missed
+}
+
+// lookupGit returns repository status for cwd
+// git problems never break the status line, the segment is just omitted
+func lookupGit(ctx context.Context, cwd string) *git.Status {
+	if cwd == "" {
+		return nil
+	}
+	status, err := git.CachedLookup(ctx, cwd, state.Default(), git.DefaultCacheTTL)
+	if err != nil {
+		return nil
+	}
+	return status
+}
+
+// cwdOptions maps config to path options, the project root is the workspace
+// project dir when Claude Code reports one, otherwise the git repository root
+func cwdOptions(cfg config.CwdConfig, projectDir string, gitStatus *git.Status) formatter.PathOptions {
+	opts := formatter.PathOptions{
+		Fish:     cfg.Fish,
+		MaxWidth: cfg.MaxWidth,
+	}
+	if cfg.Home {
+		opts.Home, _ = os.UserHomeDir()
+	}
+	if cfg.ProjectRelative {
+		opts.ProjectRoot = projectDir
+		if opts.ProjectRoot == "" && gitStatus != nil {
+			opts.ProjectRoot = gitStatus.Root
+		}
+	}
+	return opts
+}
+
+// currentBlock aggregates recent usage across all transcripts into the active 5-hour block
+// the scan stops once ctx is done, the result is then discarded by the caller
+func currentBlock(ctx context.Context, now time.Time) *calculator.Block {
+	transcripts, err := projects.List(projects.Dirs(), now.Add(-blockLookback))
+	if err != nil {
+		return nil
+	}
+	scanner := &projects.Scanner{Store: state.Default()}
+	return calculator.CurrentBlock(scanner.EntriesContext(ctx, transcripts), now)
+}
+
+// project returns the project directory of the session
+func (s *statusContext) project() string {
+	if s.input.Workspace.ProjectDir != "" {
+		return s.input.Workspace.ProjectDir
+	}
+	return s.input.Cwd
+}
+
+// budgetStatuses computes the spend of every budget that covers project
+// only transcripts modified since the earliest period start are scanned
+func budgetStatuses(ctx context.Context, budgets []config.BudgetConfig, project string, now time.Time) []calculator.BudgetStatus {
+	home, _ := os.UserHomeDir()
+	var applicable []calculator.Budget
+	var since time.Time
+	for _, b := range budgets {
+		budget := calculator.Budget{
+			Name:    b.Name,
+			Project: expandHome(b.Project, home),
+			Period:  b.Period,
+			Limit:   b.Limit,
+		}
+		if !calculator.MatchProject(budget.Project, project) {
+			continue
+		}
+		applicable = append(applicable, budget)
+		if start, _ := calculator.PeriodBounds(budget.Period, now, time.Local); since.IsZero() || start.Before(since) {
+			since = start
+		}
+	}
+	if len(applicable) == 0 {
+		return nil
+	}
+
+	transcripts, err := projects.List(projects.Dirs(), since)
+	if err != nil {
+		return nil
+	}
+	scanner := &projects.Scanner{Store: state.Default()}
+	entries := scanner.EntriesContext(ctx, transcripts)
+
+	statuses := make([]calculator.BudgetStatus, len(applicable))
+	for i, budget := range applicable {
+		statuses[i] = calculator.CalculateBudget(entries, budget, now, time.Local)
+	}
+	return statuses
+}
+
+// expandHome replaces a leading ~ with home
+func expandHome(path, home string) string {
+	if home == "" {
+		return path
+	}
+	if path == "~" {
+		return home
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	return path
+}
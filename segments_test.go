@@ -0,0 +1,138 @@
+package main
+
+import (
+	"ccstatus/internal/config"
+	"ccstatus/internal/projects"
+	"ccstatus/internal/state"
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBudgetStatuses(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(projects.EnvConfigDir, root)
+	t.Setenv(state.EnvDir, filepath.Join(root, "state"))
+	dir := filepath.Join(root, "projects", "-work-acme-api")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	// one million output tokens of sonnet cost $15
+	transcript := `{"type":"assistant","timestamp":"2025-10-14T12:00:00Z","sessionId":"s1","cwd":"/work/acme-api","requestId":"r1","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-5","usage":{"output_tokens":1000000}}}
+{"type":"assistant","timestamp":"2025-10-15T10:00:00Z","sessionId":"s1","cwd":"/work/acme-api/cmd","requestId":"r2","message":{"id":"m2","role":"assistant","model":"claude-sonnet-4-5","usage":{"output_tokens":1000000}}}
+{"type":"assistant","timestamp":"2025-10-15T11:00:00Z","sessionId":"s2","cwd":"/work/personal","requestId":"r3","message":{"id":"m3","role":"assistant","model":"claude-sonnet-4-5","usage":{"output_tokens":1000000}}}
+`
+	if err := os.WriteFile(filepath.Join(dir, "s1.jsonl"), []byte(transcript), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	budgets := []config.BudgetConfig{
+		{Name: "all", Period: "month", Limit: 1000},
+		{Name: "acme", Project: "/work/acme-*", Period: "month", Limit: 40},
+		{Name: "other", Project: "/work/other", Period: "month", Limit: 10},
+	}
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	statuses := budgetStatuses(context.Background(), budgets, "/work/acme-api", now)
+	if len(statuses) != 2 {
+		t.Fatalf("budgetStatuses() = %+v, want the all and acme budgets", statuses)
+	}
+	if statuses[0].Budget.Name != "all" || math.Abs(statuses[0].Spent-45) > 1e-9 {
+		t.Errorf("all budget = %+v, want $45 spent", statuses[0])
+	}
+	if statuses[1].Budget.Name != "acme" || math.Abs(statuses[1].Spent-30) > 1e-9 || !statuses[1].AtRisk() {
+		t.Errorf("acme budget = %+v, want $30 spent and at risk", statuses[1])
+	}
+
+	if statuses := budgetStatuses(context.Background(), budgets[2:], "/work/acme-api", now); statuses != nil {
+		t.Errorf("budgetStatuses() for another project = %+v, want nil", statuses)
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	tests := []struct {
+		path string
+		home string
+		want string
+	}{
+		{"~/work/acme-*", "/home/me", "/home/me/work/acme-*"},
+		{"~", "/home/me", "/home/me"},
+		{"/work/app", "/home/me", "/work/app"},
+		{"~other/app", "/home/me", "~other/app"},
+		{"~/work", "", "~/work"},
+	}
+	for _, tt := range tests {
+		if got := expandHome(tt.path, tt.home); got != tt.want {
+			t.Errorf("expandHome(%q, %q) = %q, want %q", tt.path, tt.home, got, tt.want)
+		}
+	}
+}
+
+func TestRunPluginSegments(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(state.EnvDir, filepath.Join(dir, "state"))
+	cfg := `{
+		"segments": ["context", "ci", "broken", "slow", "dir"],
+		"plugins": {
+			"ci": {"command": "sed -n 's/.*\"session\":{\"id\":\"\\([^\"]*\\)\".*/ci \\1/p'"},
+			"broken": {"command": "exit 1"},
+			"slow": {"command": "sleep 5", "timeout": "50ms"},
+			"dir": {"command": "pwd", "ttl": "1m"}
+		}
+	}`
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvPath, configPath)
+
+	input := `{"session_id":"af99e13e","cwd":"` + dir + `","model":{"id":"claude-sonnet-4-5"},"transcript_path":"testdata/json/transcript.jsonl"}`
+	var stdout strings.Builder
+	start := time.Now()
+	if err := runWith(strings.NewReader(input), &stdout, statusOptions{now: time.Now(), output: outputText, roots: testRoots}); err != nil {
+		t.Fatalf("runWith() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("runWith() took %v, want the slow plugin cut off", elapsed)
+	}
+
+	// plugins run in the session directory and see the status document
+	want := "[ctx: 30000/200000 15.0%] claude-sonnet-4-5 ci af99e13e " + dir
+	if got := stdout.String(); got != want {
+		t.Errorf("status line = %q, want %q", got, want)
+	}
+}
+
+func TestRunErrorsSegment(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(state.EnvDir, filepath.Join(dir, "state"))
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"segments": ["context", "errors"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvPath, configPath)
+
+	transcript := filepath.Join(dir, "session.jsonl")
+	lines := `{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":1000,"cache_read_input_tokens":19000}}}
+{"type":"assistant","isApiErrorMessage":true,"message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"API Error: 529 overloaded"}],"usage":{"input_tokens":0}}}
+{"type":"user","message":{"role":"user","content":[{"type":"text","text":"[Request interrupted by user]"}]}}
+{"type":"assistant","isApiErrorMessage":true,"message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"API Error: Request timed out."}]}}
+`
+	if err := os.WriteFile(transcript, []byte(lines), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	input := `{"session_id":"af99e13e","cwd":"` + dir + `","model":{"id":"claude-sonnet-4-5"},"transcript_path":"` + transcript + `"}`
+	var stdout strings.Builder
+	if err := runWith(strings.NewReader(input), &stdout, statusOptions{now: time.Now(), output: outputText, roots: []string{dir}}); err != nil {
+		t.Fatalf("runWith() error = %v", err)
+	}
+	want := "[ctx: 20000/200000 10.0%] claude-sonnet-4-5 2 API errors, 1 interruption this session"
+	if got := stdout.String(); got != want {
+		t.Errorf("status line = %q, want %q", got, want)
+	}
+}
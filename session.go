@@ -0,0 +1,54 @@
+package main
+
+import (
+	"ccstatus/internal/parser"
+	"ccstatus/internal/projects"
+	"ccstatus/internal/report"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// runSession implements `ccstatus session <path|session-id>`: a per-turn timeline of one transcript
+func runSession(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("session", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: ccstatus session [flags] <path|session-id>")
+		flags.PrintDefaults()
+	}
+	sidechain := flags.String("sidechain", report.SidechainAll, "sidechain entries: all, main (exclude), only")
+	format := flags.String("format", report.FormatTable, "output format: table, json")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one transcript path or session id")
+	}
+
+	path, err := resolveSessionArg(positional[0])
+	if err != nil {
+		return err
+	}
+
+	turns, err := parser.ParseTurns(path)
+	if err != nil {
+		return err
+	}
+	timeline, err := report.BuildTimeline(turns, *sidechain)
+	if err != nil {
+		return err
+	}
+	return report.WriteTimeline(stdout, timeline, *format)
+}
+
+// resolveSessionArg accepts a transcript path or a session id looked up in the projects dirs
+func resolveSessionArg(arg string) (string, error) {
+	if fi, err := os.Stat(arg); err == nil && !fi.IsDir() {
+		return arg, nil
+	}
+	return projects.FindSession(projects.Dirs(), arg)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"ccstatus/internal/parser"
+	"ccstatus/internal/projects"
+	"ccstatus/internal/report"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// runSessions implements `ccstatus sessions`: every recently active session, riskiest first
+func runSessions(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("sessions", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	active := flags.Duration("active", 24*time.Hour, "list transcripts modified within this window")
+	format := flags.String("format", report.FormatTable, "output format: table, json")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+	if *format != report.FormatTable && *format != report.FormatJSON {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	dirs := projects.Dirs()
+	if len(dirs) == 0 {
+		return fmt.Errorf("no Claude Code projects directory found")
+	}
+	now := time.Now()
+	transcripts, err := projects.List(dirs, now.Add(-*active))
+	if err != nil {
+		return fmt.Errorf("failed to list transcripts: %w", err)
+	}
+
+	// archives and nested subagent transcripts are parts of a session, not sessions
+	return report.WriteSessions(stdout, summarizeTranscripts(projects.Sessions(transcripts)), *format, now)
+}
+
+// summarizeTranscripts parses each transcript, a broken one is listed with its error
+func summarizeTranscripts(transcripts []projects.Transcript) []report.SessionSummary {
+	summaries := make([]report.SessionSummary, 0, len(transcripts))
+	for _, transcript := range transcripts {
+		summary := report.SessionSummary{Path: transcript.Path}
+		turns, err := parser.ParseTurns(transcript.Path)
+		if err == nil {
+			summary, err = report.SummarizeSession(transcript.Path, turns)
+		}
+		if err != nil {
+			summary.Error = err.Error()
+		}
+		if summary.Project == "" {
+			summary.Project = transcript.Project
+		}
+		if summary.LastActivity.IsZero() {
+			summary.LastActivity = transcript.ModTime
+		}
+		summaries = append(summaries, summary)
+	}
+	report.SortByRisk(summaries)
+	return summaries
+}
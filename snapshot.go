@@ -0,0 +1,114 @@
+package main
+
+import (
+	"ccstatus/internal/formatter"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/state"
+	"errors"
+	"io/fs"
+	"maps"
+)
+
+// lastSegments is the per-session last-good snapshot: the last rendered segment
+// texts, shown with a stale marker when a later run cannot compute them
+type lastSegments struct {
+	Segments map[string]string `json:"segments"`
+	// Tokens is the last context size, a transcript that suddenly has no usage
+	// was truncated or rotated rather than started over
+	Tokens int64 `json:"tokens"`
+}
+
+// segmentsKey names the state record of the session, the transcript path
+// identifies sessions without an id
+func (s *statusContext) segmentsKey() string {
+	if s.input.SessionID != "" {
+		return "segments-" + s.input.SessionID
+	}
+	return "segments-" + s.input.TranscriptPath
+}
+
+// loadSegments returns the snapshot of the session, read once per run
+func (s *statusContext) loadSegments() *lastSegments {
+	if s.last == nil {
+		s.last = &lastSegments{}
+		// a missing or unreadable record just means there is nothing to fall back to
+		_, _ = state.Default().Load(s.segmentsKey(), s.last)
+		if s.last.Segments == nil {
+			s.last.Segments = make(map[string]string)
+		}
+	}
+	return s.last
+}
+
+// staleSegment returns the previous text of a segment marked as stale, empty when there is none
+func (s *statusContext) staleSegment(name string) string {
+	segment := s.loadSegments().Segments[name]
+	if segment == "" {
+		return ""
+	}
+	return formatter.FormatStale(segment)
+}
+
+// saveSegments merges freshly rendered segments into the session snapshot
+// skipped on a dry run and when nothing changed, so steady runs cost no write
+func (s *statusContext) saveSegments(rendered map[string]string) {
+	if s.dryRun {
+		return
+	}
+	last := s.loadSegments()
+	next := lastSegments{Segments: maps.Clone(last.Segments), Tokens: last.Tokens}
+	maps.Copy(next.Segments, rendered)
+	if s.session != nil {
+		next.Tokens = s.info.CurrentTokens
+	}
+	if next.Tokens == last.Tokens && maps.Equal(next.Segments, last.Segments) {
+		return
+	}
+	s.last = &next
+	// a failed write only loses the fallback of the next failed run
+	_ = state.Default().Save(s.segmentsKey(), next)
+}
+
+// parse failure codes appended to a status line shown from the snapshot
+const (
+	codeNoFile = "nofile"
+	codePerm   = "perm"
+	codePath   = "path"
+	codeRead   = "read"
+	codeEmpty  = "empty"
+)
+
+// classifyParseError maps a parser error to its short code
+func classifyParseError(err error) string {
+	switch {
+	case errors.Is(err, parser.ErrInvalidPath):
+		return codePath
+	case errors.Is(err, fs.ErrNotExist):
+		return codeNoFile
+	case errors.Is(err, fs.ErrPermission):
+		return codePerm
+	default:
+		return codeRead
+	}
+}
+
+// lostUsage reports whether a transcript that parsed fine has no usage although
+// the snapshot of the same session had some, e.g. after a rotation or truncation
+func (s *statusContext) lostUsage() bool {
+	return s.session != nil && s.info.CurrentTokens == 0 && s.loadSegments().Tokens > 0
+}
+
+// hasSnapshot reports whether a previous run of the session left segments to fall back to
+func (s *statusContext) hasSnapshot() bool {
+	return len(s.loadSegments().Segments) > 0
+}
+
+// logParseFailure writes the details behind a short error code to the log file
+func (s *statusContext) logParseFailure(code string, err error) {
+	s.log.Warn("transcript parse failed",
+		"code", code,
+		"session", s.input.SessionID,
+		"transcript", s.input.TranscriptPath,
+		"error", err,
+	)
+}
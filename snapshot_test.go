@@ -0,0 +1,179 @@
+package main
+
+import (
+	"ccstatus/internal/config"
+	"ccstatus/internal/logging"
+	"ccstatus/internal/parser"
+	"ccstatus/internal/state"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassifyParseError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("failed to open transcript: %w", fs.ErrNotExist), codeNoFile},
+		{fmt.Errorf("failed to open transcript: %w", fs.ErrPermission), codePerm},
+		{fmt.Errorf("%w: transcript is not a regular file", parser.ErrInvalidPath), codePath},
+		{errors.New("error reading transcript: bufio.Scanner: token too long"), codeRead},
+	}
+	for _, tt := range tests {
+		if got := classifyParseError(tt.err); got != tt.want {
+			t.Errorf("classifyParseError(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+// fallbackEnv prepares a state dir and a copy of the test transcript
+func fallbackEnv(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv(state.EnvDir, filepath.Join(dir, "state"))
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"segments":["context","cwd"],"transcript_roots":["`+dir+`"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvPath, configPath)
+
+	data, err := os.ReadFile("testdata/json/transcript.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	transcript := filepath.Join(dir, "transcript.jsonl")
+	if err := os.WriteFile(transcript, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return transcript
+}
+
+func runFallback(transcript string) (string, error) {
+	input := `{"session_id":"af99e13e","cwd":"/work/app","model":{"id":"claude-sonnet-4-5"},"transcript_path":"` + transcript + `"}`
+	var stdout strings.Builder
+	err := runWith(strings.NewReader(input), &stdout, statusOptions{now: time.Now(), output: outputText})
+	return stdout.String(), err
+}
+
+func TestRunFallsBackToSnapshot(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage func(t *testing.T, transcript string) string
+		code   string
+	}{
+		{
+			name: "rotated away",
+			damage: func(t *testing.T, transcript string) string {
+				if err := os.Remove(transcript); err != nil {
+					t.Fatal(err)
+				}
+				return transcript
+			},
+			code: codeNoFile,
+		},
+		{
+			name: "unreadable",
+			damage: func(t *testing.T, transcript string) string {
+				// a line longer than the scanner buffer cannot be read
+				if err := os.WriteFile(transcript, []byte(strings.Repeat("x", 2<<20)), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				return transcript
+			},
+			code: codeRead,
+		},
+		{
+			name: "replaced by a directory",
+			damage: func(t *testing.T, transcript string) string {
+				if err := os.Remove(transcript); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Mkdir(transcript, 0o700); err != nil {
+					t.Fatal(err)
+				}
+				return transcript
+			},
+			code: codePath,
+		},
+		{
+			name: "truncated by a partial rewrite",
+			damage: func(t *testing.T, transcript string) string {
+				if err := os.WriteFile(transcript, []byte(`{"type":"user","message":{"role":"us`), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				return transcript
+			},
+			code: codeEmpty,
+		},
+		{
+			name: "symlink out of the allowed roots",
+			damage: func(t *testing.T, transcript string) string {
+				outside := filepath.Join(t.TempDir(), "transcript.jsonl")
+				if err := os.Rename(transcript, outside); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Symlink(outside, transcript); err != nil {
+					t.Fatal(err)
+				}
+				return transcript
+			},
+			code: codePath,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transcript := fallbackEnv(t)
+			fresh := "[ctx: 30000/200000 15.0%] claude-sonnet-4-5 /work/app"
+			if got, err := runFallback(transcript); err != nil || got != fresh {
+				t.Fatalf("first run = %q, %v, want %q", got, err, fresh)
+			}
+
+			got, err := runFallback(tt.damage(t, transcript))
+			if err != nil {
+				t.Errorf("runWith() error = %v, want the snapshot shown without error", err)
+			}
+			if want := "~[ctx: 30000/200000 15.0%] claude-sonnet-4-5 /work/app !" + tt.code; got != want {
+				t.Errorf("status line = %q, want %q", got, want)
+			}
+
+			log, err := os.ReadFile(logging.Path())
+			if err != nil {
+				t.Fatalf("log not written: %v", err)
+			}
+			if !strings.Contains(string(log), "code="+tt.code) || !strings.Contains(string(log), "session=af99e13e") {
+				t.Errorf("log = %q, want the failure details", log)
+			}
+		})
+	}
+}
+
+func TestRunWithoutSnapshotShowsError(t *testing.T) {
+	transcript := fallbackEnv(t)
+	if err := os.Remove(transcript); err != nil {
+		t.Fatal(err)
+	}
+	got, err := runFallback(transcript)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("runWith() error = %v, want the parse error", err)
+	}
+	if !strings.HasPrefix(got, "[ERROR: parse error: failed to open transcript") {
+		t.Errorf("status line = %q, want an explicit error", got)
+	}
+}
+
+func TestRunNewSessionWithoutUsage(t *testing.T) {
+	transcript := fallbackEnv(t)
+	if err := os.WriteFile(transcript, []byte(`{"type":"user","message":{"role":"user","content":"hi"}}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// a session that never had usage is not a failure
+	if got, err := runFallback(transcript); err != nil || got != "[ctx: 0/200000 0.0%] claude-sonnet-4-5 /work/app" {
+		t.Errorf("status line = %q, %v, want the empty context", got, err)
+	}
+}
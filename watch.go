@@ -0,0 +1,156 @@
+package main
+
+import (
+	"ccstatus/internal/parser"
+	"ccstatus/internal/projects"
+	"ccstatus/internal/tui"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// runWatch implements `ccstatus watch`: a live full-screen dashboard of active transcripts
+func runWatch(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: ccstatus watch [flags] [path|session-id ...]")
+		flags.PrintDefaults()
+	}
+	interval := flags.Duration("interval", time.Second, "how often transcripts are polled for changes")
+	active := flags.Duration("active", time.Hour, "without arguments, watch transcripts modified within this window")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	w := &watcher{active: *active, files: map[string]*watchedFile{}}
+	for _, arg := range positional {
+		path, err := resolveSessionArg(arg)
+		if err != nil {
+			return err
+		}
+		w.paths = append(w.paths, path)
+	}
+
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return errors.New("watch needs an interactive terminal")
+	}
+	term, err := tui.OpenTerminal(os.Stdin, stdout)
+	if err != nil {
+		return err
+	}
+	defer term.Close()
+
+	input := make(chan []byte)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(input)
+				return
+			}
+			input <- append([]byte(nil), buf[:n]...)
+		}
+	}()
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	// the size is read through stty, only when the terminal reports a change
+	resize := make(chan os.Signal, 1)
+	tui.NotifyResize(resize)
+	defer signal.Stop(resize)
+
+	dashboard := &tui.Dashboard{}
+	screen := tui.NewScreen(0, 0)
+	resized := true
+	for {
+		now := time.Now()
+		dashboard.SetSessions(w.refresh(now))
+		if resized {
+			if width, height, err := term.Size(); err == nil && (width != screen.Width || height != screen.Height) {
+				screen.Resize(width, height)
+			}
+			resized = false
+		}
+		dashboard.Draw(screen, now)
+		if err := screen.Render(stdout); err != nil {
+			return err
+		}
+
+		select {
+		case data, ok := <-input:
+			if !ok {
+				return nil
+			}
+			for _, key := range tui.ParseKeys(data) {
+				if dashboard.HandleKey(key) {
+					return nil
+				}
+			}
+		case <-resize:
+			resized = true
+		case <-ticker.C:
+		}
+	}
+}
+
+// watcher polls transcripts and re-parses only the ones whose size or mtime changed
+type watcher struct {
+	// paths are the explicitly requested transcripts, empty means discover active ones
+	paths  []string
+	active time.Duration
+	files  map[string]*watchedFile
+}
+
+type watchedFile struct {
+	size    int64
+	modTime time.Time
+	turns   []parser.Turn
+	err     error
+}
+
+// refresh returns an up-to-date view of every watched transcript
+func (w *watcher) refresh(now time.Time) []*tui.SessionView {
+	paths := w.paths
+	if len(paths) == 0 {
+		transcripts, _ := projects.List(projects.Dirs(), now.Add(-w.active))
+		for _, transcript := range transcripts {
+			paths = append(paths, transcript.Path)
+		}
+	}
+
+	views := make([]*tui.SessionView, 0, len(paths))
+	for _, path := range paths {
+		file := w.files[path]
+		if file == nil {
+			file = &watchedFile{}
+			w.files[path] = file
+		}
+
+		fi, err := os.Stat(path)
+		switch {
+		case err != nil:
+			file.err = err
+		case fi.Size() != file.size || !fi.ModTime().Equal(file.modTime):
+			file.size, file.modTime = fi.Size(), fi.ModTime()
+			file.turns, file.err = parser.ParseTurns(path)
+		}
+
+		// views are rebuilt on every tick because burn rate and idle time depend on now
+		view := tui.NewSessionView(path, file.turns, now)
+		if file.err != nil {
+			view.Err = file.err
+		}
+		views = append(views, view)
+	}
+	return views
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcherRefresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	line := `{"type":"assistant","timestamp":"2025-10-01T10:00:00Z","cwd":"/work/app","requestId":"r1","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":1000}}}` + "\n"
+	if err := os.WriteFile(path, []byte(line), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &watcher{paths: []string{path}, files: map[string]*watchedFile{}}
+	now := time.Date(2025, 10, 1, 10, 5, 0, 0, time.UTC)
+
+	views := w.refresh(now)
+	if len(views) != 1 || views[0].Context.CurrentTokens != 1000 {
+		t.Fatalf("refresh() = %+v, want one view with 1000 context tokens", views)
+	}
+
+	// appended lines are picked up on the next poll
+	appended := `{"type":"assistant","timestamp":"2025-10-01T10:01:00Z","requestId":"r2","message":{"id":"m2","role":"assistant","model":"claude-sonnet-4-5","usage":{"input_tokens":3000}}}` + "\n"
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(appended)
+	f.Close()
+
+	views = w.refresh(now)
+	if got := views[0].Context.CurrentTokens; got != 3000 {
+		t.Errorf("refresh() after append CurrentTokens = %d, want 3000", got)
+	}
+	if got := views[0].Calls; got != 2 {
+		t.Errorf("refresh() after append Calls = %d, want 2", got)
+	}
+
+	// a vanished file keeps its last view and reports the error
+	os.Remove(path)
+	views = w.refresh(now)
+	if views[0].Err == nil || views[0].Calls != 2 {
+		t.Errorf("refresh() after remove = %+v, want last view with error", views[0])
+	}
+}